@@ -0,0 +1,204 @@
+package hue
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Button is a button of a switch, numbered from 1 in the order documented
+// for each switch.
+type Button int
+
+// Buttons of the switches whose events can be decoded.
+const (
+	// DimmerOn, DimmerUp, DimmerDown and DimmerOff are the buttons of the
+	// Hue dimmer switch, from top to bottom.
+	DimmerOn   Button = 1
+	DimmerUp   Button = 2
+	DimmerDown Button = 3
+	DimmerOff  Button = 4
+
+	// SmartButton is the only button of the Hue smart button.
+	SmartButton Button = 1
+
+	// TapButton1 to TapButton4 are the buttons of the Hue tap, as
+	// numbered by the dots on the switch.
+	TapButton1 Button = 1
+	TapButton2 Button = 2
+	TapButton3 Button = 3
+	TapButton4 Button = 4
+
+	// FOHTopLeft to FOHBottomRight are the buttons of Friends of Hue
+	// switches, FOHTopPair and FOHBottomPair are reported when both
+	// buttons of a row are pressed together.
+	FOHTopLeft     Button = 1
+	FOHBottomLeft  Button = 2
+	FOHTopRight    Button = 3
+	FOHBottomRight Button = 4
+	FOHTopPair     Button = 5
+	FOHBottomPair  Button = 6
+)
+
+// ButtonAction is what happened to a button.
+type ButtonAction int
+
+// Button actions, their values are the last digit of the events of
+// ZLLSwitch sensors.
+const (
+	InitialPress ButtonAction = 0
+	Hold         ButtonAction = 1
+	ShortRelease ButtonAction = 2
+	LongRelease  ButtonAction = 3
+)
+
+func (a ButtonAction) String() string {
+	switch a {
+	case InitialPress:
+		return "initial press"
+	case Hold:
+		return "hold"
+	case ShortRelease:
+		return "short release"
+	case LongRelease:
+		return "long release"
+	default:
+		return fmt.Sprintf("action %d", int(a))
+	}
+}
+
+// ButtonEvent is a decoded buttonevent.
+type ButtonEvent struct {
+	Button Button
+	Action ButtonAction
+}
+
+func (e ButtonEvent) String() string {
+	return fmt.Sprintf("button %d %s", e.Button, e.Action)
+}
+
+// switchKind is the way a model of switch encodes its events.
+type switchKind int
+
+const (
+	unknownSwitch switchKind = iota
+
+	// zllSwitch events are the button times 1000 plus the action.
+	zllSwitch
+
+	// tapSwitch events only report presses, with a code per button.
+	tapSwitch
+
+	// fohSwitch events report presses and releases, with a code per
+	// button or pair of buttons.
+	fohSwitch
+)
+
+// switchModels maps the model IDs of switches to their encoding along
+// with the number of buttons of ZLL switches.
+var switchModels = map[string]struct {
+	kind    switchKind
+	buttons Button
+}{
+	"RWL020":    {zllSwitch, 4},
+	"RWL021":    {zllSwitch, 4},
+	"RWL022":    {zllSwitch, 4},
+	"ROM001":    {zllSwitch, 1},
+	"RDM001":    {zllSwitch, 2},
+	"ZGPSWITCH": {tapSwitch, 4},
+	"FOHSWITCH": {fohSwitch, 6},
+}
+
+var tapEvents = map[int]Button{
+	34: TapButton1,
+	16: TapButton2,
+	17: TapButton3,
+	18: TapButton4,
+}
+
+var fohEvents = map[int]ButtonEvent{
+	16:  {FOHTopLeft, InitialPress},
+	20:  {FOHTopLeft, ShortRelease},
+	17:  {FOHBottomLeft, InitialPress},
+	21:  {FOHBottomLeft, ShortRelease},
+	18:  {FOHTopRight, InitialPress},
+	22:  {FOHTopRight, ShortRelease},
+	19:  {FOHBottomRight, InitialPress},
+	23:  {FOHBottomRight, ShortRelease},
+	100: {FOHTopPair, InitialPress},
+	101: {FOHTopPair, ShortRelease},
+	98:  {FOHBottomPair, InitialPress},
+	99:  {FOHBottomPair, ShortRelease},
+}
+
+// DecodeButtonEvent decodes the buttonevent reported by a switch with the
+// model ID. Switches of unknown models are decoded as ZLL switches when
+// the event follows their encoding.
+func DecodeButtonEvent(modelID string, event int) (ButtonEvent, error) {
+	model, ok := switchModels[modelID]
+	if !ok && event >= 1000 {
+		model.kind, model.buttons = zllSwitch, Button(event/1000)
+	}
+
+	switch model.kind {
+	case zllSwitch:
+		e := ButtonEvent{Button: Button(event / 1000), Action: ButtonAction(event % 1000)}
+		if e.Button >= 1 && e.Button <= model.buttons && e.Action >= InitialPress && e.Action <= LongRelease {
+			return e, nil
+		}
+	case tapSwitch:
+		if b, ok := tapEvents[event]; ok {
+			return ButtonEvent{Button: b, Action: InitialPress}, nil
+		}
+	case fohSwitch:
+		if e, ok := fohEvents[event]; ok {
+			return e, nil
+		}
+	default:
+		return ButtonEvent{}, errors.Errorf("can't decode button event %d of unknown switch model '%s'", event, modelID)
+	}
+
+	return ButtonEvent{}, errors.Errorf("invalid button event %d for switch model '%s'", event, modelID)
+}
+
+// EncodeButtonEvent returns the buttonevent a switch with the model ID
+// reports for e, such as the value of a rule condition.
+func EncodeButtonEvent(modelID string, e ButtonEvent) (int, error) {
+	model, ok := switchModels[modelID]
+	if !ok {
+		return 0, errors.Errorf("can't encode button events of unknown switch model '%s'", modelID)
+	}
+
+	switch model.kind {
+	case zllSwitch:
+		if e.Button >= 1 && e.Button <= model.buttons && e.Action >= InitialPress && e.Action <= LongRelease {
+			return int(e.Button)*1000 + int(e.Action), nil
+		}
+	case tapSwitch:
+		for event, b := range tapEvents {
+			if b == e.Button && e.Action == InitialPress {
+				return event, nil
+			}
+		}
+	case fohSwitch:
+		for event, fe := range fohEvents {
+			if fe == e {
+				return event, nil
+			}
+		}
+	}
+
+	return 0, errors.Errorf("switch model '%s' doesn't report %s", modelID, e)
+}
+
+// ButtonEvent decodes the last button event of a switch sensor, it is
+// false when the sensor isn't a switch or never reported an event.
+func (s *Sensor) ButtonEvent() (ButtonEvent, bool) {
+	state, ok := s.State.(*SwitchState)
+	if !ok || state.ButtonEvent == 0 {
+		return ButtonEvent{}, false
+	}
+
+	e, err := DecodeButtonEvent(s.ModelID, state.ButtonEvent)
+	return e, err == nil
+}
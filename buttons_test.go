@@ -0,0 +1,96 @@
+package hue
+
+import (
+	"testing"
+)
+
+func TestDecodeButtonEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		modelID string
+		event   int
+		want    ButtonEvent
+		wantErr bool
+	}{
+		{name: "dimmer on press", modelID: "RWL021", event: 1000, want: ButtonEvent{DimmerOn, InitialPress}},
+		{name: "dimmer up hold", modelID: "RWL020", event: 2001, want: ButtonEvent{DimmerUp, Hold}},
+		{name: "dimmer off long release", modelID: "RWL022", event: 4003, want: ButtonEvent{DimmerOff, LongRelease}},
+		{name: "smart button short release", modelID: "ROM001", event: 1002, want: ButtonEvent{SmartButton, ShortRelease}},
+		{name: "smart button has one button", modelID: "ROM001", event: 2002, wantErr: true},
+		{name: "dimmer action out of range", modelID: "RWL021", event: 1004, wantErr: true},
+		{name: "tap button 1", modelID: "ZGPSWITCH", event: 34, want: ButtonEvent{TapButton1, InitialPress}},
+		{name: "tap button 4", modelID: "ZGPSWITCH", event: 18, want: ButtonEvent{TapButton4, InitialPress}},
+		{name: "tap unknown", modelID: "ZGPSWITCH", event: 20, wantErr: true},
+		{name: "friends of hue press", modelID: "FOHSWITCH", event: 18, want: ButtonEvent{FOHTopRight, InitialPress}},
+		{name: "friends of hue release", modelID: "FOHSWITCH", event: 21, want: ButtonEvent{FOHBottomLeft, ShortRelease}},
+		{name: "friends of hue pair", modelID: "FOHSWITCH", event: 101, want: ButtonEvent{FOHTopPair, ShortRelease}},
+		{name: "unknown zll model", modelID: "XYZ001", event: 3002, want: ButtonEvent{Button(3), ShortRelease}},
+		{name: "unknown model", modelID: "XYZ001", event: 34, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeButtonEvent(tt.modelID, tt.event)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeButtonEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DecodeButtonEvent() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if _, known := switchModels[tt.modelID]; !known {
+				return
+			}
+
+			event, err := EncodeButtonEvent(tt.modelID, got)
+			if err != nil || event != tt.event {
+				t.Errorf("EncodeButtonEvent() = %v, %v, want %v", event, err, tt.event)
+			}
+		})
+	}
+}
+
+func TestEncodeButtonEvent(t *testing.T) {
+	if _, err := EncodeButtonEvent("ZGPSWITCH", ButtonEvent{TapButton1, ShortRelease}); err == nil {
+		t.Errorf("EncodeButtonEvent() of a tap release succeeded")
+	}
+
+	if _, err := EncodeButtonEvent("XYZ001", ButtonEvent{DimmerOn, InitialPress}); err == nil {
+		t.Errorf("EncodeButtonEvent() of an unknown model succeeded")
+	}
+}
+
+func TestSensor_ButtonEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		sensor Sensor
+		want   ButtonEvent
+		wantOk bool
+	}{
+		{
+			name:   "dimmer",
+			sensor: Sensor{Type: ZLLSwitch, ModelID: "RWL021", State: &SwitchState{ButtonEvent: 4002}},
+			want:   ButtonEvent{DimmerOff, ShortRelease},
+			wantOk: true,
+		},
+		{
+			name:   "never pressed",
+			sensor: Sensor{Type: ZLLSwitch, ModelID: "RWL021", State: &SwitchState{}},
+		},
+		{
+			name:   "not a switch",
+			sensor: Sensor{Type: ZLLPresence, State: &PresenceState{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.sensor.ButtonEvent()
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Sensor.ButtonEvent() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
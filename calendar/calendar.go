@@ -0,0 +1,78 @@
+// Package calendar converts cron expressions and iCalendar events into
+// bridge schedules. The bridge only repeats on a weekday bitmask at a
+// fixed time of day, recurrences that can't be expressed that way are
+// reported with an *UnsupportedError rather than approximated. Malformed
+// expressions and events are reported as plain errors.
+package calendar
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ninnemana/huego"
+	"github.com/ninnemana/huego/timepattern"
+)
+
+// MaxSchedules is the most schedules a single expression or event is
+// converted into, the bridge can't hold more than 100 in total.
+const MaxSchedules = 100
+
+// maxDescriptionLength is the longest description the bridge accepts.
+const maxDescriptionLength = 64
+
+// UnsupportedError is returned when a recurrence can't be expressed as
+// bridge schedules.
+type UnsupportedError struct {
+	// Source is the cron expression or iCalendar property that was
+	// being converted.
+	Source string
+
+	// Reason describes what the bridge's schedules can't express.
+	Reason string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("can't express '%s' as a bridge schedule: %s", e.Source, e.Reason)
+}
+
+func unsupported(source, format string, args ...interface{}) error {
+	return &UnsupportedError{
+		Source: source,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// schedule builds a schedule running cmd at p.
+func schedule(name, description string, cmd hue.Command, p timepattern.Pattern) hue.Schedule {
+	s := hue.Schedule{
+		Name:        truncate(name, hue.MaxNameLength),
+		Description: truncate(description, maxDescriptionLength),
+		Command:     cmd,
+	}
+	s.SetPattern(p)
+
+	return s
+}
+
+// weekly builds the recurring patterns firing on days at each time of day.
+func weekly(source string, days timepattern.Weekdays, times []time.Duration) ([]timepattern.Pattern, error) {
+	if len(times) > MaxSchedules {
+		return nil, unsupported(source, "it fires at %d times of day, more than the %d schedules the bridge holds", len(times), MaxSchedules)
+	}
+
+	patterns := make([]timepattern.Pattern, 0, len(times))
+	for _, t := range times {
+		patterns = append(patterns, timepattern.Weekly(days, t))
+	}
+
+	return patterns, nil
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,160 @@
+package calendar
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninnemana/huego"
+	"github.com/ninnemana/huego/timepattern"
+	"github.com/pkg/errors"
+)
+
+var cronMacros = map[string]string{
+	"@midnight": "0 0 * * *",
+	"@daily":    "0 0 * * *",
+	"@hourly":   "0 * * * *",
+	"@weekly":   "0 0 * * 0",
+}
+
+var cronDays = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// FromCron converts a five field cron expression, minute hour
+// day-of-month month day-of-week, into schedules running cmd. The
+// expression is read in the bridge's local time. Only expressions that
+// repeat on weekdays are supported, so day-of-month and month must be *,
+// and one schedule is created for each time of day the expression fires.
+func FromCron(expr string, cmd hue.Command) ([]hue.Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[spec]; ok {
+		spec = macro
+	} else if strings.HasPrefix(spec, "@") {
+		return nil, unsupported(expr, "only @midnight, @daily, @hourly and @weekly repeat on weekdays")
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression '%s' needs five fields, received %d", expr, len(fields))
+	}
+
+	minutes, err := cronField(expr, fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := cronField(expr, fields[1], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !wildcard(fields[2]) {
+		return nil, unsupported(expr, "the bridge can't repeat on days of the month")
+	}
+
+	if !wildcard(fields[3]) {
+		return nil, unsupported(expr, "the bridge can't repeat on specific months")
+	}
+
+	dows, err := cronField(expr, fields[4], 0, 7, cronDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var days timepattern.Weekdays
+	for _, d := range dows {
+		days |= timepattern.Weekday(time.Weekday(d % 7))
+	}
+
+	times := make([]time.Duration, 0, len(hours)*len(minutes))
+	for _, h := range hours {
+		for _, m := range minutes {
+			times = append(times, time.Duration(h)*time.Hour+time.Duration(m)*time.Minute)
+		}
+	}
+
+	patterns, err := weekly(expr, days, times)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]hue.Schedule, 0, len(patterns))
+	for _, p := range patterns {
+		schedules = append(schedules, schedule("", expr, cmd, p))
+	}
+
+	return schedules, nil
+}
+
+func wildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// cronField expands a cron field into the sorted values it matches, names
+// maps the symbolic values a field accepts. Malformed fields are reported
+// as plain errors, they aren't recurrences the bridge can't express.
+func cronField(expr, field string, lo, hi int, names map[string]int) ([]int, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return nil, errors.Errorf("invalid step in '%s' of cron expression '%s'", part, expr)
+			}
+
+			step = n
+			part = part[:i]
+		}
+
+		from, to := lo, hi
+		switch {
+		case wildcard(part):
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if from, err = cronValue(bounds[0], names); err != nil {
+				return nil, errors.Errorf("invalid range '%s' in cron expression '%s'", part, expr)
+			}
+			if to, err = cronValue(bounds[1], names); err != nil {
+				return nil, errors.Errorf("invalid range '%s' in cron expression '%s'", part, expr)
+			}
+		default:
+			v, err := cronValue(part, names)
+			if err != nil {
+				return nil, errors.Errorf("invalid value '%s' in cron expression '%s'", part, expr)
+			}
+
+			from = v
+			if step == 1 {
+				to = v
+			}
+		}
+
+		if from < lo || to > hi || from > to {
+			return nil, errors.Errorf("'%s' is outside %d-%d in cron expression '%s'", part, lo, hi, expr)
+		}
+
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+
+	values := make([]int, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+
+	return values, nil
+}
+
+func cronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+
+	return strconv.Atoi(s)
+}
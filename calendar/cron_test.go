@@ -0,0 +1,130 @@
+package calendar
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+func TestFromCron(t *testing.T) {
+	cmd := hue.Command{
+		Address: "/api/user/groups/1/action",
+		Method:  "PUT",
+		Body:    map[string]interface{}{"on": true},
+	}
+
+	tests := []struct {
+		name            string
+		expr            string
+		want            []string
+		wantUnsupported bool
+		wantErr         bool
+	}{
+		{
+			name: "workdays",
+			expr: "30 7 * * 1-5",
+			want: []string{"W124/T07:30:00"},
+		},
+		{
+			name: "weekend by name",
+			expr: "0 9 * * SAT,SUN",
+			want: []string{"W003/T09:00:00"},
+		},
+		{
+			name: "sunday as seven",
+			expr: "0 9 * * 7",
+			want: []string{"W001/T09:00:00"},
+		},
+		{
+			name: "several times a day",
+			expr: "0,30 18-19 * * *",
+			want: []string{"W127/T18:00:00", "W127/T18:30:00", "W127/T19:00:00", "W127/T19:30:00"},
+		},
+		{
+			name: "step",
+			expr: "0 */8 * * MON",
+			want: []string{"W064/T00:00:00", "W064/T08:00:00", "W064/T16:00:00"},
+		},
+		{
+			name: "macro",
+			expr: "@daily",
+			want: []string{"W127/T00:00:00"},
+		},
+		{
+			name:            "day of month",
+			expr:            "0 7 1 * *",
+			wantUnsupported: true,
+		},
+		{
+			name:            "month",
+			expr:            "0 7 * 12 *",
+			wantUnsupported: true,
+		},
+		{
+			name:            "too many times",
+			expr:            "*/5 * * * *",
+			wantUnsupported: true,
+		},
+		{
+			name:            "yearly",
+			expr:            "@yearly",
+			wantUnsupported: true,
+		},
+		{
+			name:    "out of range",
+			expr:    "0 24 * * *",
+			wantErr: true,
+		},
+		{
+			name:    "invalid step",
+			expr:    "*/0 7 * * *",
+			wantErr: true,
+		},
+		{
+			name:    "invalid range",
+			expr:    "0 7 * * MON-",
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			expr:    "x 7 * * *",
+			wantErr: true,
+		},
+		{
+			name:    "six fields",
+			expr:    "0 0 7 * * *",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromCron(tt.expr, cmd)
+
+			var unsupported *UnsupportedError
+			if errors.As(err, &unsupported) != tt.wantUnsupported || (err != nil) != (tt.wantErr || tt.wantUnsupported) {
+				t.Fatalf("FromCron() error = %v, wantUnsupported %v, wantErr %v", err, tt.wantUnsupported, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var patterns []string
+			for _, s := range got {
+				if err := s.Validate(); err != nil {
+					t.Errorf("FromCron() produced an invalid schedule: %v", err)
+				}
+				if !reflect.DeepEqual(s.Command, cmd) || s.Description != tt.expr {
+					t.Errorf("FromCron() schedule = %+v", s)
+				}
+
+				patterns = append(patterns, s.LocalTime)
+			}
+
+			if !reflect.DeepEqual(patterns, tt.want) {
+				t.Errorf("FromCron() = %v, want %v", patterns, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package calendar
+
+import (
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninnemana/huego"
+	"github.com/ninnemana/huego/timepattern"
+	"github.com/pkg/errors"
+)
+
+var icsDays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// now is replaced by tests to convert events at a fixed time.
+var now = time.Now
+
+// event is the part of a VEVENT that can be turned into schedules.
+type event struct {
+	summary  string
+	start    time.Time
+	end      time.Time
+	rrule    string
+	excluded string
+}
+
+// FromEvents converts the VEVENT components of an RFC 5545 calendar into
+// schedules running start when each event begins, and end when it finishes
+// if end isn't nil. Event times are converted to loc, the bridge's
+// timezone, floating times are read in it directly. Events without an
+// RRULE become absolute schedules, their start or end is left out once it
+// has passed as the bridge rejects schedules in the past. Recurring events
+// must repeat daily or weekly on whole weekdays without an end.
+func FromEvents(r io.Reader, loc *time.Location, start hue.Command, end *hue.Command) ([]hue.Schedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	events, err := parseEvents(r, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	current := now()
+
+	var schedules []hue.Schedule
+	for _, ev := range events {
+		var days timepattern.Weekdays
+		if ev.rrule != "" {
+			if ev.excluded != "" {
+				return nil, unsupported(ev.excluded, "the bridge can't skip or add single occurrences")
+			}
+
+			if days, err = recurrence(ev.rrule, ev.start.Weekday()); err != nil {
+				return nil, err
+			}
+		}
+
+		if days != 0 || ev.start.After(current) {
+			schedules = append(schedules, eventSchedule(ev, ev.start, days, loc, "start of ", start))
+		}
+		if end != nil && !ev.end.IsZero() && (days != 0 || ev.end.After(current)) {
+			schedules = append(schedules, eventSchedule(ev, ev.end, days, loc, "end of ", *end))
+		}
+	}
+
+	if len(schedules) > MaxSchedules {
+		return nil, unsupported("VCALENDAR", "it needs %d schedules, more than the %d the bridge holds", len(schedules), MaxSchedules)
+	}
+
+	return schedules, nil
+}
+
+// eventSchedule builds the schedule firing at t, on days when the event
+// recurs. The days are those of the event's start in its own timezone, so
+// they're moved along when t falls on another day in the bridge's.
+func eventSchedule(ev event, t time.Time, days timepattern.Weekdays, loc *time.Location, prefix string, cmd hue.Command) hue.Schedule {
+	local := t.In(loc)
+	if days == 0 {
+		return schedule(ev.summary, prefix+ev.summary, cmd, timepattern.At(local))
+	}
+
+	shift := int(local.Weekday()-ev.start.Weekday()+7) % 7
+	var shifted timepattern.Weekdays
+	for _, d := range days.Days() {
+		shifted |= timepattern.Weekday((d + time.Weekday(shift)) % 7)
+	}
+
+	h, m, s := local.Clock()
+	timeOfDay := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+
+	return schedule(ev.summary, prefix+ev.summary, cmd, timepattern.Weekly(shifted, timeOfDay))
+}
+
+// recurrence reads the weekdays an RRULE repeats on, starting is the
+// weekday of the first occurrence.
+func recurrence(rrule string, starting time.Weekday) (timepattern.Weekdays, error) {
+	source := "RRULE:" + rrule
+
+	var (
+		freq string
+		days timepattern.Weekdays
+	)
+	for _, part := range strings.Split(rrule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return 0, errors.Errorf("invalid rule part '%s' in %s", part, source)
+		}
+
+		switch key, val := strings.ToUpper(kv[0]), kv[1]; key {
+		case "FREQ":
+			freq = strings.ToUpper(val)
+		case "INTERVAL":
+			if n, err := strconv.Atoi(val); err != nil || n != 1 {
+				return 0, unsupported(source, "the bridge can only repeat every day or week, not every %s", val)
+			}
+		case "COUNT", "UNTIL":
+			return 0, unsupported(source, "the bridge can't stop repeating after a %s", strings.ToLower(key))
+		case "WKST":
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				d, ok := icsDays[strings.ToUpper(day)]
+				if !ok {
+					return 0, unsupported(source, "the bridge can only repeat on every occurrence of a weekday, not '%s'", day)
+				}
+
+				days |= timepattern.Weekday(d)
+			}
+		default:
+			return 0, unsupported(source, "the bridge can't repeat by %s", key)
+		}
+	}
+
+	switch freq {
+	case "DAILY":
+		if days == 0 {
+			days = timepattern.Everyday
+		}
+	case "WEEKLY":
+		if days == 0 {
+			days = timepattern.Weekday(starting)
+		}
+	default:
+		return 0, unsupported(source, "the bridge can only repeat daily or weekly, not %s", strings.ToLower(freq))
+	}
+
+	return days, nil
+}
+
+// parseEvents reads the VEVENT components of a calendar.
+func parseEvents(r io.Reader, loc *time.Location) ([]event, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// unfold the content lines that were split over several lines
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+	text = strings.Replace(text, "\n ", "", -1)
+	text = strings.Replace(text, "\n\t", "", -1)
+
+	var (
+		events []event
+		ev     *event
+		depth  int
+	)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return nil, errors.Errorf("invalid calendar line '%s'", line)
+		}
+
+		params := strings.Split(line[:i], ";")
+		name, value := strings.ToUpper(params[0]), line[i+1:]
+
+		switch {
+		case name == "BEGIN" && strings.ToUpper(value) == "VEVENT" && ev == nil:
+			ev = &event{}
+			depth = 0
+			continue
+		case name == "END" && strings.ToUpper(value) == "VEVENT" && ev != nil && depth == 0:
+			if ev.start.IsZero() {
+				return nil, errors.New("calendar event has no DTSTART")
+			}
+
+			events = append(events, *ev)
+			ev = nil
+			continue
+		case ev == nil:
+			continue
+		case name == "BEGIN":
+			// nested components such as VALARM
+			depth++
+			continue
+		case name == "END":
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		switch name {
+		case "SUMMARY":
+			ev.summary = unescape(value)
+		case "DTSTART":
+			if ev.start, err = icsTime(params[1:], value, loc); err != nil {
+				return nil, err
+			}
+		case "DTEND":
+			if ev.end, err = icsTime(params[1:], value, loc); err != nil {
+				return nil, err
+			}
+		case "RRULE":
+			ev.rrule = value
+		case "EXDATE", "RDATE", "EXRULE":
+			ev.excluded = name + ":" + value
+		}
+	}
+
+	if ev != nil {
+		return nil, errors.New("calendar event isn't terminated by END:VEVENT")
+	}
+
+	return events, nil
+}
+
+// icsTime reads a DATE or DATE-TIME value, in UTC when it ends with Z, in
+// its TZID parameter when set, and in loc otherwise.
+func icsTime(params []string, value string, loc *time.Location) (time.Time, error) {
+	for _, p := range params {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 && strings.ToUpper(kv[0]) == "TZID" {
+			tz, err := time.LoadLocation(strings.Trim(kv[1], `"`))
+			if err != nil {
+				return time.Time{}, errors.Errorf("unknown event timezone '%s': %v", kv[1], err)
+			}
+
+			loc = tz
+		}
+	}
+
+	layout := "20060102T150405"
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		layout, loc = "20060102T150405Z", time.UTC
+	case len(value) == len("20060102"):
+		layout = "20060102"
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid event time '%s': %v", value, err)
+	}
+
+	return t, nil
+}
+
+// unescape reverses the escaping of TEXT values.
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
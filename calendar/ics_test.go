@@ -0,0 +1,159 @@
+package calendar
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninnemana/huego"
+)
+
+func TestFromEvents(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC) }
+
+	on := hue.Command{Address: "/api/user/groups/1/action", Method: "PUT", Body: map[string]interface{}{"on": true}}
+	off := hue.Command{Address: "/api/user/groups/1/action", Method: "PUT", Body: map[string]interface{}{"on": false}}
+
+	calendar := func(events ...string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+	}
+
+	tests := []struct {
+		name            string
+		ics             string
+		end             *hue.Command
+		want            []string
+		wantNames       []string
+		wantUnsupported bool
+		wantErr         bool
+	}{
+		{
+			name: "single event",
+			ics: calendar("BEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Dinner\\, family\r\n" +
+				"DTSTART;TZID=Europe/Amsterdam:20200327T180000\r\nDTEND;TZID=Europe/Amsterdam:20200327T200000\r\n" +
+				"BEGIN:VALARM\r\nTRIGGER:-PT15M\r\nACTION:DISPLAY\r\nEND:VALARM\r\nEND:VEVENT\r\n"),
+			end:       &off,
+			want:      []string{"2020-03-27T18:00:00", "2020-03-27T20:00:00"},
+			wantNames: []string{"Dinner, family", "Dinner, family"},
+		},
+		{
+			name: "utc converted to the bridge",
+			ics:  calendar("BEGIN:VEVENT\r\nDTSTART:20200701T050000Z\r\nSUMMARY:Early\r\nEND:VEVENT\r\n"),
+			want: []string{"2020-07-01T07:00:00"},
+		},
+		{
+			name: "past event",
+			ics: calendar("BEGIN:VEVENT\r\nSUMMARY:Party\r\nDTSTART:20191231T230000Z\r\nDTEND:20200101T030000Z\r\nEND:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\nSUMMARY:Dinner\r\nDTSTART:20200327T170000Z\r\nDTEND:20200327T190000Z\r\nEND:VEVENT\r\n"),
+			end:       &off,
+			want:      []string{"2020-03-27T18:00:00", "2020-03-27T20:00:00"},
+			wantNames: []string{"Dinner", "Dinner"},
+		},
+		{
+			name: "ending after the import",
+			ics:  calendar("BEGIN:VEVENT\r\nSUMMARY:Night\r\nDTSTART:20200229T220000Z\r\nDTEND:20200301T060000Z\r\nEND:VEVENT\r\n"),
+			end:  &off,
+			want: []string{"2020-03-01T07:00:00"},
+		},
+		{
+			name: "weekly on days",
+			ics: calendar("BEGIN:VEVENT\r\nSUMMARY:Wake up\r\nDTSTART;TZID=Europe/Amsterdam:20200330T063000\r\n" +
+				"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;WKST=MO\r\nEND:VEVENT\r\n"),
+			want:      []string{"W124/T06:30:00"},
+			wantNames: []string{"Wake up"},
+		},
+		{
+			name: "weekly on the start day",
+			ics:  calendar("BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n"),
+			want: []string{"W002/T10:00:00"},
+		},
+		{
+			name: "daily ending after midnight in another timezone",
+			ics: calendar("BEGIN:VEVENT\r\nDTSTART;TZID=America/New_York:20200601T170000\r\n" +
+				"DTEND;TZID=America/New_York:20200601T\r\n 190000\r\nRRULE:FREQ=DAILY;BYDAY=SA\r\nEND:VEVENT\r\n"),
+			end:  &off,
+			want: []string{"W002/T23:00:00", "W001/T01:00:00"},
+		},
+		{
+			name:            "every other week",
+			ics:             calendar("BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\nRRULE:FREQ=WEEKLY;INTERVAL=2\r\nEND:VEVENT\r\n"),
+			wantUnsupported: true,
+		},
+		{
+			name:            "count",
+			ics:             calendar("BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\nRRULE:FREQ=DAILY;COUNT=5\r\nEND:VEVENT\r\n"),
+			wantUnsupported: true,
+		},
+		{
+			name:            "monthly",
+			ics:             calendar("BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\nRRULE:FREQ=MONTHLY\r\nEND:VEVENT\r\n"),
+			wantUnsupported: true,
+		},
+		{
+			name:            "nth weekday",
+			ics:             calendar("BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\nRRULE:FREQ=WEEKLY;BYDAY=1MO\r\nEND:VEVENT\r\n"),
+			wantUnsupported: true,
+		},
+		{
+			name:            "excluded occurrence",
+			ics:             calendar("BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\nRRULE:FREQ=DAILY\r\nEXDATE:20200329T100000\r\nEND:VEVENT\r\n"),
+			wantUnsupported: true,
+		},
+		{
+			name:    "malformed rule",
+			ics:     calendar("BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\nRRULE:FREQ=DAILY;BYDAY\r\nEND:VEVENT\r\n"),
+			wantErr: true,
+		},
+		{
+			name:    "missing start",
+			ics:     calendar("BEGIN:VEVENT\r\nSUMMARY:Nothing\r\nEND:VEVENT\r\n"),
+			wantErr: true,
+		},
+		{
+			name:    "unterminated",
+			ics:     "BEGIN:VEVENT\r\nDTSTART:20200328T100000\r\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromEvents(strings.NewReader(tt.ics), loc, on, tt.end)
+
+			var unsupported *UnsupportedError
+			if errors.As(err, &unsupported) != tt.wantUnsupported || (err != nil) != (tt.wantErr || tt.wantUnsupported) {
+				t.Fatalf("FromEvents() error = %v, wantUnsupported %v, wantErr %v", err, tt.wantUnsupported, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var patterns, names []string
+			for _, s := range got {
+				if err := s.Validate(); err != nil {
+					t.Errorf("FromEvents() produced an invalid schedule: %v", err)
+				}
+
+				patterns = append(patterns, s.LocalTime)
+				names = append(names, s.Name)
+			}
+
+			if !reflect.DeepEqual(patterns, tt.want) {
+				t.Errorf("FromEvents() = %v, want %v", patterns, tt.want)
+			}
+			if tt.wantNames != nil && !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("FromEvents() names = %v, want %v", names, tt.wantNames)
+			}
+			if tt.end != nil && !reflect.DeepEqual(got[len(got)-1].Command, *tt.end) {
+				t.Errorf("FromEvents() end command = %+v, want %+v", got[len(got)-1].Command, *tt.end)
+			}
+		})
+	}
+}
@@ -2,17 +2,15 @@ package client
 
 import (
 	"context"
-	"fmt"
-	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/ninnemana/huego"
+	"github.com/ninnemana/huego/timepattern"
 
 	jsoniter "github.com/ninnemana/json-iterator"
 	"github.com/pkg/errors"
-	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
 )
 
@@ -21,11 +19,7 @@ var (
 )
 
 func (c *client) AllBridges(ctx context.Context, q interface{}) ([]interface{}, error) {
-	span := trace.NewSpan(
-		"hue.http.bridges.all",
-		trace.FromContext(ctx),
-		trace.StartOptions{},
-	)
+	ctx, span := trace.StartSpan(ctx, "hue.http.bridges.all")
 	defer span.End()
 
 	params, ok := q.(*hue.AllBridgeParams)
@@ -33,11 +27,6 @@ func (c *client) AllBridges(ctx context.Context, q interface{}) ([]interface{},
 		return nil, errors.Errorf("provided params were expected to be *AllBridgeParams, received '%T'", params)
 	}
 
-	// Automatically add a Stackdriver trace header to outgoing requests:
-	client := &http.Client{
-		Transport: &ochttp.Transport{},
-	}
-
 	span.AddAttributes(trace.StringAttribute("method", params.Method))
 	var discoverEndpoint string
 	switch strings.ToLower(params.Method) {
@@ -66,27 +55,13 @@ func (c *client) AllBridges(ctx context.Context, q interface{}) ([]interface{},
 		return nil, errors.Errorf("connection method '%s' was not valid", params.Method)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, discoverEndpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := client.Do(req)
+	data, err := c.send(ctx, http.MethodGet, discoverEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	span.AddAttributes(trace.Int64Attribute("statusCode", int64(resp.StatusCode)))
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
-	}
-
 	bridges := []interface{}{}
-	err = json.NewDecoder(resp.Body).Decode(&bridges)
+	err = json.Unmarshal(data, &bridges)
 	if err != nil {
 		return nil, err
 	}
@@ -98,113 +73,57 @@ func (c *client) AllBridges(ctx context.Context, q interface{}) ([]interface{},
 	return bridges, nil
 }
 
-func (c *client) CreateUser(interface{}) (interface{}, error) {
+func (c *client) CreateUser(ctx context.Context, user interface{}) (interface{}, error) {
 	return nil, hue.ErrNotImplemented
 }
 
 func (c *client) GetConfig(ctx context.Context) (interface{}, error) {
-	span := trace.NewSpan(
-		"hue.http.bridges.config",
-		trace.FromContext(ctx),
-		trace.StartOptions{},
-	)
+	ctx, span := trace.StartSpan(ctx, "hue.http.bridges.config")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
-	}
-
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
-	}
-
-	path := fmt.Sprintf("%s/api/%s/config", host, user)
-	req, err := http.NewRequest(http.MethodGet, path, nil)
-	if err != nil {
+	var conf interface{}
+	if err := c.get(ctx, "/config", &conf); err != nil {
 		return nil, err
 	}
 
-	client := http.Client{
-		Timeout: time.Second * 5,
-	}
+	return conf, nil
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
+// TimeZone returns the location of the timezone the bridge is configured
+// with, local times and time patterns reported by the bridge are in it.
+func (c *client) TimeZone(ctx context.Context) (*time.Location, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.bridges.timezone")
+	defer span.End()
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
+	var config struct {
+		TimeZone string `json:"timezone"`
 	}
-
-	var conf interface{}
-	err = json.NewDecoder(resp.Body).Decode(&conf)
-	if err != nil {
+	if err := c.get(ctx, "/config", &config); err != nil {
 		return nil, err
 	}
 
-	return conf, nil
+	return timepattern.LoadLocation(config.TimeZone)
 }
 
-func (c *client) ModifyConfig(interface{}) (interface{}, error) {
+func (c *client) ModifyConfig(ctx context.Context, config interface{}) (interface{}, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) Unwhitelist(string) error {
+func (c *client) Unwhitelist(ctx context.Context, user string) error {
 	return hue.ErrNotImplemented
 }
 
-func (c *client) GetFullState(ctx context.Context) (interface{}, error) {
-	span := trace.NewSpan(
-		"hue.http.bridges.state",
-		trace.FromContext(ctx),
-		trace.StartOptions{},
-	)
+// GetFullState reads every resource on the bridge along with its
+// configuration in a single request.
+// GET /api/<username>
+func (c *client) GetFullState(ctx context.Context) (*hue.FullState, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.bridges.state")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
-	}
-
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
-	}
-
-	path := fmt.Sprintf("%s/api/%s", host, user)
-	req, err := http.NewRequest(http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	client := http.Client{
-		Timeout: time.Second * 5,
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
-	}
-
-	var result interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	var state hue.FullState
+	if err := c.get(ctx, "", &state); err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return &state, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"testing"
+	"time"
 
 	hue "github.com/ninnemana/huego"
 
@@ -70,3 +71,41 @@ func TestAllBridges(t *testing.T) {
 		t.Log(bridge["internalipaddress"])
 	}
 }
+
+func Test_client_GetFullState(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user": `{
+			"lights": {"2": {"name": "Lamp", "state": {"on": true}}, "1": {"name": "Ceiling", "state": {"on": false}}},
+			"groups": {},
+			"sensors": {"5": {"name": "Hall motion", "type": "ZLLPresence", "state": {"presence": true, "lastupdated": "2020-03-27T11:59:00"}}},
+			"scenes": {"AB34EF5": {"name": "Night", "lights": ["1"]}},
+			"schedules": {"1": {"name": "Wake up", "localtime": "W124/T07:00:00", "command": {"address": "/api/user/groups/0/action", "method": "PUT", "body": {"on": true}}}},
+			"rules": {"3": {"name": "Motion", "conditions": [{"address": "/sensors/5/state/presence", "operator": "dx"}], "actions": [{"address": "/groups/0/action", "method": "PUT", "body": {"on": true}}]}},
+			"config": {"name": "Philips hue", "timezone": "none", "localtime": "2020-03-27T12:00:00"}
+		}`,
+	})
+
+	c := &client{}
+	got, err := c.GetFullState(bridgeContext(srv))
+	if err != nil {
+		t.Fatalf("client.GetFullState() error = %v", err)
+	}
+
+	if len(got.Lights) != 2 || got.Lights[0].ID != 1 || got.Lights[1].Name != "Lamp" {
+		t.Errorf("client.GetFullState() lights = %+v", got.Lights)
+	}
+	if len(got.Sensors) != 1 || got.Sensors[0].ID != 5 {
+		t.Errorf("client.GetFullState() sensors = %+v", got.Sensors)
+	}
+	if len(got.Scenes) != 1 || got.Scenes[0].ID != "AB34EF5" {
+		t.Errorf("client.GetFullState() scenes = %+v", got.Scenes)
+	}
+	if len(got.Schedules) != 1 || got.Schedules[0].ID != 1 || len(got.Rules) != 1 || got.Rules[0].ID != 3 {
+		t.Errorf("client.GetFullState() schedules = %+v, rules = %+v", got.Schedules, got.Rules)
+	}
+
+	now, err := got.LocalTime()
+	if want := time.Date(2020, 3, 27, 12, 0, 0, 0, time.UTC); err != nil || !now.Equal(want) {
+		t.Errorf("FullState.LocalTime() = %v, %v, want %v", now, err, want)
+	}
+}
@@ -1,14 +1,100 @@
 package client
 
 import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
 	"cloud.google.com/go/trace"
 	"github.com/ninnemana/huego"
 )
 
 type client struct {
 	trace *trace.Client
+
+	host   string
+	user   string
+	scheme string
+
+	// clientKey is the key issued with the username for the
+	// entertainment streaming API, it isn't used by REST requests.
+	clientKey string
+
+	timeout time.Duration
+	http    *http.Client
+
+	// refresh has state writes fetch the light again once applied.
+	refresh bool
+}
+
+// New returns a hue.Client configured by opts. The bridge host and
+// username can be left out and provided on each request through the
+// hue.HostKey and hue.UserKey context values instead, which always take
+// precedence over the configured ones.
+func New(opts ...Option) (hue.Client, error) {
+	c := &client{
+		scheme: defaultScheme,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if c.timeout > 0 {
+		hc := *c.httpClient()
+		hc.Timeout = c.timeout
+		c.http = &hc
+	}
+
+	return c, nil
+}
+
+// credentials resolves the bridge address and username for a request,
+// preferring the values set on ctx over the ones the client was created
+// with.
+func (c *client) credentials(ctx context.Context) (string, string, error) {
+	user, _ := ctx.Value(hue.UserKey{}).(string)
+	if user == "" {
+		user = c.user
+	}
+
+	if user == "" {
+		return "", "", hue.ErrNoUser
+	}
+
+	host, _ := ctx.Value(hue.HostKey{}).(string)
+	if host == "" && c.host != "" {
+		host = c.scheme + "://" + c.host
+	}
+
+	if host == "" {
+		return "", "", hue.ErrNoHost
+	}
+
+	if !strings.Contains(host, "://") {
+		scheme := c.scheme
+		if scheme == "" {
+			scheme = defaultScheme
+		}
+
+		host = scheme + "://" + host
+	}
+
+	return strings.TrimSuffix(host, "/"), user, nil
 }
 
-func New() (hue.Client, error) {
-	return &client{}, nil
+// httpClient returns the HTTP client requests to the bridge are made with.
+func (c *client) httpClient() *http.Client {
+	if c.http != nil {
+		return c.http
+	}
+
+	return defaultClient
 }
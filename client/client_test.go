@@ -1,30 +1,221 @@
 package client
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ninnemana/huego"
 )
 
 func TestNew(t *testing.T) {
+	hc := &http.Client{Timeout: 5 * time.Second}
+
 	tests := []struct {
 		name    string
+		opts    []Option
 		want    hue.Client
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "defaults",
+			want: &client{scheme: "http"},
+		},
+		{
+			name: "host and user",
+			opts: []Option{WithHost("192.168.86.133"), WithUsername("user")},
+			want: &client{host: "192.168.86.133", user: "user", scheme: "http"},
+		},
+		{
+			name: "host with scheme",
+			opts: []Option{WithHost("https://hue.local:8443/")},
+			want: &client{host: "hue.local:8443", scheme: "https"},
+		},
+		{
+			name: "http client and timeout",
+			opts: []Option{WithHTTPClient(hc), WithTimeout(time.Second), WithClientKey("321c0c2ebfa7361e55491095b2f5f9db")},
+			want: &client{scheme: "http", clientKey: "321c0c2ebfa7361e55491095b2f5f9db", timeout: time.Second, http: &http.Client{Timeout: time.Second}},
+		},
+		{
+			name: "timeout before http client",
+			opts: []Option{WithTimeout(time.Second), WithHTTPClient(hc)},
+			want: &client{scheme: "http", timeout: time.Second, http: &http.Client{Timeout: time.Second}},
+		},
+		{
+			name: "refresh",
+			opts: []Option{WithRefresh()},
+			want: &client{scheme: "http", refresh: true},
+		},
+		{
+			name: "http client alone",
+			opts: []Option{WithHTTPClient(hc)},
+			want: &client{scheme: "http", http: hc},
+		},
+		{
+			name:    "empty host",
+			opts:    []Option{WithHost(" ")},
+			wantErr: true,
+		},
+		{
+			name:    "host with path",
+			opts:    []Option{WithHost("http://192.168.86.133/api")},
+			wantErr: true,
+		},
+		{
+			name:    "empty username",
+			opts:    []Option{WithUsername("")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid client key",
+			opts:    []Option{WithClientKey("abc")},
+			wantErr: true,
+		},
+		{
+			name:    "nil http client",
+			opts:    []Option{WithHTTPClient(nil)},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			opts:    []Option{WithTimeout(-time.Second)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid scheme",
+			opts:    []Option{WithScheme("ftp")},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := New()
+			got, err := New(tt.opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("New() = %v, want %v", got, tt.want)
+				t.Errorf("New() = %+v, want %+v", got, tt.want)
+			}
+			if hc.Timeout != 5*time.Second {
+				t.Errorf("New() changed the timeout of the http client to %s", hc.Timeout)
 			}
 		})
 	}
 }
+
+func Test_client_credentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		ctx      context.Context
+		wantHost string
+		wantUser string
+		wantErr  error
+	}{
+		{
+			name:     "configured",
+			opts:     []Option{WithHost("192.168.86.133"), WithUsername("user")},
+			ctx:      context.Background(),
+			wantHost: "http://192.168.86.133",
+			wantUser: "user",
+		},
+		{
+			name:     "context override",
+			opts:     []Option{WithHost("192.168.86.133"), WithUsername("user")},
+			ctx:      context.WithValue(context.WithValue(context.Background(), hue.HostKey{}, "http://10.0.0.2/"), hue.UserKey{}, "tenant"),
+			wantHost: "http://10.0.0.2",
+			wantUser: "tenant",
+		},
+		{
+			name:     "context host without scheme",
+			opts:     []Option{WithScheme("https"), WithUsername("user")},
+			ctx:      context.WithValue(context.Background(), hue.HostKey{}, "10.0.0.2"),
+			wantHost: "https://10.0.0.2",
+			wantUser: "user",
+		},
+		{
+			name:    "no user",
+			opts:    []Option{WithHost("192.168.86.133")},
+			ctx:     context.Background(),
+			wantErr: hue.ErrNoUser,
+		},
+		{
+			name:    "no host",
+			opts:    []Option{WithUsername("user")},
+			ctx:     context.WithValue(context.Background(), hue.HostKey{}, ""),
+			wantErr: hue.ErrNoHost,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			host, user, err := c.(*client).credentials(tt.ctx)
+			if err != tt.wantErr {
+				t.Errorf("client.credentials() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if host != tt.wantHost || user != tt.wantUser {
+				t.Errorf("client.credentials() = %s, %s, want %s, %s", host, user, tt.wantHost, tt.wantUser)
+			}
+		})
+	}
+}
+
+// newBridge starts a fake bridge that replies to "METHOD /path" requests
+// with the canned bodies in routes, anything else is a 404.
+func newBridge(t *testing.T, routes map[string]string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Logf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// recordingBridge answers every request with the response for its route
+// and records the body of the last one.
+func recordingBridge(t *testing.T, routes map[string]string) (*httptest.Server, *string) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			data, _ := ioutil.ReadAll(r.Body)
+			body = string(data)
+		}
+
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &body
+}
+
+// bridgeContext returns a context addressing the fake bridge as "user".
+func bridgeContext(srv *httptest.Server) context.Context {
+	return context.WithValue(
+		context.WithValue(context.Background(), hue.HostKey{}, srv.URL),
+		hue.UserKey{},
+		"user",
+	)
+}
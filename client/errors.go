@@ -0,0 +1,57 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ninnemana/huego"
+)
+
+// responseError builds the error for a response with a status code other
+// than 200, preferring the Hue errors held in the body when there are any.
+func responseError(resp *http.Response) error {
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := resultError(data); err != nil {
+		return err
+	}
+
+	return &hue.HTTPError{
+		StatusCode: resp.StatusCode,
+		Body:       string(bytes.TrimSpace(data)),
+	}
+}
+
+// resultError collects the error entries of a bridge result array into
+// hue.APIErrors. It returns nil when data isn't a result array or holds
+// no errors, so it is safe to call on any response body.
+func resultError(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		return nil
+	}
+
+	var results []struct {
+		Error *hue.APIError `json:"error"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil
+	}
+
+	var errs hue.APIErrors
+	for _, r := range results {
+		if r.Error != nil {
+			errs = append(errs, r.Error)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
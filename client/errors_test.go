@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+func Test_resultError(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    hue.ErrorType
+		wantLen int
+	}{
+		{
+			name: "object",
+			data: `{"1": {"name": "Hue color lamp 1"}}`,
+		},
+		{
+			name: "success",
+			data: `[{"success": {"/lights/1/state/on": true}}]`,
+		},
+		{
+			name:    "unauthorized",
+			data:    ` [{"error": {"type": 1, "address": "/lights", "description": "unauthorized user"}}]`,
+			want:    hue.ErrorUnauthorizedUser,
+			wantLen: 1,
+		},
+		{
+			name: "partial",
+			data: `[
+				{"success": {"/lights/1/state/on": true}},
+				{"error": {"type": 201, "address": "/lights/1/state/bri", "description": "parameter, bri, is not modifiable. Device is set to off."}},
+				{"error": {"type": 7, "address": "/lights/1/state/ct", "description": "invalid value, 1000, for parameter, ct"}}
+			]`,
+			want:    hue.ErrorDeviceOff,
+			wantLen: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resultError([]byte(tt.data))
+			if tt.wantLen == 0 {
+				if err != nil {
+					t.Errorf("resultError() error = %v, want nil", err)
+				}
+				return
+			}
+
+			errs, ok := err.(hue.APIErrors)
+			if !ok {
+				t.Fatalf("resultError() = %T, want hue.APIErrors", err)
+			}
+			if len(errs) != tt.wantLen {
+				t.Errorf("resultError() returned %d errors, want %d", len(errs), tt.wantLen)
+			}
+			if !errors.Is(err, &hue.APIError{Type: tt.want}) {
+				t.Errorf("resultError() = %v, want type %d", err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_responseError(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *http.Response
+		want error
+	}{
+		{
+			name: "hue errors",
+			resp: &http.Response{
+				StatusCode: http.StatusForbidden,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`[{"error": {"type": 1, "address": "/", "description": "unauthorized user"}}]`)),
+			},
+			want: hue.ErrUnauthorized,
+		},
+		{
+			name: "plain body",
+			resp: &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("bad gateway\n")),
+			},
+			want: &hue.HTTPError{StatusCode: http.StatusBadGateway, Body: "bad gateway"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := responseError(tt.resp)
+			if httpErr, ok := tt.want.(*hue.HTTPError); ok {
+				got, ok := err.(*hue.HTTPError)
+				if !ok || *got != *httpErr {
+					t.Errorf("responseError() = %#v, want %#v", err, tt.want)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("responseError() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
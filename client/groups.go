@@ -3,87 +3,252 @@ package client
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
-	"cloud.google.com/go/trace"
 	"github.com/ninnemana/huego"
+
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 )
 
-func (c *client) AllGroups(ctx context.Context) ([]interface{}, error) {
-	span := trace.FromContext(ctx).NewChild("hue.http.groups.all")
-	defer span.Finish()
+// groupBody holds the group attributes that can be written to the bridge.
+type groupBody struct {
+	Name    string        `json:"name,omitempty"`
+	Type    hue.GroupType `json:"type,omitempty"`
+	Class   hue.RoomClass `json:"class,omitempty"`
+	Lights  []string      `json:"lights"`
+	Recycle bool          `json:"recycle,omitempty"`
+}
+
+func (c *client) AllGroups(ctx context.Context) ([]hue.Group, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.all")
+	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
+	groups := make(map[string]hue.Group, 0)
+	if err := c.get(ctx, "/groups", &groups); err != nil {
+		return nil, err
 	}
 
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
+	results := make([]hue.Group, 0, len(groups))
+	for key, g := range groups {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Errorf("failed to parse group key into identifier '%s'", key)
+		}
+
+		g.ID = id
+		results = append(results, g)
 	}
 
-	client := http.Client{
-		Timeout: time.Second * 5,
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
+}
+
+// CreateGroup adds a group to the bridge and returns its identifier.
+// Rooms are checked against the existing rooms first, since a light can
+// only be assigned to one of them.
+// POST /api/<username>/groups
+func (c *client) CreateGroup(ctx context.Context, group *hue.Group) (int, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.create")
+	defer span.End()
+
+	if err := group.Validate(); err != nil {
+		return 0, err
 	}
 
-	path := fmt.Sprintf("%s/api/%s/groups", host, user)
-	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err := c.checkRooms(ctx, group); err != nil {
+		return 0, err
+	}
+
+	body := groupBody{
+		Name:    group.Name,
+		Type:    group.Type,
+		Class:   group.Class,
+		Lights:  group.Lights,
+		Recycle: group.Recycle,
+	}
+	if body.Lights == nil {
+		body.Lights = []string{}
+	}
+
+	res, err := c.write(ctx, http.MethodPost, "/groups", body)
 	if err != nil {
+		return 0, err
+	}
+
+	return createdID(res)
+}
+
+func (c *client) GetGroup(ctx context.Context, id int) (*hue.Group, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.get")
+	defer span.End()
+
+	var g hue.Group
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d", id), &g); err != nil {
 		return nil, err
 	}
 
-	resp, err := client.Do(req)
+	g.ID = id
+
+	return &g, nil
+}
+
+// SaveGroup updates the name, class and lights of a group, the type of a
+// group can't be changed once it has been created. An empty name or class
+// and nil lights keep the group's current ones, an empty non-nil slice of
+// lights removes every light.
+// PUT /api/<username>/groups/<id>
+func (c *client) SaveGroup(ctx context.Context, id int, group *hue.Group) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.save")
+	defer span.End()
+
+	if id == hue.AllLightsGroup {
+		return nil, errors.New("the group of all lights can't be modified")
+	}
+
+	existing, err := c.GetGroup(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
+	if group.Type != "" && group.Type != existing.Type {
+		return nil, errors.Errorf("group type can't be changed from '%s' to '%s'", existing.Type, group.Type)
+	}
+
+	updated := *group
+	updated.ID = id
+	updated.Type = existing.Type
+	if updated.Name == "" {
+		updated.Name = existing.Name
+	}
+	if updated.Class == "" {
+		updated.Class = existing.Class
+	}
+	if updated.Lights == nil {
+		updated.Lights = existing.Lights
+	}
+
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkRooms(ctx, &updated); err != nil {
+		return nil, err
+	}
+
+	body := groupBody{
+		Name:   updated.Name,
+		Class:  updated.Class,
+		Lights: updated.Lights,
+	}
+	if body.Lights == nil {
+		body.Lights = []string{}
 	}
 
-	groups := make(map[string]interface{}, 0)
-	err = json.NewDecoder(resp.Body).Decode(&groups)
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/groups/%d", id), body)
 	if err != nil {
 		return nil, err
 	}
 
-	results := []interface{}{}
-	for _, l := range groups {
-		lmp, ok := l.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	return res, res.Err()
+}
+
+// SetGroupState applies a state update to every light in a group.
+// PUT /api/<username>/groups/<id>/action
+func (c *client) SetGroupState(ctx context.Context, id int, state *hue.StateUpdate) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.state")
+	defer span.End()
 
-		results = append(results, lmp)
+	if err := state.Validate(nil); err != nil {
+		return nil, err
 	}
 
-	return results, nil
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/groups/%d/action", id), state)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
 }
 
-func (c *client) CreateGroup(ctx context.Context, group interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// RecallScene applies a stored scene to the lights of a group, fading to
+// it over transition.
+// PUT /api/<username>/groups/<id>/action
+func (c *client) RecallScene(ctx context.Context, id int, scene string, transition time.Duration) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.scene")
+	defer span.End()
+
+	if scene == "" {
+		return nil, errors.New("scene identifier can't be empty")
+	}
+
+	state, err := hue.NewStateBuilder(nil).Scene(scene).TransitionTime(transition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SetGroupState(ctx, id, state)
 }
 
-func (c *client) GetGroup(ctx context.Context, id string) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// ToggleGroup turns every light in a group off when any of them is on,
+// and on otherwise.
+func (c *client) ToggleGroup(ctx context.Context, id int) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.toggle")
+	defer span.End()
+
+	existing, err := c.GetGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	on := !existing.State.AnyOn
+	return c.SetGroupState(ctx, id, &hue.StateUpdate{
+		On: &on,
+	})
 }
 
-func (c *client) SaveGroup(ctx context.Context, id string, group interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// SetAllLights applies a state update to every light on the bridge at
+// once through the special group 0.
+func (c *client) SetAllLights(ctx context.Context, state *hue.StateUpdate) (*hue.Result, error) {
+	return c.SetGroupState(ctx, hue.AllLightsGroup, state)
 }
 
-func (c *client) SetGroupState(ctx context.Context, id string, state interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// DeleteGroup removes a group from the bridge, the lights in it are left
+// untouched.
+// DELETE /api/<username>/groups/<id>
+func (c *client) DeleteGroup(ctx context.Context, id int) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.groups.delete")
+	defer span.End()
+
+	if id == hue.AllLightsGroup {
+		return errors.New("the group of all lights can't be deleted")
+	}
+
+	res, err := c.write(ctx, http.MethodDelete, fmt.Sprintf("/groups/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
 }
 
-func (c *client) DeleteGroup(ctx context.Context, id string) error {
-	return hue.ErrNotImplemented
+// checkRooms verifies a room doesn't claim lights already assigned to
+// another room on the bridge.
+func (c *client) checkRooms(ctx context.Context, group *hue.Group) error {
+	if group.Type != hue.GroupRoom || len(group.Lights) == 0 {
+		return nil
+	}
+
+	groups, err := c.AllGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	return group.CheckRooms(groups)
 }
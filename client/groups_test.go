@@ -2,13 +2,27 @@ package client
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/ninnemana/huego"
 
 	"cloud.google.com/go/trace"
 )
 
+const groupsJSON = `{
+	"1": {"name": "Living room", "type": "Room", "class": "Living room", "lights": ["1", "2"], "sensors": [], "action": {"on": true, "bri": 200}, "state": {"any_on": true, "all_on": false}, "recycle": false},
+	"2": {"name": "Reading", "type": "Zone", "class": "Reading", "lights": ["2", "3"], "sensors": [], "action": {"on": false}, "state": {"any_on": false, "all_on": false}, "recycle": false}
+}`
+
 func Test_client_AllGroups(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/groups": groupsJSON,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
@@ -19,10 +33,42 @@ func Test_client_AllGroups(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		want    []interface{}
+		want    []hue.Group
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "success",
+			args: args{
+				ctx: bridgeContext(srv),
+			},
+			want: []hue.Group{
+				{
+					ID:      1,
+					Name:    "Living room",
+					Type:    hue.GroupRoom,
+					Class:   hue.ClassLivingRoom,
+					Lights:  []string{"1", "2"},
+					Sensors: []string{},
+					Action:  hue.LightState{On: true, Bri: 200},
+					State:   hue.GroupState{AnyOn: true},
+				},
+				{
+					ID:      2,
+					Name:    "Reading",
+					Type:    hue.GroupZone,
+					Class:   hue.ClassReading,
+					Lights:  []string{"2", "3"},
+					Sensors: []string{},
+				},
+			},
+		},
+		{
+			name: "no user",
+			args: args{
+				ctx: context.WithValue(bridgeContext(srv), hue.UserKey{}, ""),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -35,28 +81,64 @@ func Test_client_AllGroups(t *testing.T) {
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("client.AllGroups() = %v, want %v", got, tt.want)
+				t.Errorf("client.AllGroups() = %+v, want %+v", got, tt.want)
 			}
 		})
 	}
 }
 
 func Test_client_CreateGroup(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/groups":  groupsJSON,
+		"POST /api/user/groups": `[{"success": {"id": "3"}}]`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
 	type args struct {
 		ctx   context.Context
-		group interface{}
+		group *hue.Group
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    interface{}
+		want    int
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "room",
+			args: args{
+				ctx:   bridgeContext(srv),
+				group: &hue.Group{Name: "Kitchen", Type: hue.GroupRoom, Class: hue.ClassKitchen, Lights: []string{"3"}},
+			},
+			want: 3,
+		},
+		{
+			name: "zone sharing lights",
+			args: args{
+				ctx:   bridgeContext(srv),
+				group: &hue.Group{Name: "Downstairs", Type: hue.GroupZone, Class: hue.ClassDownstairs, Lights: []string{"1", "3"}},
+			},
+			want: 3,
+		},
+		{
+			name: "light in another room",
+			args: args{
+				ctx:   bridgeContext(srv),
+				group: &hue.Group{Name: "Kitchen", Type: hue.GroupRoom, Lights: []string{"2"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown class",
+			args: args{
+				ctx:   bridgeContext(srv),
+				group: &hue.Group{Name: "Kitchen", Type: hue.GroupRoom, Class: "Scullery"},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -76,21 +158,48 @@ func Test_client_CreateGroup(t *testing.T) {
 }
 
 func Test_client_GetGroup(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/groups/0": `{"name": "Group 0", "type": "LightGroup", "lights": ["1", "2", "3"], "sensors": [], "action": {"on": true}, "state": {"any_on": true, "all_on": true}}`,
+		"GET /api/user/groups/9": `[{"error": {"type": 3, "address": "/groups/9", "description": "resource, /groups/9, not available"}}]`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
 	type args struct {
 		ctx context.Context
-		id  string
+		id  int
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    interface{}
+		want    *hue.Group
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "all lights",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  0,
+			},
+			want: &hue.Group{
+				Name:    "Group 0",
+				Type:    hue.GroupLightGroup,
+				Lights:  []string{"1", "2", "3"},
+				Sensors: []string{},
+				Action:  hue.LightState{On: true},
+				State:   hue.GroupState{AnyOn: true, AllOn: true},
+			},
+		},
+		{
+			name: "missing group",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  9,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -103,32 +212,103 @@ func Test_client_GetGroup(t *testing.T) {
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("client.GetGroup() = %v, want %v", got, tt.want)
+				t.Errorf("client.GetGroup() = %+v, want %+v", got, tt.want)
 			}
 		})
 	}
 }
 
 func Test_client_SaveGroup(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"GET /api/user/groups":   groupsJSON,
+		"GET /api/user/groups/1": `{"name": "Living room", "type": "Room", "class": "Living room", "lights": ["1", "2"]}`,
+		"PUT /api/user/groups/1": `[{"success": {"/groups/1/name": "Lounge"}}, {"success": {"/groups/1/lights": ["1", "2", "4"]}}]`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
 	type args struct {
 		ctx   context.Context
-		id    string
-		group interface{}
+		id    int
+		group *hue.Group
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    interface{}
-		wantErr bool
+		name     string
+		fields   fields
+		args     args
+		want     *hue.Result
+		wantBody string
+		wantErr  bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "renamed with new light",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				group: &hue.Group{Name: "Lounge", Lights: []string{"1", "2", "4"}},
+			},
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/groups/1/name", Value: "Lounge"},
+					{Address: "/groups/1/lights", Value: []interface{}{"1", "2", "4"}},
+				},
+			},
+			wantBody: `{"name":"Lounge","class":"Living room","lights":["1","2","4"]}`,
+		},
+		{
+			name: "renamed only",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				group: &hue.Group{Name: "Lounge"},
+			},
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/groups/1/name", Value: "Lounge"},
+					{Address: "/groups/1/lights", Value: []interface{}{"1", "2", "4"}},
+				},
+			},
+			wantBody: `{"name":"Lounge","class":"Living room","lights":["1","2"]}`,
+		},
+		{
+			name: "lights only",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				group: &hue.Group{Lights: []string{"2"}},
+			},
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/groups/1/name", Value: "Lounge"},
+					{Address: "/groups/1/lights", Value: []interface{}{"1", "2", "4"}},
+				},
+			},
+			wantBody: `{"name":"Living room","class":"Living room","lights":["2"]}`,
+		},
+		{
+			name: "all lights",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    hue.AllLightsGroup,
+				group: &hue.Group{Name: "Everything"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "type changed",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				group: &hue.Group{Name: "Lounge", Type: hue.GroupZone},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
 			c := &client{
 				trace: tt.fields.trace,
 			}
@@ -138,29 +318,60 @@ func Test_client_SaveGroup(t *testing.T) {
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("client.SaveGroup() = %v, want %v", got, tt.want)
+				t.Errorf("client.SaveGroup() = %+v, want %+v", got, tt.want)
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.SaveGroup() body = %s, want %s", *body, tt.wantBody)
 			}
 		})
 	}
 }
 
 func Test_client_SetGroupState(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"PUT /api/user/groups/1/action": `[{"success": {"/groups/1/action/on": true}}]`,
+	})
+
+	on := true
+	tooBright := 300
+
 	type fields struct {
 		trace *trace.Client
 	}
 	type args struct {
 		ctx   context.Context
-		id    string
-		state interface{}
+		id    int
+		state *hue.StateUpdate
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    interface{}
+		want    *hue.Result
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "on",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				state: &hue.StateUpdate{On: &on},
+			},
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/groups/1/action/on", Value: true},
+				},
+			},
+		},
+		{
+			name: "invalid state",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				state: &hue.StateUpdate{Bri: &tooBright},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -179,13 +390,164 @@ func Test_client_SetGroupState(t *testing.T) {
 	}
 }
 
+func Test_client_RecallScene(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/user/groups/1/action" {
+			http.NotFound(w, r)
+			return
+		}
+
+		got = nil
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`[{"success": {"/groups/1/action/scene": "AB34EF5"}}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	type args struct {
+		ctx        context.Context
+		id         int
+		scene      string
+		transition time.Duration
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantBody map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name: "with transition",
+			args: args{
+				ctx:        bridgeContext(srv),
+				id:         1,
+				scene:      "AB34EF5",
+				transition: 2 * time.Second,
+			},
+			wantBody: map[string]interface{}{"scene": "AB34EF5", "transitiontime": float64(20)},
+		},
+		{
+			name: "instant",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				scene: "AB34EF5",
+			},
+			wantBody: map[string]interface{}{"scene": "AB34EF5", "transitiontime": float64(0)},
+		},
+		{
+			name: "no scene",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  1,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+
+			c := &client{}
+			_, err := c.RecallScene(tt.args.ctx, tt.args.id, tt.args.scene, tt.args.transition)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.RecallScene() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("client.RecallScene() sent %v, want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_ToggleGroup(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/groups/1":        `{"name": "Living room", "type": "Room", "state": {"any_on": true, "all_on": false}}`,
+		"PUT /api/user/groups/1/action": `[{"success": {"/groups/1/action/on": false}}]`,
+		"GET /api/user/groups/2":        `{"name": "Kitchen", "type": "Room", "state": {"any_on": false, "all_on": false}}`,
+		"PUT /api/user/groups/2/action": `[{"success": {"/groups/2/action/on": true}}]`,
+	})
+
+	tests := []struct {
+		name    string
+		id      int
+		want    *hue.Result
+		wantErr bool
+	}{
+		{
+			name: "some lights on",
+			id:   1,
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/groups/1/action/on", Value: false},
+				},
+			},
+		},
+		{
+			name: "all lights off",
+			id:   2,
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/groups/2/action/on", Value: true},
+				},
+			},
+		},
+		{
+			name:    "missing group",
+			id:      3,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{}
+			got, err := c.ToggleGroup(bridgeContext(srv), tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.ToggleGroup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.ToggleGroup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_SetAllLights(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"PUT /api/user/groups/0/action": `[{"success": {"/groups/0/action/on": false}}]`,
+	})
+
+	off := false
+	c := &client{}
+	got, err := c.SetAllLights(bridgeContext(srv), &hue.StateUpdate{On: &off})
+	if err != nil {
+		t.Fatalf("client.SetAllLights() error = %v", err)
+	}
+
+	want := &hue.Result{
+		Success: []hue.AttributeResult{
+			{Address: "/groups/0/action/on", Value: false},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("client.SetAllLights() = %v, want %v", got, want)
+	}
+}
+
 func Test_client_DeleteGroup(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"DELETE /api/user/groups/1": `[{"success": "/groups/1 deleted"}]`,
+		"DELETE /api/user/groups/2": `[{"error": {"type": 3, "address": "/groups/2", "description": "resource, /groups/2, not available"}}]`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
 	type args struct {
 		ctx context.Context
-		id  string
+		id  int
 	}
 	tests := []struct {
 		name    string
@@ -193,7 +555,29 @@ func Test_client_DeleteGroup(t *testing.T) {
 		args    args
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "deleted",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  1,
+			},
+		},
+		{
+			name: "missing group",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "all lights",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  hue.AllLightsGroup,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
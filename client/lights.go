@@ -1,23 +1,24 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/ninnemana/huego"
 
 	"github.com/pkg/errors"
-	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
 )
 
-func (c *client) AllLights(ctx context.Context) ([]interface{}, error) {
+// scanInterval is how often ScanForLights and PairSensors check whether
+// the bridge has finished searching.
+var scanInterval = 2 * time.Second
+
+func (c *client) AllLights(ctx context.Context) ([]hue.Light, error) {
 	ctx, span := trace.StartSpan(ctx, "hue.http.lights.all")
 	now := time.Now().UTC()
 
@@ -26,398 +27,278 @@ func (c *client) AllLights(ctx context.Context) ([]interface{}, error) {
 		span.End()
 	}()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
-	}
-
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
-	}
-
-	client := http.Client{
-		Timeout: time.Second * 5,
-	}
-
-	path := fmt.Sprintf("%s/api/%s/lights", host, user)
-	req, err := http.NewRequest(http.MethodGet, path, nil)
-	if err != nil {
+	lights := make(map[string]hue.Light, 0)
+	if err := c.get(ctx, "/lights", &lights); err != nil {
 		return nil, err
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
-	}
-	defer resp.Body.Close()
-
-	lights := make(map[string]interface{}, 0)
-	err = json.NewDecoder(resp.Body).Decode(&lights)
-	if err != nil {
-		return nil, errors.Errorf("failed to decode result: %v", err)
-	}
-
-	results := []interface{}{}
+	results := make([]hue.Light, 0, len(lights))
 	for key, l := range lights {
 		id, err := strconv.Atoi(key)
 		if err != nil {
 			return nil, errors.Errorf("failed to parse light key into identifier '%s'", key)
 		}
 
-		lmp, ok := l.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		lmp["ID"] = int32(id)
-		results = append(results, lmp)
+		l.ID = id
+		results = append(results, l)
 	}
 
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
 	return results, nil
 }
 
-func (c *client) NewLights(ctx context.Context) (interface{}, error) {
+// NewLights returns the lights found by the latest search along with
+// the state of that search.
+// GET /api/<username>/lights/new
+func (c *client) NewLights(ctx context.Context) (*hue.ScanResult, error) {
 	ctx, span := trace.StartSpan(ctx, "hue.http.lights.new")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
-	}
-
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
+	var scan hue.ScanResult
+	if err := c.get(ctx, "/lights/new", &scan); err != nil {
+		return nil, err
 	}
 
-	client := http.Client{
-		Timeout:   time.Second * 5,
-		Transport: &ochttp.Transport{},
-	}
+	return &scan, nil
+}
 
-	req, err := http.NewRequest(
-		http.MethodGet,
-		fmt.Sprintf("%s/api/%s/lights/new", host, user),
-		nil,
-	)
+// SearchLights starts a search for new lights, also looking for the
+// lights with the given serial numbers when there are any.
+// POST /api/<username>/lights
+func (c *client) SearchLights(ctx context.Context, deviceIDs []string) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.lights.search")
+	defer span.End()
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	if len(deviceIDs) > hue.MaxSearchSerials {
+		return errors.Errorf("a search accepts at most %d serial numbers, received %d", hue.MaxSearchSerials, len(deviceIDs))
 	}
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
+	var body interface{}
+	if len(deviceIDs) > 0 {
+		body = hue.SearchParams{
+			Devices: deviceIDs,
 		}
-
-		return nil, errors.New(string(data))
 	}
 
-	var scan map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&scan)
+	res, err := c.write(ctx, http.MethodPost, "/lights", body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return scan, nil
+	return res.Err()
 }
 
-func (c *client) SearchLights(ctx context.Context, deviceIDs []string) error {
-	ctx, span := trace.StartSpan(ctx, "hue.http.lights.search")
+// ScanForLights starts a search for new lights and blocks until the
+// bridge reports it has completed, returning the lights it found. The
+// search is abandoned once ctx is done, or after twice hue.ScanDuration
+// when ctx has no deadline.
+func (c *client) ScanForLights(ctx context.Context, serials []string) ([]hue.NewLight, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.lights.scan")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return hue.ErrNoUser
-	}
-
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return hue.ErrNoHost
-	}
+	var scan *hue.ScanResult
+	err := awaitScan(ctx,
+		func(ctx context.Context) error {
+			return c.SearchLights(ctx, serials)
+		},
+		func(ctx context.Context) (string, error) {
+			var err error
+			if scan, err = c.NewLights(ctx); err != nil {
+				return "", err
+			}
 
-	client := http.Client{
-		Timeout: time.Second * 5,
+			return scan.LastScan, nil
+		},
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	var buf io.Reader
-	if len(deviceIDs) > 0 {
-		js, err := json.Marshal(hue.SearchParams{
-			Devices: deviceIDs,
-		})
-		if err != nil {
-			return err
-		}
+	return scan.Lights, nil
+}
 
-		buf = bytes.NewBuffer(js)
+// awaitScan starts a search and polls lastScan until the bridge reports
+// that search has completed. The search is abandoned once ctx is done,
+// or after twice hue.ScanDuration when ctx has no deadline.
+func awaitScan(ctx context.Context, search func(context.Context) error, lastScan func(context.Context) (string, error)) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 2*hue.ScanDuration)
+		defer cancel()
 	}
 
-	req, err := http.NewRequest(
-		http.MethodPost,
-		fmt.Sprintf("%s/api/%s/lights", host, user),
-		buf,
-	)
+	before, err := lastScan(ctx)
 	if err != nil {
 		return err
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
+	if err := search(ctx); err != nil {
 		return err
 	}
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+
+	started := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		last, err := lastScan(ctx)
 		if err != nil {
 			return err
 		}
-		return errors.New(string(data))
-	}
 
-	return nil
+		// the previous result is reported until the bridge picks up
+		// the search, so a completed scan only counts once it changed
+		if last == hue.ScanActive {
+			started = true
+			continue
+		}
+
+		if started || last != before {
+			return nil
+		}
+	}
 }
 
-func (c *client) GetLight(ctx context.Context, id int) (interface{}, error) {
+func (c *client) GetLight(ctx context.Context, id int) (*hue.Light, error) {
 	ctx, span := trace.StartSpan(ctx, "hue.http.lights.get")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
+	var l hue.Light
+	if err := c.get(ctx, fmt.Sprintf("/lights/%d", id), &l); err != nil {
+		return nil, err
 	}
 
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
-	}
+	l.ID = id
 
-	client := http.Client{
-		Timeout: time.Second * 5,
-	}
+	return &l, nil
+}
 
-	path := fmt.Sprintf("%s/api/%s/lights/%d", host, user, id)
-	req, err := http.NewRequest(http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
+// RenameLight sets the name of a light.
+// PUT /api/<username>/lights/<id>
+func (c *client) RenameLight(ctx context.Context, id int, name string) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.lights.rename")
+	defer span.End()
 
-	resp, err := client.Do(req)
-	if err != nil {
+	if err := hue.ValidateName(name); err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
-	}
-
-	data, err := ioutil.ReadAll(resp.Body)
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/lights/%d", id), struct {
+		Name string `json:"name"`
+	}{
+		Name: name,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var l map[string]interface{}
-	if err := json.Unmarshal(data, &l); err != nil {
-		return nil, errors.Errorf("failed to encode '%s' to Light: %v", data, err)
-	}
-
-	l["ID"] = int32(id)
-
-	return l, nil
+	return res, res.Err()
 }
 
-func (c *client) RenameLight(ctx context.Context, id string, newName string) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
-}
-
-func (c *client) LightState(ctx context.Context, id int, state interface{}) (interface{}, error) {
-	ctx, span := trace.StartSpan(ctx, "hue.http.lights.state")
+// SetLightConfig updates the configuration of a light, such as its
+// power-on behaviour. The light is fetched first so startup changes are
+// only sent to lights that report supporting them.
+// PUT /api/<username>/lights/<id>/config
+func (c *client) SetLightConfig(ctx context.Context, id int, config *hue.LightConfigUpdate) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.lights.config")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
-	}
-
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
-	}
-
-	client := http.Client{
-		Timeout: time.Second * 5,
-	}
-
-	data, err := json.Marshal(state)
-	if err != nil {
+	if err := config.Validate(nil); err != nil {
 		return nil, err
 	}
 
-	path := fmt.Sprintf("%s/api/%s/lights/%d/state", host, user, id)
-	req, err := http.NewRequest(http.MethodPut, path, bytes.NewBuffer(data))
+	existing, err := c.GetLight(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
+	if err := config.Validate(existing); err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
-	}
-
-	data, err = ioutil.ReadAll(resp.Body)
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/lights/%d/config", id), config)
 	if err != nil {
 		return nil, err
 	}
 
-	var res []interface{}
-	if err = json.Unmarshal(data, &res); err != nil {
-		return nil, err
-	}
-
-	for _, r := range res {
-		switch r.(type) {
-		case map[string]interface{}:
-			result := r.(map[string]interface{})
-			switch {
-			case result["error"] != nil:
-				switch result["error"].(type) {
-				case map[string]interface{}:
-					e := result["error"].(map[string]interface{})
-					switch e["description"].(type) {
-					case string:
-						return nil, errors.Errorf("failed to set state: %s", e["description"])
-					}
-				}
-
-				return nil, errors.Errorf("state update failed")
-			}
-		default:
-			return nil, errors.Errorf("failed to read state response")
-		}
-	}
-
-	return c.GetLight(ctx, id)
+	return res, res.Err()
 }
 
-func (c *client) Toggle(ctx context.Context, id int) (interface{}, error) {
-	ctx, span := trace.StartSpan(ctx, "hue.http.lights.toggle")
+// LightState applies a state update to a light. Only the documented
+// ranges are checked, updates built with Light.StateBuilder are also
+// checked against the capabilities of the light.
+// PUT /api/<username>/lights/<id>/state
+func (c *client) LightState(ctx context.Context, id int, state *hue.StateUpdate) (*hue.StateResult, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.lights.state")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoUser
+	if err := state.Validate(nil); err != nil {
+		return nil, err
 	}
 
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return nil, hue.ErrNoHost
+	if state.Scene != "" {
+		return nil, &hue.ValidationError{
+			Fields: []hue.FieldError{
+				{Field: "scene", Value: state.Scene, Reason: "can only be recalled on a group"},
+			},
+		}
 	}
 
-	res, err := c.GetLight(ctx, id)
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/lights/%d/state", id), state)
 	if err != nil {
 		return nil, err
 	}
 
-	existing, ok := res.(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("failed to convert '%T' to *light.Light", res)
-	}
-
-	alreadyOn := false
-	existingMap, ok := existing["state"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("existing could not be mapped from '%T' to map[string]interface{}", existing)
-	}
-
-	on, ok := existingMap["on"].(bool)
-	if !ok {
-		return nil, errors.Errorf("existing could not be mapped from '%T' to bool", existingMap["on"])
-	}
-
-	if on {
-		alreadyOn = true
-	}
-
-	client := http.Client{
-		Timeout: time.Second * 5,
-	}
+	return c.stateResult(ctx, id, res)
+}
 
-	body := fmt.Sprintf(`{"on": %t}`, !alreadyOn)
+func (c *client) Toggle(ctx context.Context, id int) (*hue.StateResult, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.lights.toggle")
+	defer span.End()
 
-	path := fmt.Sprintf("%s/api/%s/lights/%d/state", host, user, id)
-	req, err := http.NewRequest(http.MethodPut, path, bytes.NewBuffer([]byte(body)))
+	existing, err := c.GetLight(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := client.Do(req)
+	on := !existing.State.On
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/lights/%d/state", id), &hue.StateUpdate{
+		On: &on,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, errors.New(string(data))
-	}
-
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.stateResult(ctx, id, res)
+}
 
-	var result []interface{}
-	if err = json.Unmarshal(data, &result); err != nil {
-		return nil, err
+// stateResult builds the result of a state write to light id. Rejected
+// attributes are returned as the error alongside the result, the light
+// is only fetched again when the client was created with WithRefresh.
+func (c *client) stateResult(ctx context.Context, id int, res *hue.Result) (*hue.StateResult, error) {
+	result := &hue.StateResult{
+		Result: *res,
 	}
 
-	for _, r := range result {
-		switch r.(type) {
-		case map[string]interface{}:
-			result := r.(map[string]interface{})
-			switch {
-			case result["error"] != nil:
-				switch result["error"].(type) {
-				case map[string]interface{}:
-					e := result["error"].(map[string]interface{})
-					switch e["description"].(type) {
-					case string:
-						return nil, errors.Errorf("failed to set state: %s", e["description"])
-					}
-				}
-
-				return nil, errors.Errorf("state update failed")
-			}
-		default:
-			return nil, errors.Errorf("failed to read state response")
+	if c.refresh {
+		l, err := c.GetLight(ctx, id)
+		if err != nil {
+			return result, err
 		}
+
+		result.Light = l
 	}
 
-	return c.GetLight(ctx, id)
+	return result, result.Err()
 }
 
 // DeleteLight removes a light from the registered devices on the authenticated bridge.
@@ -426,38 +307,10 @@ func (c *client) DeleteLight(ctx context.Context, id string) error {
 	ctx, span := trace.StartSpan(ctx, "hue.http.lights.delete")
 	defer span.End()
 
-	user, ok := ctx.Value(hue.UserKey{}).(string)
-	if !ok {
-		return hue.ErrNoUser
-	}
-
-	host, ok := ctx.Value(hue.HostKey{}).(string)
-	if !ok {
-		return hue.ErrNoHost
-	}
-
-	client := http.Client{
-		Timeout: time.Second * 5,
-	}
-
-	path := fmt.Sprintf("%s/api/%s/lights/%s", host, user, id)
-	req, err := http.NewRequest(http.MethodDelete, path, nil)
-	if err != nil {
-		return err
-	}
-
-	resp, err := client.Do(req)
+	res, err := c.write(ctx, http.MethodDelete, fmt.Sprintf("/lights/%s", id), nil)
 	if err != nil {
 		return err
 	}
 
-	if resp.StatusCode != 200 {
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return errors.New("failed to delete light")
-		}
-		return errors.New(string(data))
-	}
-
-	return nil
+	return res.Err()
 }
@@ -2,9 +2,13 @@ package client
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ninnemana/huego"
 
@@ -167,6 +171,10 @@ func Test_client_NewLights(t *testing.T) {
 }
 
 func Test_client_SearchLights(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"POST /api/user/lights": `[{"success": {"/lights": "Searching for new devices"}}]`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
@@ -180,7 +188,27 @@ func Test_client_SearchLights(t *testing.T) {
 		args    args
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "all lights",
+			args: args{
+				ctx: bridgeContext(srv),
+			},
+		},
+		{
+			name: "serials",
+			args: args{
+				ctx:       bridgeContext(srv),
+				deviceIDs: []string{"45AF34", "543636"},
+			},
+		},
+		{
+			name: "too many serials",
+			args: args{
+				ctx:       bridgeContext(srv),
+				deviceIDs: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -194,6 +222,100 @@ func Test_client_SearchLights(t *testing.T) {
 	}
 }
 
+func Test_client_ScanForLights(t *testing.T) {
+	defer func(d time.Duration) { scanInterval = d }(scanInterval)
+	scanInterval = time.Millisecond
+
+	// scripted replays the scan states in order, repeating the last one.
+	scripted := func(states ...string) *httptest.Server {
+		var mu sync.Mutex
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.URL.Path {
+			case "POST /api/user/lights":
+				w.Write([]byte(`[{"success": {"/lights": "Searching for new devices"}}]`))
+			case "GET /api/user/lights/new":
+				mu.Lock()
+				state := states[0]
+				if len(states) > 1 {
+					states = states[1:]
+				}
+				mu.Unlock()
+
+				w.Write([]byte(state))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(srv.Close)
+
+		return srv
+	}
+
+	type args struct {
+		ctx     context.Context
+		serials []string
+	}
+	tests := []struct {
+		name    string
+		srv     *httptest.Server
+		timeout time.Duration
+		want    []hue.NewLight
+		wantErr bool
+	}{
+		{
+			name: "found lights",
+			srv: scripted(
+				`{"lastscan": "none"}`,
+				`{"lastscan": "active"}`,
+				`{"lastscan": "active", "7": {"name": "Hue Lamp 7"}}`,
+				`{"lastscan": "2012-10-29T12:00:00", "7": {"name": "Hue Lamp 7"}, "8": {"name": "Hue Lamp 8"}}`,
+			),
+			want: []hue.NewLight{
+				{ID: 7, Name: "Hue Lamp 7"},
+				{ID: 8, Name: "Hue Lamp 8"},
+			},
+		},
+		{
+			name: "previous scan reported first",
+			srv: scripted(
+				`{"lastscan": "2012-10-29T12:00:00", "3": {"name": "Hue Lamp 3"}}`,
+				`{"lastscan": "2012-10-29T12:00:00", "3": {"name": "Hue Lamp 3"}}`,
+				`{"lastscan": "active"}`,
+				`{"lastscan": "2012-10-29T12:01:00"}`,
+			),
+		},
+		{
+			name: "never completes",
+			srv: scripted(
+				`{"lastscan": "none"}`,
+				`{"lastscan": "active"}`,
+			),
+			timeout: 50 * time.Millisecond,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bridgeContext(tt.srv)
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			c := &client{}
+			got, err := c.ScanForLights(ctx, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.ScanForLights() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.ScanForLights() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_client_GetLight(t *testing.T) {
 	type fields struct {
 		trace *trace.Client
@@ -206,7 +328,7 @@ func Test_client_GetLight(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		want    interface{}
+		want    *hue.Light
 		wantErr bool
 	}{
 		// TODO: Add test cases.
@@ -229,22 +351,70 @@ func Test_client_GetLight(t *testing.T) {
 }
 
 func Test_client_RenameLight(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"PUT /api/user/lights/1": `[{"success": {"/lights/1/name": "Desk"}}]`,
+		"PUT /api/user/lights/2": `[{"error": {"type": 3, "address": "/lights/2", "description": "resource, /lights/2, not available"}}]`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
 	type args struct {
 		ctx  context.Context
-		id   string
+		id   int
 		name string
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    interface{}
+		want    *hue.Result
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "renamed",
+			args: args{
+				ctx:  bridgeContext(srv),
+				id:   1,
+				name: "Desk",
+			},
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/lights/1/name", Value: "Desk"},
+				},
+			},
+		},
+		{
+			name: "missing light",
+			args: args{
+				ctx:  bridgeContext(srv),
+				id:   2,
+				name: "Desk",
+			},
+			want: &hue.Result{
+				Errors: hue.APIErrors{
+					{Type: hue.ErrorResourceNotAvailable, Address: "/lights/2", Description: "resource, /lights/2, not available"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty name",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "name too long",
+			args: args{
+				ctx:  bridgeContext(srv),
+				id:   1,
+				name: "Living room floor lamp by the window",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -263,28 +433,208 @@ func Test_client_RenameLight(t *testing.T) {
 	}
 }
 
-func Test_client_LightState(t *testing.T) {
+func Test_client_SetLightConfig(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/lights/1":        `{"config": {"archetype": "sultanbulb", "startup": {"mode": "safety", "configured": true}}}`,
+		"PUT /api/user/lights/1/config": `[{"success": {"/lights/1/config/startup/mode": "powerfail"}}]`,
+		"GET /api/user/lights/2":        `{"config": {"archetype": "classicbulb"}}`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
+	type args struct {
+		ctx    context.Context
+		id     int
+		config *hue.LightConfigUpdate
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *hue.Result
+		wantErr bool
+	}{
+		{
+			name: "startup mode",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  1,
+				config: &hue.LightConfigUpdate{
+					Startup: &hue.StartupUpdate{Mode: hue.StartupPowerfail},
+				},
+			},
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/lights/1/config/startup/mode", Value: "powerfail"},
+				},
+			},
+		},
+		{
+			name: "startup not supported",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  2,
+				config: &hue.LightConfigUpdate{
+					Startup: &hue.StartupUpdate{Mode: hue.StartupPowerfail},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "nil config",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing light",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  3,
+				config: &hue.LightConfigUpdate{
+					Startup: &hue.StartupUpdate{Mode: hue.StartupPowerfail},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{
+				trace: tt.fields.trace,
+			}
+			got, err := c.SetLightConfig(tt.args.ctx, tt.args.id, tt.args.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.SetLightConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.SetLightConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_LightState(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"PUT /api/user/lights/1/state": `[{"success": {"/lights/1/state/on": true}}, {"success": {"/lights/1/state/bri": 200}}]`,
+		"PUT /api/user/lights/2/state": `[{"success": {"/lights/2/state/on": true}}, {"error": {"type": 7, "address": "/lights/2/state/ct", "description": "invalid value, 153, for parameter, ct"}}]`,
+		"GET /api/user/lights/1":       `{"state": {"on": true, "bri": 200, "reachable": true}, "name": "Desk"}`,
+	})
+
+	on := true
+	bri := 200
+	ct := 153
+	tooBright := 300
+
+	type fields struct {
+		trace   *trace.Client
+		refresh bool
+	}
 	type args struct {
 		ctx   context.Context
 		id    int
-		state interface{}
+		state *hue.StateUpdate
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    interface{}
+		want    *hue.StateResult
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "applied",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				state: &hue.StateUpdate{On: &on, Bri: &bri},
+			},
+			want: &hue.StateResult{
+				Result: hue.Result{
+					Success: []hue.AttributeResult{
+						{Address: "/lights/1/state/on", Value: true},
+						{Address: "/lights/1/state/bri", Value: float64(200)},
+					},
+				},
+			},
+		},
+		{
+			name: "partially applied",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    2,
+				state: &hue.StateUpdate{On: &on, CT: &ct},
+			},
+			want: &hue.StateResult{
+				Result: hue.Result{
+					Success: []hue.AttributeResult{
+						{Address: "/lights/2/state/on", Value: true},
+					},
+					Errors: hue.APIErrors{
+						{Type: hue.ErrorInvalidValue, Address: "/lights/2/state/ct", Description: "invalid value, 153, for parameter, ct"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "refreshed",
+			fields: fields{refresh: true},
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				state: &hue.StateUpdate{On: &on, Bri: &bri},
+			},
+			want: &hue.StateResult{
+				Result: hue.Result{
+					Success: []hue.AttributeResult{
+						{Address: "/lights/1/state/on", Value: true},
+						{Address: "/lights/1/state/bri", Value: float64(200)},
+					},
+				},
+				Light: &hue.Light{
+					ID:    1,
+					Name:  "Desk",
+					State: hue.LightState{On: true, Bri: 200, Reachable: true},
+				},
+			},
+		},
+		{
+			name: "invalid state",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				state: &hue.StateUpdate{Bri: &tooBright},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scene",
+			args: args{
+				ctx:   bridgeContext(srv),
+				id:    1,
+				state: &hue.StateUpdate{Scene: "AB34EF5"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "nil state",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  1,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &client{
-				trace: tt.fields.trace,
+				trace:   tt.fields.trace,
+				refresh: tt.fields.refresh,
 			}
 			got, err := c.LightState(tt.args.ctx, tt.args.id, tt.args.state)
 			if (err != nil) != tt.wantErr {
@@ -292,13 +642,19 @@ func Test_client_LightState(t *testing.T) {
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("client.LightState() = %v, want %v", got, tt.want)
+				t.Errorf("client.LightState() = %+v, want %+v", got, tt.want)
 			}
 		})
 	}
 }
 
 func Test_client_Toggle(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/lights/1":       `{"state": {"on": true, "reachable": true}}`,
+		"PUT /api/user/lights/1/state": `[{"success": {"/lights/1/state/on": false}}]`,
+		"GET /api/user/lights/2":       `[{"error": {"type": 3, "address": "/lights/2", "description": "resource, /lights/2, not available"}}]`,
+	})
+
 	type fields struct {
 		trace *trace.Client
 	}
@@ -310,10 +666,31 @@ func Test_client_Toggle(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		want    interface{}
+		want    *hue.StateResult
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "turn off",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  1,
+			},
+			want: &hue.StateResult{
+				Result: hue.Result{
+					Success: []hue.AttributeResult{
+						{Address: "/lights/1/state/on", Value: false},
+					},
+				},
+			},
+		},
+		{
+			name: "missing light",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  2,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
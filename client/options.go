@@ -0,0 +1,134 @@
+package client
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultScheme  = "http"
+	defaultTimeout = time.Second * 5
+)
+
+// Option configures the client returned by New.
+type Option func(*client) error
+
+// WithHost sets the address of the bridge, either as a host name or IP
+// address with an optional port, or as a URL including the scheme.
+func WithHost(host string) Option {
+	return func(c *client) error {
+		host = strings.TrimSuffix(strings.TrimSpace(host), "/")
+		if host == "" {
+			return errors.New("bridge host can't be empty")
+		}
+
+		if strings.Contains(host, "://") {
+			u, err := url.Parse(host)
+			if err != nil {
+				return errors.Errorf("invalid bridge host '%s': %v", host, err)
+			}
+
+			if u.Host == "" || (u.Path != "" && u.Path != "/") {
+				return errors.Errorf("invalid bridge host '%s'", host)
+			}
+
+			c.scheme = u.Scheme
+			host = u.Host
+		}
+
+		if _, err := url.Parse("http://" + host); err != nil || strings.ContainsAny(host, "/?#") {
+			return errors.Errorf("invalid bridge host '%s'", host)
+		}
+
+		c.host = host
+		return nil
+	}
+}
+
+// WithUsername sets the whitelisted username requests are made as.
+func WithUsername(user string) Option {
+	return func(c *client) error {
+		if strings.TrimSpace(user) == "" {
+			return errors.New("username can't be empty")
+		}
+
+		c.user = user
+		return nil
+	}
+}
+
+// WithClientKey sets the client key issued alongside the username when
+// it was generated with generateclientkey, it must be 32 hex characters.
+func WithClientKey(key string) Option {
+	return func(c *client) error {
+		if b, err := hex.DecodeString(key); err != nil || len(b) != 16 {
+			return errors.Errorf("client key must be 32 hex characters, received '%s'", key)
+		}
+
+		c.clientKey = key
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to reach the bridge, its
+// transport and timeout are used as is. When WithTimeout is given too,
+// in any order, its timeout takes precedence and is set on a copy of hc,
+// hc itself is left unchanged.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *client) error {
+		if hc == nil {
+			return errors.New("http client can't be nil")
+		}
+
+		c.http = hc
+		return nil
+	}
+}
+
+// WithRefresh has state writes to a light fetch the light again once the
+// write has been applied, populating StateResult.Light at the cost of a
+// second request per write.
+func WithRefresh() Option {
+	return func(c *client) error {
+		c.refresh = true
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each request to the bridge, it takes
+// precedence over the timeout of a client given with WithHTTPClient.
+func WithTimeout(d time.Duration) Option {
+	return func(c *client) error {
+		if d <= 0 {
+			return errors.Errorf("timeout must be positive, received '%s'", d)
+		}
+
+		c.timeout = d
+		return nil
+	}
+}
+
+// WithScheme sets the scheme used to reach the bridge, either http or
+// https.
+func WithScheme(scheme string) Option {
+	return func(c *client) error {
+		c.scheme = strings.ToLower(scheme)
+		return nil
+	}
+}
+
+// validate checks the combination of options once they're all applied.
+func (c *client) validate() error {
+	switch c.scheme {
+	case "http", "https":
+	default:
+		return errors.Errorf("scheme must be http or https, received '%s'", c.scheme)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ninnemana/huego"
+
+	jsoniter "github.com/ninnemana/json-iterator"
+	"github.com/pkg/errors"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+)
+
+var (
+	// transport is shared by every client that isn't given its own HTTP
+	// client, so connections to the bridge are pooled across requests.
+	transport = &ochttp.Transport{
+		Base: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   5 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          10,
+			MaxIdleConnsPerHost:   4,
+			IdleConnTimeout:       90 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+
+	defaultClient = &http.Client{
+		Timeout:   defaultTimeout,
+		Transport: transport,
+	}
+)
+
+// get fetches the resource at path, relative to /api/<username>, and
+// decodes it into out.
+func (c *client) get(ctx context.Context, path string, out interface{}) error {
+	data, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := resultError(data); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return errors.Errorf("failed to decode '%s': %v", path, err)
+	}
+
+	return nil
+}
+
+// write sends body to the resource at path, relative to /api/<username>,
+// and returns the per attribute result reported by the bridge.
+func (c *client) write(ctx context.Context, method, path string, body interface{}) (*hue.Result, error) {
+	data, err := c.do(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResult(data)
+}
+
+// do sends a request for the resource at path, relative to
+// /api/<username>, on the bridge addressed by ctx or the client options.
+func (c *client) do(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	host, user, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.send(ctx, method, fmt.Sprintf("%s/api/%s%s", host, user, path), body)
+}
+
+// send issues a request to url, encoding body as JSON when it isn't nil,
+// and returns the response body once the status code has been checked.
+// The request is bound to ctx, so it is abandoned as soon as ctx is done.
+//
+// Bodies are encoded with encoding/json rather than jsoniter: the vendored
+// jsoniter can't iterate maps on current Go releases, and the bodies of
+// commands, rule actions and scene light states are maps.
+func (c *client) send(ctx context.Context, method, url string, body interface{}) ([]byte, error) {
+	var buf io.Reader
+	if body != nil {
+		js, err := stdjson.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = bytes.NewReader(js)
+	}
+
+	req, err := http.NewRequest(method, url, buf)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	trace.FromContext(ctx).AddAttributes(
+		trace.Int64Attribute("statusCode", int64(resp.StatusCode)),
+	)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// createdID reads the identifier of a resource the bridge created from
+// the result of the POST that created it.
+func createdID(res *hue.Result) (int, error) {
+	if err := res.Err(); err != nil {
+		return 0, err
+	}
+
+	val, ok := res.Value("id")
+	if !ok {
+		return 0, errors.New("bridge didn't report the identifier of the new resource")
+	}
+
+	key, _ := val.(string)
+	id, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, errors.Errorf("failed to parse resource key into identifier '%v'", val)
+	}
+
+	return id, nil
+}
+
+// parseResult splits a bridge result array into the attributes that were
+// applied and the ones that were rejected.
+func parseResult(data []byte) (*hue.Result, error) {
+	var results []struct {
+		Success jsoniter.RawMessage `json:"success"`
+		Error   *hue.APIError       `json:"error"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, errors.Errorf("failed to read result '%s': %v", data, err)
+	}
+
+	res := &hue.Result{}
+	for _, r := range results {
+		if r.Error != nil {
+			res.Errors = append(res.Errors, r.Error)
+		}
+
+		if len(r.Success) == 0 {
+			continue
+		}
+
+		// deletes report a plain message rather than an attribute
+		var msg string
+		if err := json.Unmarshal(r.Success, &msg); err == nil {
+			res.Success = append(res.Success, hue.AttributeResult{
+				Value: msg,
+			})
+			continue
+		}
+
+		var attrs map[string]interface{}
+		if err := json.Unmarshal(r.Success, &attrs); err != nil {
+			return nil, errors.Errorf("failed to read result '%s': %v", data, err)
+		}
+
+		keys := make([]string, 0, len(attrs))
+		for k := range attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			res.Success = append(res.Success, hue.AttributeResult{
+				Address: k,
+				Value:   attrs[k],
+			})
+		}
+	}
+
+	return res, nil
+}
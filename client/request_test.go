@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ninnemana/huego"
+)
+
+func Test_client_send(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		case "/echo":
+			if r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			w.Write([]byte(`[{"success": {"/echo": true}}]`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("bridge is busy"))
+		}
+	}))
+	defer srv.Close()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deadline, done := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer done()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		path    string
+		body    interface{}
+		want    string
+		wantErr error
+	}{
+		{
+			name: "json body",
+			ctx:  context.Background(),
+			path: "/echo",
+			body: hue.SearchParams{Devices: []string{"45AF34"}},
+			want: `[{"success": {"/echo": true}}]`,
+		},
+		{
+			name:    "cancelled",
+			ctx:     cancelled,
+			path:    "/slow",
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "deadline",
+			ctx:     deadline,
+			path:    "/slow",
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "unexpected status",
+			ctx:     context.Background(),
+			path:    "/busy",
+			wantErr: &hue.HTTPError{StatusCode: http.StatusServiceUnavailable, Body: "bridge is busy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{}
+			got, err := c.send(tt.ctx, http.MethodPut, srv.URL+tt.path, tt.body)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("client.send() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("client.send() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *hue.Result
+		wantErr bool
+	}{
+		{
+			name: "attributes",
+			data: `[{"success": {"/lights/1/state/xy": [0.3, 0.3]}}, {"success": {"/lights/1/state/on": true}}]`,
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Address: "/lights/1/state/xy", Value: []interface{}{0.3, 0.3}},
+					{Address: "/lights/1/state/on", Value: true},
+				},
+			},
+		},
+		{
+			name: "delete",
+			data: `[{"success": "/lights/1 deleted"}]`,
+			want: &hue.Result{
+				Success: []hue.AttributeResult{
+					{Value: "/lights/1 deleted"},
+				},
+			},
+		},
+		{
+			name:    "not a result",
+			data:    `{"on": true}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseResult([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseResult() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
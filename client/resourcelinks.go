@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ninnemana/huego"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// resourceLinkBody holds the resourcelink attributes that can be written
+// to the bridge, the type, class and recycle flag are only written when
+// the resourcelink is created.
+type resourceLinkBody struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Type        string     `json:"type,omitempty"`
+	ClassID     int        `json:"classid,omitempty"`
+	Recycle     bool       `json:"recycle,omitempty"`
+	Links       []hue.Link `json:"links"`
+}
+
+func (c *client) AllResourceLinks(ctx context.Context) ([]hue.ResourceLink, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.resourcelinks.all")
+	defer span.End()
+
+	links := make(map[string]hue.ResourceLink, 0)
+	if err := c.get(ctx, "/resourcelinks", &links); err != nil {
+		return nil, err
+	}
+
+	results := make([]hue.ResourceLink, 0, len(links))
+	for key, l := range links {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Errorf("failed to parse resourcelink key into identifier '%s'", key)
+		}
+
+		l.ID = id
+		results = append(results, l)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
+}
+
+func (c *client) GetResourceLink(ctx context.Context, id int) (*hue.ResourceLink, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.resourcelinks.get")
+	defer span.End()
+
+	var l hue.ResourceLink
+	if err := c.get(ctx, fmt.Sprintf("/resourcelinks/%d", id), &l); err != nil {
+		return nil, err
+	}
+
+	l.ID = id
+
+	return &l, nil
+}
+
+// CreateResourceLink adds a resourcelink to the bridge and returns its
+// identifier.
+// POST /api/<username>/resourcelinks
+func (c *client) CreateResourceLink(ctx context.Context, link *hue.ResourceLink) (int, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.resourcelinks.create")
+	defer span.End()
+
+	if err := link.Validate(); err != nil {
+		return 0, err
+	}
+
+	res, err := c.write(ctx, http.MethodPost, "/resourcelinks", resourceLinkBody{
+		Name:        link.Name,
+		Description: link.Description,
+		Type:        hue.ResourceLinkType,
+		ClassID:     link.ClassID,
+		Recycle:     link.Recycle,
+		Links:       link.Links,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return createdID(res)
+}
+
+// UpdateResourceLink replaces the name, description and links of a
+// resourcelink, its class and recycle flag can't be changed.
+// PUT /api/<username>/resourcelinks/<id>
+func (c *client) UpdateResourceLink(ctx context.Context, id int, link *hue.ResourceLink) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.resourcelinks.update")
+	defer span.End()
+
+	if err := link.Validate(); err != nil {
+		return nil, err
+	}
+
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/resourcelinks/%d", id), resourceLinkBody{
+		Name:        link.Name,
+		Description: link.Description,
+		Links:       link.Links,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
+}
+
+// DeleteResourceLink removes a resourcelink from the bridge, the
+// resources it links to are left as they are.
+// DELETE /api/<username>/resourcelinks/<id>
+func (c *client) DeleteResourceLink(ctx context.Context, id int) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.resourcelinks.delete")
+	defer span.End()
+
+	res, err := c.write(ctx, http.MethodDelete, fmt.Sprintf("/resourcelinks/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+func Test_client_AllResourceLinks(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/resourcelinks": `{
+			"12": {"name": "Go to sleep", "description": "Evening routine", "type": "Link", "classid": 10020, "owner": "abc", "recycle": false, "links": ["/rules/5"]},
+			"3": {"name": "Wake up", "description": "Morning routine", "type": "Link", "classid": 10010, "owner": "abc", "recycle": false, "links": ["/schedules/2", "/scenes/AB34EF5"]}
+		}`,
+	})
+
+	c := &client{}
+	got, err := c.AllResourceLinks(bridgeContext(srv))
+	if err != nil {
+		t.Fatalf("client.AllResourceLinks() error = %v", err)
+	}
+
+	want := []hue.ResourceLink{
+		{
+			ID:          3,
+			Name:        "Wake up",
+			Description: "Morning routine",
+			Type:        hue.ResourceLinkType,
+			ClassID:     10010,
+			Owner:       "abc",
+			Links:       []hue.Link{hue.ScheduleLink(2), hue.SceneLink("AB34EF5")},
+		},
+		{
+			ID:          12,
+			Name:        "Go to sleep",
+			Description: "Evening routine",
+			Type:        hue.ResourceLinkType,
+			ClassID:     10020,
+			Owner:       "abc",
+			Links:       []hue.Link{hue.RuleLink(5)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("client.AllResourceLinks() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_client_CreateResourceLink(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"POST /api/user/resourcelinks": `[{"success": {"id": "7"}}]`,
+	})
+
+	tests := []struct {
+		name     string
+		link     hue.ResourceLink
+		want     int
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "created",
+			link:     hue.ResourceLink{Name: "Wake up", ClassID: 10010, Recycle: true, Links: []hue.Link{hue.RuleLink(4)}},
+			want:     7,
+			wantBody: `{"name":"Wake up","description":"","type":"Link","classid":10010,"recycle":true,"links":["/rules/4"]}`,
+		},
+		{
+			name:    "invalid",
+			link:    hue.ResourceLink{Name: "Wake up", Links: []hue.Link{hue.RuleLink(4), hue.RuleLink(4)}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			got, err := c.CreateResourceLink(bridgeContext(srv), &tt.link)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.CreateResourceLink() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				if _, ok := err.(*hue.ValidationError); !ok {
+					t.Errorf("client.CreateResourceLink() error = %T, want *hue.ValidationError", err)
+				}
+				if *body != "" {
+					t.Errorf("client.CreateResourceLink() sent %s", *body)
+				}
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("client.CreateResourceLink() = %v, want %v", got, tt.want)
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.CreateResourceLink() body = %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_UpdateResourceLink(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"PUT /api/user/resourcelinks/7": `[{"success": {"/resourcelinks/7/links": ["/rules/4", "/sensors/9"]}}]`,
+	})
+
+	link := hue.ResourceLink{Name: "Wake up", ClassID: 10010, Recycle: true, Links: []hue.Link{hue.RuleLink(4), hue.SensorLink(9)}}
+
+	c := &client{}
+	if _, err := c.UpdateResourceLink(bridgeContext(srv), 7, &link); err != nil {
+		t.Fatalf("client.UpdateResourceLink() error = %v", err)
+	}
+
+	want := `{"name":"Wake up","description":"","links":["/rules/4","/sensors/9"]}`
+	if *body != want {
+		t.Errorf("client.UpdateResourceLink() body = %s, want %s", *body, want)
+	}
+}
+
+func Test_client_DeleteResourceLink(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"DELETE /api/user/resourcelinks/7": `[{"success": "/resourcelinks/7 deleted"}]`,
+	})
+
+	c := &client{}
+	if err := c.DeleteResourceLink(bridgeContext(srv), 7); err != nil {
+		t.Errorf("client.DeleteResourceLink() error = %v", err)
+	}
+}
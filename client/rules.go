@@ -1,23 +1,161 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
 
-func (c *client) AllRules() ([]interface{}, error) {
-	return nil, hue.ErrNotImplemented
+	"github.com/ninnemana/huego"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// ruleBody holds the rule attributes that can be written to the bridge.
+type ruleBody struct {
+	Name       string           `json:"name,omitempty"`
+	Status     string           `json:"status,omitempty"`
+	Recycle    bool             `json:"recycle,omitempty"`
+	Conditions []hue.Condition  `json:"conditions"`
+	Actions    []hue.RuleAction `json:"actions"`
+}
+
+func newRuleBody(r *hue.Rule) ruleBody {
+	return ruleBody{
+		Name:       r.Name,
+		Status:     r.Status,
+		Recycle:    r.Recycle,
+		Conditions: r.Conditions,
+		Actions:    r.Actions,
+	}
+}
+
+func (c *client) AllRules(ctx context.Context) ([]hue.Rule, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.rules.all")
+	defer span.End()
+
+	rules := make(map[string]hue.Rule, 0)
+	if err := c.get(ctx, "/rules", &rules); err != nil {
+		return nil, err
+	}
+
+	results := make([]hue.Rule, 0, len(rules))
+	for key, r := range rules {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Errorf("failed to parse rule key into identifier '%s'", key)
+		}
+
+		r.ID = id
+		results = append(results, r)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
+}
+
+func (c *client) GetRule(ctx context.Context, id int) (*hue.Rule, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.rules.get")
+	defer span.End()
+
+	var r hue.Rule
+	if err := c.get(ctx, fmt.Sprintf("/rules/%d", id), &r); err != nil {
+		return nil, err
+	}
+
+	r.ID = id
+
+	return &r, nil
 }
 
-func (c *client) GetRule(string) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// CreateRule adds a rule to the bridge and returns its identifier. The
+// resources the rule refers to are fetched first so rules referring to
+// missing resources are rejected before they're sent.
+// POST /api/<username>/rules
+func (c *client) CreateRule(ctx context.Context, rule *hue.Rule) (int, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.rules.create")
+	defer span.End()
+
+	if err := c.validateRule(ctx, rule); err != nil {
+		return 0, err
+	}
+
+	res, err := c.write(ctx, http.MethodPost, "/rules", newRuleBody(rule))
+	if err != nil {
+		return 0, err
+	}
+
+	return createdID(res)
 }
 
-func (c *client) CreateRule(interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// UpdateRule replaces the name, status, conditions and actions of a rule,
+// they are validated as when the rule is created.
+// PUT /api/<username>/rules/<id>
+func (c *client) UpdateRule(ctx context.Context, id int, rule *hue.Rule) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.rules.update")
+	defer span.End()
+
+	if err := c.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/rules/%d", id), newRuleBody(rule))
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
 }
 
-func (c *client) UpdateRule(string, interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// DeleteRule removes a rule from the bridge.
+// DELETE /api/<username>/rules/<id>
+func (c *client) DeleteRule(ctx context.Context, id int) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.rules.delete")
+	defer span.End()
+
+	res, err := c.write(ctx, http.MethodDelete, fmt.Sprintf("/rules/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
 }
 
-func (c *client) DeleteRule(string) error {
-	return hue.ErrNotImplemented
+// validateRule validates the rule and checks the resources it refers to
+// exist, only the kinds of resource it refers to are fetched.
+func (c *client) validateRule(ctx context.Context, rule *hue.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	var (
+		res hue.Resources
+		err error
+	)
+	for _, kind := range rule.Kinds() {
+		switch kind {
+		case "lights":
+			res.Lights, err = c.AllLights(ctx)
+		case "groups":
+			res.Groups, err = c.AllGroups(ctx)
+		case "sensors":
+			res.Sensors, err = c.AllSensors(ctx)
+		case "scenes":
+			res.Scenes, err = c.AllScenes(ctx)
+		case "schedules":
+			res.Schedules, err = c.AllSchedules(ctx)
+		case "rules":
+			res.Rules, err = c.AllRules(ctx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return rule.CheckAddresses(&res)
 }
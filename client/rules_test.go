@@ -0,0 +1,129 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+func Test_client_AllRules(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/rules": `{
+			"2": {"name": "Dimmer off", "owner": "abc", "status": "enabled", "conditions": [{"address": "/sensors/2/state/buttonevent", "operator": "eq", "value": "4002"}], "actions": [{"address": "/groups/0/action", "method": "PUT", "body": {"on": false}}]},
+			"1": {"name": "Dimmer on", "owner": "abc", "status": "enabled", "conditions": [{"address": "/sensors/2/state/lastupdated", "operator": "dx"}], "actions": [{"address": "/groups/0/action", "method": "PUT", "body": {"on": true}}]}
+		}`,
+	})
+
+	c := &client{}
+	got, err := c.AllRules(bridgeContext(srv))
+	if err != nil {
+		t.Fatalf("client.AllRules() error = %v", err)
+	}
+
+	want := []hue.Rule{
+		{
+			ID:         1,
+			Name:       "Dimmer on",
+			Owner:      "abc",
+			Status:     hue.RuleEnabled,
+			Conditions: []hue.Condition{{Address: "/sensors/2/state/lastupdated", Operator: hue.OpDx}},
+			Actions:    []hue.RuleAction{{Address: "/groups/0/action", Method: http.MethodPut, Body: map[string]interface{}{"on": true}}},
+		},
+		{
+			ID:         2,
+			Name:       "Dimmer off",
+			Owner:      "abc",
+			Status:     hue.RuleEnabled,
+			Conditions: []hue.Condition{{Address: "/sensors/2/state/buttonevent", Operator: hue.OpEq, Value: "4002"}},
+			Actions:    []hue.RuleAction{{Address: "/groups/0/action", Method: http.MethodPut, Body: map[string]interface{}{"on": false}}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("client.AllRules() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_client_CreateRule(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"GET /api/user/sensors": `{"2": {"name": "Dimmer", "type": "ZLLSwitch", "modelid": "RWL021", "state": {"buttonevent": 1002}}}`,
+		"GET /api/user/groups":  `{"1": {"name": "Living room", "type": "Room", "lights": ["1"]}}`,
+		"POST /api/user/rules":  `[{"success": {"id": "4"}}]`,
+	})
+
+	tests := []struct {
+		name     string
+		rule     hue.Rule
+		want     int
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name: "created",
+			rule: hue.Rule{
+				Name:       "Dimmer on",
+				Conditions: []hue.Condition{{Address: "/sensors/2/state/buttonevent", Operator: hue.OpEq, Value: "1002"}},
+				Actions:    []hue.RuleAction{{Address: "/groups/1/action", Method: http.MethodPut, Body: map[string]interface{}{"on": true}}},
+			},
+			want:     4,
+			wantBody: `{"name":"Dimmer on","conditions":[{"address":"/sensors/2/state/buttonevent","operator":"eq","value":"1002"}],"actions":[{"address":"/groups/1/action","method":"PUT","body":{"on":true}}]}`,
+		},
+		{
+			name: "invalid",
+			rule: hue.Rule{
+				Conditions: []hue.Condition{{Address: "/sensors/2/state/buttonevent", Operator: hue.OpGt, Value: "high"}},
+				Actions:    []hue.RuleAction{{Address: "/groups/1/action", Method: http.MethodPut, Body: map[string]interface{}{"on": true}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing resources",
+			rule: hue.Rule{
+				Conditions: []hue.Condition{{Address: "/sensors/3/state/buttonevent", Operator: hue.OpDx}},
+				Actions:    []hue.RuleAction{{Address: "/groups/2/action", Method: http.MethodPut, Body: map[string]interface{}{"on": true}}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			got, err := c.CreateRule(bridgeContext(srv), &tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.CreateRule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				if _, ok := err.(*hue.ValidationError); !ok {
+					t.Errorf("client.CreateRule() error = %T, want *hue.ValidationError", err)
+				}
+				if *body != "" {
+					t.Errorf("client.CreateRule() sent %s", *body)
+				}
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("client.CreateRule() = %v, want %v", got, tt.want)
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.CreateRule() body = %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_DeleteRule(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"DELETE /api/user/rules/4": `[{"success": "/rules/4 deleted"}]`,
+	})
+
+	c := &client{}
+	if err := c.DeleteRule(bridgeContext(srv), 4); err != nil {
+		t.Errorf("client.DeleteRule() error = %v", err)
+	}
+}
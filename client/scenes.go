@@ -1,23 +1,233 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
 
-func (c *client) AllScenes() ([]interface{}, error) {
-	return nil, hue.ErrNotImplemented
+	"github.com/ninnemana/huego"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// sceneBody holds the scene attributes that can be written to the bridge.
+type sceneBody struct {
+	Name            string          `json:"name,omitempty"`
+	Type            hue.SceneType   `json:"type,omitempty"`
+	Group           string          `json:"group,omitempty"`
+	Lights          []string        `json:"lights,omitempty"`
+	Recycle         bool            `json:"recycle,omitempty"`
+	AppData         *hue.AppData    `json:"appdata,omitempty"`
+	Picture         string          `json:"picture,omitempty"`
+	LightStates     hue.LightStates `json:"lightstates,omitempty"`
+	StoreLightState bool            `json:"storelightstate,omitempty"`
 }
 
-func (c *client) GetScene(string) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+func (c *client) AllScenes(ctx context.Context) ([]hue.Scene, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.scenes.all")
+	defer span.End()
+
+	scenes := make(map[string]hue.Scene, 0)
+	if err := c.get(ctx, "/scenes", &scenes); err != nil {
+		return nil, err
+	}
+
+	results := make([]hue.Scene, 0, len(scenes))
+	for id, s := range scenes {
+		s.ID = id
+		results = append(results, s)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
 }
 
-func (c *client) CreateScene(interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// GetScene returns a scene along with the states it recalls on each of
+// its lights.
+func (c *client) GetScene(ctx context.Context, id string) (*hue.Scene, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.scenes.get")
+	defer span.End()
+
+	var s hue.Scene
+	if err := c.get(ctx, "/scenes/"+id, &s); err != nil {
+		return nil, err
+	}
+
+	s.ID = id
+
+	return &s, nil
 }
 
-func (c *client) SetScene(string, interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// CreateScene adds a scene to the bridge and returns its identifier. When
+// the scene has no light states the bridge stores the current state of
+// its lights.
+// POST /api/<username>/scenes
+func (c *client) CreateScene(ctx context.Context, scene *hue.Scene) (string, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.scenes.create")
+	defer span.End()
+
+	if err := scene.Validate(); err != nil {
+		return "", err
+	}
+
+	body := sceneBody{
+		Name:    scene.Name,
+		Type:    scene.Type,
+		Group:   scene.Group,
+		Recycle: scene.Recycle,
+		Picture: scene.Picture,
+	}
+	if scene.Type != hue.GroupScene {
+		body.Lights = scene.Lights
+	}
+	if scene.AppData != (hue.AppData{}) {
+		body.AppData = &scene.AppData
+	}
+
+	body.LightStates = scene.LightStates
+
+	res, err := c.write(ctx, http.MethodPost, "/scenes", body)
+	if err != nil {
+		return "", err
+	}
+
+	if err := res.Err(); err != nil {
+		return "", err
+	}
+
+	val, ok := res.Value("id")
+	if !ok {
+		return "", errors.New("bridge didn't report the identifier of the new scene")
+	}
+
+	id, _ := val.(string)
+	return id, nil
 }
 
-func (c *client) DeleteScene(string) error {
-	return hue.ErrNotImplemented
+// SetScene updates the name, lights, appdata and light states of a scene.
+// The lights of a group scene follow its group and are left out.
+// PUT /api/<username>/scenes/<id>
+func (c *client) SetScene(ctx context.Context, id string, scene *hue.Scene) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.scenes.set")
+	defer span.End()
+
+	if err := scene.Validate(); err != nil {
+		return nil, err
+	}
+
+	body := sceneBody{
+		Name:    scene.Name,
+		Picture: scene.Picture,
+	}
+	if scene.Type != hue.GroupScene {
+		body.Lights = scene.Lights
+	}
+	if scene.AppData != (hue.AppData{}) {
+		body.AppData = &scene.AppData
+	}
+
+	body.LightStates = scene.LightStates
+
+	res, err := c.write(ctx, http.MethodPut, "/scenes/"+id, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
+}
+
+// CaptureScene stores the current state of the lights in a scene. A scene
+// without an ID is created, otherwise the existing scene is overwritten
+// with the current state of its lights. It returns the identifier of the
+// scene.
+func (c *client) CaptureScene(ctx context.Context, scene *hue.Scene) (string, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.scenes.capture")
+	defer span.End()
+
+	captured := *scene
+	captured.LightStates = nil
+
+	if captured.ID == "" {
+		return c.CreateScene(ctx, &captured)
+	}
+
+	if err := captured.Validate(); err != nil {
+		return "", err
+	}
+
+	body := sceneBody{
+		StoreLightState: true,
+	}
+	if captured.Type != hue.GroupScene {
+		body.Lights = captured.Lights
+	}
+
+	res, err := c.write(ctx, http.MethodPut, "/scenes/"+captured.ID, body)
+	if err != nil {
+		return "", err
+	}
+
+	return captured.ID, res.Err()
+}
+
+// SetSceneLightState changes the state a scene recalls on one of its
+// lights. Once the state is checked the scene is fetched, so lights that
+// aren't part of it are rejected.
+// PUT /api/<username>/scenes/<id>/lightstates/<light>
+func (c *client) SetSceneLightState(ctx context.Context, id string, light int, state *hue.StateUpdate) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.scenes.lightstate")
+	defer span.End()
+
+	if err := state.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	if state.Scene != "" {
+		return nil, errors.New("a scene can't be stored as a light state")
+	}
+
+	existing, err := c.GetScene(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strconv.Itoa(light)
+	member := false
+	for _, l := range existing.Lights {
+		if l == key {
+			member = true
+			break
+		}
+	}
+	if !member {
+		return nil, errors.Errorf("light %d isn't part of scene '%s'", light, id)
+	}
+
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/scenes/%s/lightstates/%d", id, light), state)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
+}
+
+// DeleteScene removes a scene from the bridge, scenes locked by a rule or
+// schedule are refused by the bridge.
+// DELETE /api/<username>/scenes/<id>
+func (c *client) DeleteScene(ctx context.Context, id string) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.scenes.delete")
+	defer span.End()
+
+	res, err := c.write(ctx, http.MethodDelete, "/scenes/"+id, nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
 }
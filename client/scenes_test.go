@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+
+	"cloud.google.com/go/trace"
+)
+
+func Test_client_GetScene(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/scenes/4e1c6b20e-on-0": `{
+			"name": "Kathy on 1449133269486", "type": "LightScene", "lights": ["2", "3"], "owner": "ffffffffe0341b1b376a2389376a2389",
+			"recycle": true, "locked": false, "appdata": {}, "picture": "", "lastupdated": "2015-12-03T08:57:13", "version": 2,
+			"lightstates": {"2": {"on": true, "bri": 253, "xy": [0.5, 0.5]}, "3": {"on": true, "ct": 300}}
+		}`,
+	})
+
+	on := true
+	bri, ct := 253, 300
+
+	type fields struct {
+		trace *trace.Client
+	}
+	type args struct {
+		ctx context.Context
+		id  string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *hue.Scene
+		wantErr bool
+	}{
+		{
+			name: "light scene",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  "4e1c6b20e-on-0",
+			},
+			want: &hue.Scene{
+				ID:          "4e1c6b20e-on-0",
+				Name:        "Kathy on 1449133269486",
+				Type:        hue.LightScene,
+				Lights:      []string{"2", "3"},
+				Owner:       "ffffffffe0341b1b376a2389376a2389",
+				Recycle:     true,
+				LastUpdated: "2015-12-03T08:57:13",
+				Version:     2,
+				LightStates: hue.LightStates{
+					"2": {On: &on, Bri: &bri, XY: []float64{0.5, 0.5}},
+					"3": {On: &on, CT: &ct},
+				},
+			},
+		},
+		{
+			name: "missing scene",
+			args: args{
+				ctx: bridgeContext(srv),
+				id:  "missing",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{
+				trace: tt.fields.trace,
+			}
+			got, err := c.GetScene(tt.args.ctx, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.GetScene() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.GetScene() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_CreateScene(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"POST /api/user/scenes": `[{"success": {"id": "Abc123Def456Ghi"}}]`,
+	})
+
+	on := true
+	bri := 120
+
+	tests := []struct {
+		name     string
+		scene    *hue.Scene
+		want     string
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name: "light scene with states",
+			scene: &hue.Scene{
+				Name:        "Reading",
+				Type:        hue.LightScene,
+				Lights:      []string{"1"},
+				AppData:     hue.AppData{Version: 1, Data: "reading"},
+				LightStates: hue.LightStates{"1": {On: &on, Bri: &bri}},
+			},
+			want:     "Abc123Def456Ghi",
+			wantBody: `{"name":"Reading","type":"LightScene","lights":["1"],"appdata":{"version":1,"data":"reading"},"lightstates":{"1":{"on":true,"bri":120}}}`,
+		},
+		{
+			name:     "group scene",
+			scene:    &hue.Scene{Name: "Evening", Type: hue.GroupScene, Group: "1", Lights: []string{"1", "2"}},
+			want:     "Abc123Def456Ghi",
+			wantBody: `{"name":"Evening","type":"GroupScene","group":"1"}`,
+		},
+		{
+			name:    "state for another light",
+			scene:   &hue.Scene{Name: "Reading", Lights: []string{"1"}, LightStates: hue.LightStates{"2": {On: &on}}},
+			wantErr: true,
+		},
+		{
+			name:    "group scene without group",
+			scene:   &hue.Scene{Name: "Evening", Type: hue.GroupScene},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			got, err := c.CreateScene(bridgeContext(srv), tt.scene)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.CreateScene() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("client.CreateScene() = %v, want %v", got, tt.want)
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.CreateScene() sent %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_CaptureScene(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"POST /api/user/scenes":    `[{"success": {"id": "New"}}]`,
+		"PUT /api/user/scenes/Old": `[{"success": {"/scenes/Old/storelightstate": true}}]`,
+	})
+
+	on := true
+
+	tests := []struct {
+		name     string
+		scene    *hue.Scene
+		want     string
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "new scene",
+			scene:    &hue.Scene{Name: "Now", Lights: []string{"1", "2"}, LightStates: hue.LightStates{"1": {On: &on}}},
+			want:     "New",
+			wantBody: `{"name":"Now","lights":["1","2"]}`,
+		},
+		{
+			name:     "existing scene",
+			scene:    &hue.Scene{ID: "Old", Name: "Then", Lights: []string{"1"}},
+			want:     "Old",
+			wantBody: `{"lights":["1"],"storelightstate":true}`,
+		},
+		{
+			name:     "existing group scene",
+			scene:    &hue.Scene{ID: "Old", Name: "Then", Type: hue.GroupScene, Group: "2", Lights: []string{"1"}},
+			want:     "Old",
+			wantBody: `{"storelightstate":true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			got, err := c.CaptureScene(bridgeContext(srv), tt.scene)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.CaptureScene() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("client.CaptureScene() = %v, want %v", got, tt.want)
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.CaptureScene() sent %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_SetSceneLightState(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"GET /api/user/scenes/S1":               `{"name": "Reading", "type": "LightScene", "lights": ["1", "2"]}`,
+		"PUT /api/user/scenes/S1/lightstates/2": `[{"success": {"/scenes/S1/lightstates/2/bri": 100}}]`,
+	})
+
+	bri := 100
+	tooBright := 300
+
+	tests := []struct {
+		name     string
+		light    int
+		state    *hue.StateUpdate
+		want     *hue.Result
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "member light",
+			light:    2,
+			state:    &hue.StateUpdate{Bri: &bri},
+			want:     &hue.Result{Success: []hue.AttributeResult{{Address: "/scenes/S1/lightstates/2/bri", Value: float64(100)}}},
+			wantBody: `{"bri":100}`,
+		},
+		{
+			name:    "other light",
+			light:   3,
+			state:   &hue.StateUpdate{Bri: &bri},
+			wantErr: true,
+		},
+		{
+			name:    "invalid state",
+			light:   2,
+			state:   &hue.StateUpdate{Bri: &tooBright},
+			wantErr: true,
+		},
+		{
+			name:    "missing state",
+			light:   2,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			got, err := c.SetSceneLightState(bridgeContext(srv), "S1", tt.light, tt.state)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.SetSceneLightState() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.SetSceneLightState() = %+v, want %+v", got, tt.want)
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.SetSceneLightState() sent %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
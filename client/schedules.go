@@ -1,23 +1,129 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
 
-func (c *client) AllSchedules() ([]interface{}, error) {
-	return nil, hue.ErrNotImplemented
+	"github.com/ninnemana/huego"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// scheduleBody holds the schedule attributes that can be written to the
+// bridge.
+type scheduleBody struct {
+	Name        string       `json:"name,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Command     *hue.Command `json:"command,omitempty"`
+	LocalTime   string       `json:"localtime,omitempty"`
+	Status      string       `json:"status,omitempty"`
+	AutoDelete  *bool        `json:"autodelete,omitempty"`
+	Recycle     bool         `json:"recycle,omitempty"`
+}
+
+func newScheduleBody(s *hue.Schedule) scheduleBody {
+	return scheduleBody{
+		Name:        s.Name,
+		Description: s.Description,
+		Command:     &s.Command,
+		LocalTime:   s.LocalTime,
+		Status:      s.Status,
+		AutoDelete:  s.AutoDelete,
+		Recycle:     s.Recycle,
+	}
+}
+
+func (c *client) AllSchedules(ctx context.Context) ([]hue.Schedule, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.schedules.all")
+	defer span.End()
+
+	schedules := make(map[string]hue.Schedule, 0)
+	if err := c.get(ctx, "/schedules", &schedules); err != nil {
+		return nil, err
+	}
+
+	results := make([]hue.Schedule, 0, len(schedules))
+	for key, s := range schedules {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Errorf("failed to parse schedule key into identifier '%s'", key)
+		}
+
+		s.ID = id
+		results = append(results, s)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
 }
 
-func (c *client) CreateSchedule(interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// CreateSchedule adds a schedule to the bridge and returns its identifier.
+// POST /api/<username>/schedules
+func (c *client) CreateSchedule(ctx context.Context, schedule *hue.Schedule) (int, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.schedules.create")
+	defer span.End()
+
+	if err := schedule.Validate(); err != nil {
+		return 0, err
+	}
+
+	res, err := c.write(ctx, http.MethodPost, "/schedules", newScheduleBody(schedule))
+	if err != nil {
+		return 0, err
+	}
+
+	return createdID(res)
 }
 
-func (c *client) GetSchedule(string) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+func (c *client) GetSchedule(ctx context.Context, id int) (*hue.Schedule, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.schedules.get")
+	defer span.End()
+
+	var s hue.Schedule
+	if err := c.get(ctx, fmt.Sprintf("/schedules/%d", id), &s); err != nil {
+		return nil, err
+	}
+
+	s.ID = id
+
+	return &s, nil
 }
 
-func (c *client) SetSchedule(string, interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// SetSchedule updates the attributes of a schedule.
+// PUT /api/<username>/schedules/<id>
+func (c *client) SetSchedule(ctx context.Context, id int, schedule *hue.Schedule) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.schedules.set")
+	defer span.End()
+
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/schedules/%d", id), newScheduleBody(schedule))
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
 }
 
-func (c *client) DeleteSchedule(string) error {
-	return hue.ErrNotImplemented
+// DeleteSchedule removes a schedule from the bridge.
+// DELETE /api/<username>/schedules/<id>
+func (c *client) DeleteSchedule(ctx context.Context, id int) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.schedules.delete")
+	defer span.End()
+
+	res, err := c.write(ctx, http.MethodDelete, fmt.Sprintf("/schedules/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
 }
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ninnemana/huego"
+
+	"cloud.google.com/go/trace"
+)
+
+func Test_client_AllSchedules(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/schedules": `{
+			"1": {"name": "Wake up", "description": "", "command": {"address": "/api/user/groups/1/action", "method": "PUT", "body": {"scene": "AB34EF5"}}, "localtime": "W124/T07:00:00", "time": "W124/T06:00:00", "created": "2019-01-02T10:00:00", "status": "enabled"},
+			"2": {"name": "Timer", "description": "", "command": {"address": "/api/user/lights/1/state", "method": "PUT", "body": {"on": false}}, "localtime": "PT00:10:00", "status": "disabled"}
+		}`,
+	})
+
+	type fields struct {
+		trace *trace.Client
+	}
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []hue.Schedule
+		wantErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx: bridgeContext(srv),
+			},
+			want: []hue.Schedule{
+				{
+					ID:   1,
+					Name: "Wake up",
+					Command: hue.Command{
+						Address: "/api/user/groups/1/action",
+						Method:  http.MethodPut,
+						Body:    map[string]interface{}{"scene": "AB34EF5"},
+					},
+					LocalTime: "W124/T07:00:00",
+					Time:      "W124/T06:00:00",
+					Created:   "2019-01-02T10:00:00",
+					Status:    hue.ScheduleEnabled,
+				},
+				{
+					ID:   2,
+					Name: "Timer",
+					Command: hue.Command{
+						Address: "/api/user/lights/1/state",
+						Method:  http.MethodPut,
+						Body:    map[string]interface{}{"on": false},
+					},
+					LocalTime: "PT00:10:00",
+					Status:    hue.ScheduleDisabled,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{
+				trace: tt.fields.trace,
+			}
+			got, err := c.AllSchedules(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.AllSchedules() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.AllSchedules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_CreateSchedule(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`[{"success": {"id": "3"}}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	type args struct {
+		ctx      context.Context
+		schedule *hue.Schedule
+	}
+	tests := []struct {
+		name     string
+		args     args
+		want     int
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name: "timer",
+			args: args{
+				ctx: bridgeContext(srv),
+				schedule: &hue.Schedule{
+					Name: "Off",
+					Command: hue.Command{
+						Address: "/api/user/lights/1/state",
+						Method:  http.MethodPut,
+						Body:    map[string]interface{}{"on": false},
+					},
+					LocalTime: "PT00:10:00",
+				},
+			},
+			want:     3,
+			wantBody: `{"name":"Off","command":{"address":"/api/user/lights/1/state","method":"PUT","body":{"on":false}},"localtime":"PT00:10:00"}`,
+		},
+		{
+			name: "invalid pattern",
+			args: args{
+				ctx: bridgeContext(srv),
+				schedule: &hue.Schedule{
+					Command: hue.Command{
+						Address: "/api/user/lights/1/state",
+						Method:  http.MethodPut,
+					},
+					LocalTime: "W200/T07:00:00",
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = ""
+
+			c := &client{}
+			got, err := c.CreateSchedule(tt.args.ctx, tt.args.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.CreateSchedule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("client.CreateSchedule() = %v, want %v", got, tt.want)
+			}
+			if body != tt.wantBody {
+				t.Errorf("client.CreateSchedule() sent %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_DeleteSchedule(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"DELETE /api/user/schedules/1": `[{"success": "/schedules/1 deleted"}]`,
+	})
+
+	c := &client{}
+	if err := c.DeleteSchedule(bridgeContext(srv), 1); err != nil {
+		t.Errorf("client.DeleteSchedule() error = %v", err)
+	}
+	if err := c.DeleteSchedule(bridgeContext(srv), 2); err == nil {
+		t.Errorf("client.DeleteSchedule() expected an error for a missing schedule")
+	}
+}
+
+func Test_client_TimeZone(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/config": `{"name": "Philips hue", "timezone": "Europe/Amsterdam", "localtime": "2020-03-27T12:00:00"}`,
+	})
+
+	c := &client{}
+	got, err := c.TimeZone(bridgeContext(srv))
+	if err != nil {
+		t.Fatalf("client.TimeZone() error = %v", err)
+	}
+
+	want, _ := time.LoadLocation("Europe/Amsterdam")
+	if got.String() != want.String() {
+		t.Errorf("client.TimeZone() = %v, want %v", got, want)
+	}
+}
@@ -1,35 +1,261 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
 
-func (c *client) AllSensors() ([]interface{}, error) {
-	return nil, hue.ErrNotImplemented
+	"github.com/ninnemana/huego"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// sensorBody holds the sensor attributes that can be written when a
+// sensor is created.
+type sensorBody struct {
+	Name             string            `json:"name"`
+	Type             hue.SensorType    `json:"type"`
+	ModelID          string            `json:"modelid"`
+	ManufacturerName string            `json:"manufacturername"`
+	SWVersion        string            `json:"swversion"`
+	UniqueID         string            `json:"uniqueid"`
+	Recycle          bool              `json:"recycle,omitempty"`
+	State            hue.SensorState   `json:"state,omitempty"`
+	Config           *hue.SensorConfig `json:"config,omitempty"`
+}
+
+func (c *client) AllSensors(ctx context.Context) ([]hue.Sensor, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.all")
+	defer span.End()
+
+	sensors := make(map[string]hue.Sensor, 0)
+	if err := c.get(ctx, "/sensors", &sensors); err != nil {
+		return nil, err
+	}
+
+	results := make([]hue.Sensor, 0, len(sensors))
+	for key, s := range sensors {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Errorf("failed to parse sensor key into identifier '%s'", key)
+		}
+
+		s.ID = id
+		results = append(results, s)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
+}
+
+// CreateSensor adds a CLIP sensor to the bridge and returns its
+// identifier, physical sensors are added by searching for them. The
+// config is only sent when any of it is set, in which case it is sent as
+// is and On should be set for the sensor to be enabled.
+// POST /api/<username>/sensors
+func (c *client) CreateSensor(ctx context.Context, sensor *hue.Sensor) (int, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.create")
+	defer span.End()
+
+	if err := sensor.Validate(); err != nil {
+		return 0, err
+	}
+
+	body := sensorBody{
+		Name:             sensor.Name,
+		Type:             sensor.Type,
+		ModelID:          sensor.ModelID,
+		ManufacturerName: sensor.ManufacturerName,
+		SWVersion:        sensor.SWVersion,
+		UniqueID:         sensor.UniqueID,
+		Recycle:          sensor.Recycle,
+		State:            sensor.State,
+	}
+	if sensor.Config.On || sensor.Config.URL != "" || sensor.Config.Battery != nil || sensor.Config.Reachable != nil {
+		body.Config = &sensor.Config
+	}
+
+	res, err := c.write(ctx, http.MethodPost, "/sensors", body)
+	if err != nil {
+		return 0, err
+	}
+
+	return createdID(res)
 }
 
-func (c *client) CreateSensor(interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// SearchSensors starts a search for new Zigbee sensors, it runs for
+// about hue.ScanDuration.
+// POST /api/<username>/sensors
+func (c *client) SearchSensors(ctx context.Context) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.search")
+	defer span.End()
+
+	res, err := c.write(ctx, http.MethodPost, "/sensors", nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
 }
 
-func (c *client) SearchSensors() error {
-	return hue.ErrNotImplemented
+// NewSensors returns the sensors found by the latest search along with
+// the state of that search.
+// GET /api/<username>/sensors/new
+func (c *client) NewSensors(ctx context.Context) (*hue.SensorScanResult, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.new")
+	defer span.End()
+
+	var scan hue.SensorScanResult
+	if err := c.get(ctx, "/sensors/new", &scan); err != nil {
+		return nil, err
+	}
+
+	return &scan, nil
+}
+
+// PairSensors starts a search for new sensors and blocks until the
+// bridge reports it has completed. The sensor entries found are fetched
+// and grouped by the physical device that registered them. The search is
+// abandoned once ctx is done, or after twice hue.ScanDuration when ctx has
+// no deadline.
+func (c *client) PairSensors(ctx context.Context) ([]hue.SensorDevice, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.pair")
+	defer span.End()
+
+	var scan *hue.SensorScanResult
+	err := awaitScan(ctx, c.SearchSensors, func(ctx context.Context) (string, error) {
+		var err error
+		if scan, err = c.NewSensors(ctx); err != nil {
+			return "", err
+		}
+
+		return scan.LastScan, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := make([]hue.Sensor, 0, len(scan.Sensors))
+	for _, n := range scan.Sensors {
+		s, err := c.GetSensor(ctx, n.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		sensors = append(sensors, *s)
+	}
+
+	return hue.GroupSensors(sensors), nil
 }
 
-func (c *client) NewSensors() ([]interface{}, error) {
-	return nil, hue.ErrNotImplemented
+func (c *client) GetSensor(ctx context.Context, id int) (*hue.Sensor, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.get")
+	defer span.End()
+
+	var s hue.Sensor
+	if err := c.get(ctx, fmt.Sprintf("/sensors/%d", id), &s); err != nil {
+		return nil, err
+	}
+
+	s.ID = id
+
+	return &s, nil
 }
 
-func (c *client) GetSensor(string) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// RenameSensor sets the name of a sensor.
+// PUT /api/<username>/sensors/<id>
+func (c *client) RenameSensor(ctx context.Context, id int, name string) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.rename")
+	defer span.End()
+
+	if err := hue.ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/sensors/%d", id), struct {
+		Name string `json:"name"`
+	}{
+		Name: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
 }
 
-func (c *client) SetSensor(string, interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// SetSensorConfig updates the configuration of a sensor. Once the
+// documented ranges are checked the sensor is fetched, so attributes it
+// doesn't support are rejected before they're sent.
+// PUT /api/<username>/sensors/<id>/config
+func (c *client) SetSensorConfig(ctx context.Context, id int, config *hue.SensorConfigUpdate) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.config")
+	defer span.End()
+
+	if err := config.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	existing, err := c.GetSensor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(existing); err != nil {
+		return nil, err
+	}
+
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/sensors/%d/config", id), config)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
 }
 
-func (c *client) RenameSensor(string, string) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// SetSensorState writes the state of a CLIP sensor. The sensor is fetched
+// first so states are only sent to CLIP sensors of the matching type.
+// PUT /api/<username>/sensors/<id>/state
+func (c *client) SetSensorState(ctx context.Context, id int, state hue.SensorState) (*hue.Result, error) {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.state")
+	defer span.End()
+
+	existing, err := c.GetSensor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !existing.Type.CLIP() {
+		return nil, errors.Errorf("the state of %s sensor %d can't be written", existing.Type, id)
+	}
+
+	if want := hue.NewSensorState(existing.Type); reflect.TypeOf(state) != reflect.TypeOf(want) {
+		return nil, errors.Errorf("sensor %d is a %s, its state is a %T not a %T", id, existing.Type, want, state)
+	}
+
+	res, err := c.write(ctx, http.MethodPut, fmt.Sprintf("/sensors/%d/state", id), state)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
 }
 
-func (c *client) DeleteSensor(string) error {
-	return hue.ErrNotImplemented
+func (c *client) DeleteSensor(ctx context.Context, id int) error {
+	ctx, span := trace.StartSpan(ctx, "hue.http.sensors.delete")
+	defer span.End()
+
+	res, err := c.write(ctx, http.MethodDelete, fmt.Sprintf("/sensors/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
 }
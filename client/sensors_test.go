@@ -0,0 +1,328 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ninnemana/huego"
+
+	"cloud.google.com/go/trace"
+)
+
+func Test_client_AllSensors(t *testing.T) {
+	srv := newBridge(t, map[string]string{
+		"GET /api/user/sensors": `{
+			"1": {"name": "Daylight", "type": "Daylight", "modelid": "PHDL00", "manufacturername": "Philips", "swversion": "1.0",
+				"state": {"daylight": null, "lastupdated": "none"}, "config": {"on": true, "configured": false}},
+			"12": {"name": "Hall sensor", "type": "ZLLTemperature", "modelid": "SML001", "manufacturername": "Philips", "swversion": "6.1.0.18912",
+				"uniqueid": "00:17:88:01:02:00:b5:d1-02-0402", "state": {"temperature": 1875, "lastupdated": "2019-03-01T10:20:30"},
+				"config": {"on": true, "battery": 100, "reachable": true, "alert": "none", "ledindication": false, "usertest": false, "pending": []}}
+		}`,
+	})
+
+	configured := false
+	battery := 100
+	yes, no := true, false
+
+	type fields struct {
+		trace *trace.Client
+	}
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []hue.Sensor
+		wantErr bool
+	}{
+		{
+			name: "typed sensors",
+			args: args{
+				ctx: bridgeContext(srv),
+			},
+			want: []hue.Sensor{
+				{
+					ID:               1,
+					Name:             "Daylight",
+					Type:             hue.Daylight,
+					ModelID:          "PHDL00",
+					ManufacturerName: "Philips",
+					SWVersion:        "1.0",
+					State:            &hue.DaylightState{StateTime: hue.StateTime{LastUpdated: "none"}},
+					Config:           hue.SensorConfig{On: true, Configured: &configured},
+				},
+				{
+					ID:               12,
+					Name:             "Hall sensor",
+					Type:             hue.ZLLTemperature,
+					ModelID:          "SML001",
+					ManufacturerName: "Philips",
+					SWVersion:        "6.1.0.18912",
+					UniqueID:         "00:17:88:01:02:00:b5:d1-02-0402",
+					State:            &hue.TemperatureState{StateTime: hue.StateTime{LastUpdated: "2019-03-01T10:20:30"}, Temperature: 1875},
+					Config: hue.SensorConfig{
+						On:            true,
+						Battery:       &battery,
+						Reachable:     &yes,
+						Alert:         "none",
+						LEDIndication: &no,
+						UserTest:      &no,
+						Pending:       []string{},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{
+				trace: tt.fields.trace,
+			}
+			got, err := c.AllSensors(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.AllSensors() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.AllSensors() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_CreateSensor(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"POST /api/user/sensors": `[{"success": {"id": "42"}}]`,
+	})
+
+	tests := []struct {
+		name     string
+		sensor   *hue.Sensor
+		want     int
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name: "generic flag",
+			sensor: &hue.Sensor{
+				Name:             "Away",
+				Type:             hue.CLIPGenericFlag,
+				ModelID:          "away",
+				ManufacturerName: "huego",
+				SWVersion:        "1.0",
+				UniqueID:         "away-1",
+				State:            &hue.FlagState{Flag: true},
+			},
+			want:     42,
+			wantBody: `{"name":"Away","type":"CLIPGenericFlag","modelid":"away","manufacturername":"huego","swversion":"1.0","uniqueid":"away-1","state":{"flag":true}}`,
+		},
+		{
+			name:    "physical sensor",
+			sensor:  &hue.Sensor{Name: "Hall", Type: hue.ZLLPresence, ModelID: "SML001", ManufacturerName: "Philips", SWVersion: "1", UniqueID: "x"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			got, err := c.CreateSensor(bridgeContext(srv), tt.sensor)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.CreateSensor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("client.CreateSensor() = %v, want %v", got, tt.want)
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.CreateSensor() sent %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_SetSensorConfig(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"GET /api/user/sensors/3": `{"name": "Hall", "type": "ZLLLightLevel", "state": {"lightlevel": 0},
+			"config": {"on": true, "battery": 90, "tholddark": 16000, "tholdoffset": 7000, "ledindication": false}}`,
+		"PUT /api/user/sensors/3/config": `[{"success": {"/sensors/3/config/tholddark": 12000}}]`,
+	})
+
+	dark := 12000
+	sensitivity := 1
+	battery := 50
+
+	tests := []struct {
+		name     string
+		update   *hue.SensorConfigUpdate
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "threshold",
+			update:   &hue.SensorConfigUpdate{TholdDark: &dark},
+			wantBody: `{"tholddark":12000}`,
+		},
+		{
+			name:    "sensitivity",
+			update:  &hue.SensorConfigUpdate{Sensitivity: &sensitivity},
+			wantErr: true,
+		},
+		{
+			name:    "battery",
+			update:  &hue.SensorConfigUpdate{Battery: &battery},
+			wantErr: true,
+		},
+		{
+			name:    "missing config",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			_, err := c.SetSensorConfig(bridgeContext(srv), 3, tt.update)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.SetSensorConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.SetSensorConfig() sent %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_SetSensorState(t *testing.T) {
+	srv, body := recordingBridge(t, map[string]string{
+		"GET /api/user/sensors/5":       `{"name": "Away", "type": "CLIPGenericFlag", "state": {"flag": false}, "config": {"on": true}}`,
+		"GET /api/user/sensors/6":       `{"name": "Hall", "type": "ZLLPresence", "state": {"presence": false}, "config": {"on": true}}`,
+		"PUT /api/user/sensors/5/state": `[{"success": {"/sensors/5/state/flag": true}}]`,
+	})
+
+	tests := []struct {
+		name     string
+		id       int
+		state    hue.SensorState
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "flag",
+			id:       5,
+			state:    &hue.FlagState{Flag: true},
+			wantBody: `{"flag":true}`,
+		},
+		{
+			name:    "state of another type",
+			id:      5,
+			state:   &hue.StatusState{Status: 1},
+			wantErr: true,
+		},
+		{
+			name:    "physical sensor",
+			id:      6,
+			state:   &hue.PresenceState{Presence: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*body = ""
+
+			c := &client{}
+			_, err := c.SetSensorState(bridgeContext(srv), tt.id, tt.state)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.SetSensorState() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if *body != tt.wantBody {
+				t.Errorf("client.SetSensorState() sent %s, want %s", *body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_client_PairSensors(t *testing.T) {
+	defer func(d time.Duration) { scanInterval = d }(scanInterval)
+	scanInterval = time.Millisecond
+
+	states := []string{
+		`{"lastscan": "none"}`,
+		`{"lastscan": "active"}`,
+		`{"lastscan": "2019-03-01T10:20:30", "5": {"name": "Hue motion sensor 1"}, "6": {"name": "Hue ambient light sensor 1"},
+			"7": {"name": "Hue temperature sensor 1"}, "8": {"name": "Hue dimmer switch 1"}}`,
+	}
+	sensors := map[string]string{
+		"5": `{"name": "Hue motion sensor 1", "type": "ZLLPresence", "modelid": "SML001", "uniqueid": "00:17:88:01:02:00:b5:d1-02-0406", "state": {"presence": false}}`,
+		"6": `{"name": "Hue ambient light sensor 1", "type": "ZLLLightLevel", "modelid": "SML001", "uniqueid": "00:17:88:01:02:00:b5:d1-02-0400", "state": {"lightlevel": 0}}`,
+		"7": `{"name": "Hue temperature sensor 1", "type": "ZLLTemperature", "modelid": "SML001", "uniqueid": "00:17:88:01:02:00:b5:d1-02-0402", "state": {"temperature": 0}}`,
+		"8": `{"name": "Hue dimmer switch 1", "type": "ZLLSwitch", "modelid": "RWL021", "uniqueid": "00:17:88:01:10:3e:3a:dc-02-fc00", "state": {"buttonevent": 0}}`,
+	}
+
+	var (
+		mu       sync.Mutex
+		searched bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch path := r.Method + " " + r.URL.Path; path {
+		case "POST /api/user/sensors":
+			searched = true
+			w.Write([]byte(`[{"success": {"/sensors": "Searching for new devices"}}]`))
+		case "GET /api/user/sensors/new":
+			w.Write([]byte(states[0]))
+			if len(states) > 1 {
+				states = states[1:]
+			}
+		default:
+			id := path[len("GET /api/user/sensors/"):]
+			if body, ok := sensors[id]; ok {
+				w.Write([]byte(body))
+				return
+			}
+
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &client{}
+	got, err := c.PairSensors(bridgeContext(srv))
+	if err != nil {
+		t.Fatalf("client.PairSensors() error = %v", err)
+	}
+
+	if !searched {
+		t.Errorf("client.PairSensors() didn't start a search")
+	}
+
+	var devices [][]int
+	for _, d := range got {
+		var ids []int
+		for _, s := range d.Sensors {
+			ids = append(ids, s.ID)
+		}
+		devices = append(devices, ids)
+	}
+
+	if want := [][]int{{5, 6, 7}, {8}}; !reflect.DeepEqual(devices, want) {
+		t.Errorf("client.PairSensors() devices = %v, want %v", devices, want)
+	}
+
+	if got[0].MAC != "00:17:88:01:02:00:b5:d1" || got[0].ModelID != "SML001" {
+		t.Errorf("client.PairSensors() device = %+v", got[0])
+	}
+}
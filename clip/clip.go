@@ -0,0 +1,175 @@
+// Package clip manages the virtual CLIP sensors an application uses to
+// feed the state of other systems into the bridge, so rules can react to
+// it. Sensors are owned by an application through their manufacturer
+// name and found again by their unique ID when the application restarts.
+package clip
+
+import (
+	"context"
+	"math"
+
+	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
+)
+
+// kinds are the CLIP sensor types managed by the package.
+var kinds = map[hue.SensorType]bool{
+	hue.CLIPGenericFlag:   true,
+	hue.CLIPGenericStatus: true,
+	hue.CLIPPresence:      true,
+	hue.CLIPTemperature:   true,
+	hue.CLIPHumidity:      true,
+	hue.CLIPOpenClose:     true,
+	hue.CLIPSwitch:        true,
+}
+
+// App is the application owning a set of sensors.
+type App struct {
+	// Manufacturer is written as the manufacturer name of the sensors,
+	// it tells the sensors of the application apart from any other.
+	Manufacturer string
+
+	// Version is written as the software version of the sensors.
+	Version string
+}
+
+// Sensor returns the sensor of the application with the unique ID, false
+// when there is none.
+func (a *App) Sensor(ctx context.Context, c hue.Client, uniqueID string) (*hue.Sensor, bool, error) {
+	sensors, err := a.Sensors(ctx, c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for i := range sensors {
+		if sensors[i].UniqueID == uniqueID {
+			return &sensors[i], true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// Sensors returns every CLIP sensor owned by the application.
+func (a *App) Sensors(ctx context.Context, c hue.Client) ([]hue.Sensor, error) {
+	all, err := c.AllSensors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sensors []hue.Sensor
+	for _, s := range all {
+		if s.Type.CLIP() && s.ManufacturerName == a.Manufacturer {
+			sensors = append(sensors, s)
+		}
+	}
+
+	return sensors, nil
+}
+
+// Ensure returns the sensor of the application with the unique ID,
+// creating it with the type and name when it doesn't exist yet. An
+// existing sensor of another type is reported as an error rather than
+// replaced.
+func (a *App) Ensure(ctx context.Context, c hue.Client, t hue.SensorType, uniqueID, name string) (*hue.Sensor, error) {
+	if !kinds[t] {
+		return nil, errors.Errorf("unsupported virtual sensor type '%s'", t)
+	}
+
+	if a.Manufacturer == "" {
+		return nil, errors.New("the application needs a manufacturer name to own sensors")
+	}
+
+	s, ok, err := a.Sensor(ctx, c, uniqueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		if s.Type != t {
+			return nil, errors.Errorf("sensor %d with unique ID '%s' is a %s, not a %s", s.ID, uniqueID, s.Type, t)
+		}
+
+		return s, nil
+	}
+
+	version := a.Version
+	if version == "" {
+		version = "1.0"
+	}
+
+	created := &hue.Sensor{
+		Name:             name,
+		Type:             t,
+		ModelID:          string(t),
+		ManufacturerName: a.Manufacturer,
+		SWVersion:        version,
+		UniqueID:         uniqueID,
+		State:            hue.NewSensorState(t),
+		Config:           hue.SensorConfig{On: true},
+	}
+
+	id, err := c.CreateSensor(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+
+	created.ID = id
+	return created, nil
+}
+
+// Remove deletes the sensor of the application with the unique ID, it is
+// a no-op when there is none.
+func (a *App) Remove(ctx context.Context, c hue.Client, uniqueID string) error {
+	s, ok, err := a.Sensor(ctx, c, uniqueID)
+	if err != nil || !ok {
+		return err
+	}
+
+	return c.DeleteSensor(ctx, s.ID)
+}
+
+// SetFlag sets the flag of a CLIPGenericFlag sensor.
+func SetFlag(ctx context.Context, c hue.Client, id int, flag bool) (*hue.Result, error) {
+	return c.SetSensorState(ctx, id, &hue.FlagState{Flag: flag})
+}
+
+// SetStatus sets the status of a CLIPGenericStatus sensor.
+func SetStatus(ctx context.Context, c hue.Client, id int, status int) (*hue.Result, error) {
+	return c.SetSensorState(ctx, id, &hue.StatusState{Status: status})
+}
+
+// SetPresence sets the presence of a CLIPPresence sensor.
+func SetPresence(ctx context.Context, c hue.Client, id int, presence bool) (*hue.Result, error) {
+	return c.SetSensorState(ctx, id, &hue.PresenceState{Presence: presence})
+}
+
+// SetTemperature sets the temperature of a CLIPTemperature sensor in
+// degrees Celsius.
+func SetTemperature(ctx context.Context, c hue.Client, id int, celsius float64) (*hue.Result, error) {
+	return c.SetSensorState(ctx, id, &hue.TemperatureState{Temperature: hundredths(celsius)})
+}
+
+// SetHumidity sets the relative humidity of a CLIPHumidity sensor in
+// percent.
+func SetHumidity(ctx context.Context, c hue.Client, id int, percent float64) (*hue.Result, error) {
+	if percent < 0 || percent > 100 {
+		return nil, errors.Errorf("humidity must be between 0 and 100 percent, got %v", percent)
+	}
+
+	return c.SetSensorState(ctx, id, &hue.HumidityState{Humidity: hundredths(percent)})
+}
+
+// SetOpen sets whether the contact of a CLIPOpenClose sensor is open.
+func SetOpen(ctx context.Context, c hue.Client, id int, open bool) (*hue.Result, error) {
+	return c.SetSensorState(ctx, id, &hue.OpenCloseState{Open: open})
+}
+
+// SetButtonEvent sets the button event of a CLIPSwitch sensor.
+func SetButtonEvent(ctx context.Context, c hue.Client, id int, event int) (*hue.Result, error) {
+	return c.SetSensorState(ctx, id, &hue.SwitchState{ButtonEvent: event})
+}
+
+func hundredths(v float64) int {
+	return int(math.Round(v * 100))
+}
@@ -0,0 +1,126 @@
+package clip
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+// bridge is a hue.Client holding sensors in memory, the methods that
+// aren't overridden panic.
+type bridge struct {
+	hue.Client
+
+	sensors []hue.Sensor
+	states  map[int]hue.SensorState
+	deleted []int
+}
+
+func (b *bridge) AllSensors(ctx context.Context) ([]hue.Sensor, error) {
+	return b.sensors, nil
+}
+
+func (b *bridge) CreateSensor(ctx context.Context, s *hue.Sensor) (int, error) {
+	if err := s.Validate(); err != nil {
+		return 0, err
+	}
+
+	s.ID = len(b.sensors) + 1
+	b.sensors = append(b.sensors, *s)
+	return s.ID, nil
+}
+
+func (b *bridge) SetSensorState(ctx context.Context, id int, state hue.SensorState) (*hue.Result, error) {
+	b.states[id] = state
+	return &hue.Result{}, nil
+}
+
+func (b *bridge) DeleteSensor(ctx context.Context, id int) error {
+	b.deleted = append(b.deleted, id)
+	return nil
+}
+
+func TestApp_Ensure(t *testing.T) {
+	ctx := context.Background()
+	b := &bridge{
+		sensors: []hue.Sensor{
+			{ID: 1, Type: hue.CLIPGenericFlag, ManufacturerName: "other", UniqueID: "away"},
+			{ID: 2, Type: hue.ZLLPresence, ManufacturerName: "alarm", UniqueID: "away"},
+		},
+	}
+	app := &App{Manufacturer: "alarm"}
+
+	s, err := app.Ensure(ctx, b, hue.CLIPGenericFlag, "away", "Away")
+	if err != nil {
+		t.Fatalf("App.Ensure() error = %v", err)
+	}
+
+	want := &hue.Sensor{
+		ID:               3,
+		Name:             "Away",
+		Type:             hue.CLIPGenericFlag,
+		ModelID:          "CLIPGenericFlag",
+		ManufacturerName: "alarm",
+		SWVersion:        "1.0",
+		UniqueID:         "away",
+		State:            &hue.FlagState{},
+		Config:           hue.SensorConfig{On: true},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("App.Ensure() = %+v, want %+v", s, want)
+	}
+
+	// found again rather than created twice
+	again, err := app.Ensure(ctx, b, hue.CLIPGenericFlag, "away", "Away")
+	if err != nil || again.ID != 3 || len(b.sensors) != 3 {
+		t.Errorf("App.Ensure() = %+v, %v, want sensor 3", again, err)
+	}
+
+	if _, err := app.Ensure(ctx, b, hue.CLIPPresence, "away", "Away"); err == nil {
+		t.Errorf("App.Ensure() of another type succeeded")
+	}
+
+	if _, err := app.Ensure(ctx, b, hue.CLIPLightLevel, "level", "Level"); err == nil {
+		t.Errorf("App.Ensure() of an unsupported type succeeded")
+	}
+
+	if err := app.Remove(ctx, b, "away"); err != nil || !reflect.DeepEqual(b.deleted, []int{3}) {
+		t.Errorf("App.Remove() = %v, deleted %v", err, b.deleted)
+	}
+
+	if err := app.Remove(ctx, b, "missing"); err != nil || len(b.deleted) != 1 {
+		t.Errorf("App.Remove() of a missing sensor = %v, deleted %v", err, b.deleted)
+	}
+}
+
+func TestSetState(t *testing.T) {
+	ctx := context.Background()
+	b := &bridge{states: map[int]hue.SensorState{}}
+
+	SetFlag(ctx, b, 1, true)
+	SetStatus(ctx, b, 2, 3)
+	SetPresence(ctx, b, 3, true)
+	SetTemperature(ctx, b, 4, 21.456)
+	SetHumidity(ctx, b, 5, 48.5)
+	SetOpen(ctx, b, 6, true)
+	SetButtonEvent(ctx, b, 7, 1002)
+
+	want := map[int]hue.SensorState{
+		1: &hue.FlagState{Flag: true},
+		2: &hue.StatusState{Status: 3},
+		3: &hue.PresenceState{Presence: true},
+		4: &hue.TemperatureState{Temperature: 2146},
+		5: &hue.HumidityState{Humidity: 4850},
+		6: &hue.OpenCloseState{Open: true},
+		7: &hue.SwitchState{ButtonEvent: 1002},
+	}
+	if !reflect.DeepEqual(b.states, want) {
+		t.Errorf("states = %+v, want %+v", b.states, want)
+	}
+
+	if _, err := SetHumidity(ctx, b, 5, 101); err == nil {
+		t.Errorf("SetHumidity() above 100 percent succeeded")
+	}
+}
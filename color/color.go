@@ -0,0 +1,329 @@
+// Package color converts between the color models people work in (sRGB,
+// HSV and Kelvin) and the CIE xy and mired values understood by Hue lights.
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Color is implemented by every color model that can be rendered by a
+// color light.
+type Color interface {
+	// XY returns the CIE 1931 chromaticity of the color.
+	XY() XY
+}
+
+// Brightness is implemented by color models that carry a brightness as
+// well as a chromaticity.
+type Brightness interface {
+	// Brightness returns the relative brightness from 0 to 1.
+	Brightness() float64
+}
+
+// WhitePoint is the chromaticity of the D65 white point.
+var WhitePoint = XY{X: 0.3127, Y: 0.3290}
+
+// XY is a point in the CIE 1931 color space.
+type XY struct {
+	X float64
+	Y float64
+}
+
+// XY implements Color.
+func (p XY) XY() XY {
+	return p
+}
+
+// RGB converts the chromaticity to sRGB at the relative brightness bri,
+// from 0 to 1. The result is scaled so the brightest channel is bri.
+func (p XY) RGB(bri float64) RGB {
+	if p.Y <= 0 || bri <= 0 {
+		return RGB{}
+	}
+
+	z := 1 - p.X - p.Y
+	y := 1.0
+	x := (y / p.Y) * p.X
+	zz := (y / p.Y) * z
+
+	r := x*1.656492 - y*0.354851 - zz*0.255038
+	g := -x*0.707196 + y*1.655397 + zz*0.036152
+	b := x*0.051713 - y*0.121364 + zz*1.011530
+
+	r, g, b = compand(r), compand(g), compand(b)
+	r, g, b = math.Max(r, 0), math.Max(g, 0), math.Max(b, 0)
+
+	peak := math.Max(r, math.Max(g, b))
+	if peak <= 0 {
+		return RGB{}
+	}
+
+	scale := math.Min(bri, 1) / peak
+	return RGB{
+		R: channel(r * scale),
+		G: channel(g * scale),
+		B: channel(b * scale),
+	}
+}
+
+// Mired approximates the correlated color temperature of the point
+// using McCamy's formula, it is only meaningful close to the Planckian
+// locus.
+func (p XY) Mired() Mired {
+	n := (p.X - 0.3320) / (0.1858 - p.Y)
+	cct := 449*n*n*n + 3525*n*n + 6823.3*n + 5520.33
+	if cct <= 0 {
+		return 0
+	}
+
+	return Mired(math.Round(1e6 / cct))
+}
+
+// RGB is a color in the sRGB color space.
+type RGB struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+// ParseHex parses a hex color in the #rrggbb, rrggbb or #rgb forms.
+func ParseHex(s string) (RGB, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	if len(hex) != 6 {
+		return RGB{}, errors.Errorf("invalid hex color '%s'", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return RGB{}, errors.Errorf("invalid hex color '%s'", s)
+	}
+
+	return RGB{
+		R: uint8(v >> 16),
+		G: uint8(v >> 8),
+		B: uint8(v),
+	}, nil
+}
+
+// Hex formats the color as #rrggbb.
+func (c RGB) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// XY implements Color using the wide gamut conversion recommended by
+// Philips for Hue lights.
+func (c RGB) XY() XY {
+	r := linear(float64(c.R) / 255)
+	g := linear(float64(c.G) / 255)
+	b := linear(float64(c.B) / 255)
+
+	x := r*0.664511 + g*0.154324 + b*0.162028
+	y := r*0.283881 + g*0.668433 + b*0.047685
+	z := r*0.000088 + g*0.072310 + b*0.986039
+
+	sum := x + y + z
+	if sum == 0 {
+		return WhitePoint
+	}
+
+	return XY{X: x / sum, Y: y / sum}
+}
+
+// Brightness implements Brightness, it is the value of the brightest
+// channel so fully saturated colors render at full brightness.
+func (c RGB) Brightness() float64 {
+	return c.HSV().V
+}
+
+// HSV converts the color to hue, saturation and value.
+func (c RGB) HSV() HSV {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	delta := hi - lo
+
+	hsv := HSV{V: hi}
+	if hi > 0 {
+		hsv.S = delta / hi
+	}
+
+	if delta == 0 {
+		return hsv
+	}
+
+	switch hi {
+	case r:
+		hsv.H = 60 * math.Mod((g-b)/delta, 6)
+	case g:
+		hsv.H = 60 * ((b-r)/delta + 2)
+	default:
+		hsv.H = 60 * ((r-g)/delta + 4)
+	}
+
+	if hsv.H < 0 {
+		hsv.H += 360
+	}
+
+	return hsv
+}
+
+// HSV is a color expressed as hue in degrees, saturation and value
+// from 0 to 1.
+type HSV struct {
+	H float64
+	S float64
+	V float64
+}
+
+// RGB converts the color to sRGB.
+func (c HSV) RGB() RGB {
+	h := math.Mod(c.H, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	chroma := c.V * c.S
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := c.V - chroma
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = chroma, x, 0
+	case h < 120:
+		r, g, b = x, chroma, 0
+	case h < 180:
+		r, g, b = 0, chroma, x
+	case h < 240:
+		r, g, b = 0, x, chroma
+	case h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return RGB{
+		R: channel(r + m),
+		G: channel(g + m),
+		B: channel(b + m),
+	}
+}
+
+// XY implements Color.
+func (c HSV) XY() XY {
+	return HSV{H: c.H, S: c.S, V: 1}.RGB().XY()
+}
+
+// Brightness implements Brightness.
+func (c HSV) Brightness() float64 {
+	return c.V
+}
+
+// HueSat returns the hue and saturation in the ranges used by the
+// bridge, 0 to 65535 and 0 to 254.
+func (c HSV) HueSat() (int, int) {
+	h := math.Mod(c.H, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	return int(math.Round(h / 360 * 65535)), int(math.Round(clamp(c.S) * 254))
+}
+
+// FromHueSat returns the color for a hue and saturation in the ranges
+// used by the bridge and a brightness from 1 to 254.
+func FromHueSat(hue, sat, bri int) HSV {
+	return HSV{
+		H: float64(hue) / 65535 * 360,
+		S: float64(sat) / 254,
+		V: float64(bri) / 254,
+	}
+}
+
+// Mired is a color temperature in reciprocal megakelvin, the unit of the
+// ct attribute.
+type Mired int
+
+// Kelvin converts a color temperature in Kelvin to mired.
+func Kelvin(k int) Mired {
+	if k <= 0 {
+		return 0
+	}
+
+	return Mired(math.Round(1e6 / float64(k)))
+}
+
+// Kelvin returns the color temperature in Kelvin.
+func (m Mired) Kelvin() int {
+	if m <= 0 {
+		return 0
+	}
+
+	return int(math.Round(1e6 / float64(m)))
+}
+
+// XY implements Color by approximating the point on the Planckian locus,
+// valid between 1667K and 25000K.
+func (m Mired) XY() XY {
+	t := float64(m.Kelvin())
+	t = math.Max(1667, math.Min(25000, t))
+
+	var x float64
+	if t <= 4000 {
+		x = -0.2661239e9/(t*t*t) - 0.2343589e6/(t*t) + 0.8776956e3/t + 0.179910
+	} else {
+		x = -3.0258469e9/(t*t*t) + 2.1070379e6/(t*t) + 0.2226347e3/t + 0.240390
+	}
+
+	var y float64
+	switch {
+	case t <= 2222:
+		y = -1.1063814*x*x*x - 1.34811020*x*x + 2.18555832*x - 0.20219683
+	case t <= 4000:
+		y = -0.9549476*x*x*x - 1.37418593*x*x + 2.09137015*x - 0.16748867
+	default:
+		y = 3.0817580*x*x*x - 5.87338670*x*x + 3.75112997*x - 0.37001483
+	}
+
+	return XY{X: x, Y: y}
+}
+
+// RGB approximates the color temperature in sRGB at full brightness.
+func (m Mired) RGB() RGB {
+	return m.XY().RGB(1)
+}
+
+// linear removes the sRGB gamma.
+func linear(v float64) float64 {
+	if v > 0.04045 {
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+
+	return v / 12.92
+}
+
+// compand applies the sRGB gamma.
+func compand(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func channel(v float64) uint8 {
+	return uint8(math.Round(clamp(v) * 255))
+}
@@ -0,0 +1,139 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestParseHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    RGB
+		wantErr bool
+	}{
+		{name: "long", s: "#ff8000", want: RGB{R: 255, G: 128}},
+		{name: "no prefix", s: "00ff00", want: RGB{G: 255}},
+		{name: "short", s: "#fff", want: RGB{R: 255, G: 255, B: 255}},
+		{name: "invalid length", s: "#ff80", wantErr: true},
+		{name: "invalid digits", s: "#gg0000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHex(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseHex() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseHex() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && tt.s[0] == '#' && len(tt.s) == 7 && got.Hex() != tt.s {
+				t.Errorf("RGB.Hex() = %s, want %s", got.Hex(), tt.s)
+			}
+		})
+	}
+}
+
+func TestRGB_XY(t *testing.T) {
+	tests := []struct {
+		name string
+		c    RGB
+		want XY
+	}{
+		{name: "red", c: RGB{R: 255}, want: XY{X: 0.7006, Y: 0.2993}},
+		{name: "green", c: RGB{G: 255}, want: XY{X: 0.1724, Y: 0.7468}},
+		{name: "blue", c: RGB{B: 255}, want: XY{X: 0.1355, Y: 0.0399}},
+		{name: "white", c: RGB{R: 255, G: 255, B: 255}, want: XY{X: 0.3227, Y: 0.3290}},
+		{name: "black", c: RGB{}, want: WhitePoint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.c.XY()
+			if !near(got.X, tt.want.X, 0.001) || !near(got.Y, tt.want.Y, 0.001) {
+				t.Errorf("RGB.XY() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXY_RGB(t *testing.T) {
+	for _, c := range []RGB{{R: 255}, {G: 255}, {B: 255}, {R: 255, G: 255, B: 255}, {R: 255, G: 128, B: 64}} {
+		got := c.XY().RGB(c.Brightness())
+		if !near(float64(got.R), float64(c.R), 4) || !near(float64(got.G), float64(c.G), 4) || !near(float64(got.B), float64(c.B), 4) {
+			t.Errorf("XY.RGB() round trip of %v = %v", c, got)
+		}
+	}
+}
+
+func TestHSV(t *testing.T) {
+	tests := []struct {
+		name string
+		c    RGB
+		want HSV
+	}{
+		{name: "red", c: RGB{R: 255}, want: HSV{H: 0, S: 1, V: 1}},
+		{name: "cyan", c: RGB{G: 255, B: 255}, want: HSV{H: 180, S: 1, V: 1}},
+		{name: "gray", c: RGB{R: 128, G: 128, B: 128}, want: HSV{H: 0, S: 0, V: 128.0 / 255}},
+		{name: "magenta", c: RGB{R: 255, B: 255}, want: HSV{H: 300, S: 1, V: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.c.HSV()
+			if !near(got.H, tt.want.H, 0.01) || !near(got.S, tt.want.S, 0.01) || !near(got.V, tt.want.V, 0.01) {
+				t.Errorf("RGB.HSV() = %v, want %v", got, tt.want)
+			}
+			if back := got.RGB(); back != tt.c {
+				t.Errorf("HSV.RGB() = %v, want %v", back, tt.c)
+			}
+		})
+	}
+}
+
+func TestHSV_HueSat(t *testing.T) {
+	hue, sat := HSV{H: 180, S: 0.5, V: 1}.HueSat()
+	if hue != 32768 || sat != 127 {
+		t.Errorf("HSV.HueSat() = %d, %d, want 32768, 127", hue, sat)
+	}
+
+	got := FromHueSat(hue, sat, 254)
+	if !near(got.H, 180, 0.01) || !near(got.S, 0.5, 0.01) || got.V != 1 {
+		t.Errorf("FromHueSat() = %v", got)
+	}
+}
+
+func TestMired(t *testing.T) {
+	tests := []struct {
+		name   string
+		kelvin int
+		want   Mired
+		xy     XY
+	}{
+		{name: "candle", kelvin: 2000, want: 500, xy: XY{X: 0.5267, Y: 0.4133}},
+		{name: "warm white", kelvin: 2700, want: 370, xy: XY{X: 0.4599, Y: 0.4106}},
+		{name: "daylight", kelvin: 6500, want: 154, xy: XY{X: 0.3135, Y: 0.3237}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Kelvin(tt.kelvin)
+			if got != tt.want {
+				t.Errorf("Kelvin() = %d, want %d", got, tt.want)
+			}
+			if !near(float64(got.Kelvin()), float64(tt.kelvin), 10) {
+				t.Errorf("Mired.Kelvin() = %d, want %d", got.Kelvin(), tt.kelvin)
+			}
+
+			xy := got.XY()
+			if !near(xy.X, tt.xy.X, 0.002) || !near(xy.Y, tt.xy.Y, 0.002) {
+				t.Errorf("Mired.XY() = %v, want %v", xy, tt.xy)
+			}
+			if m := xy.Mired(); !near(float64(m), float64(tt.want), 5) {
+				t.Errorf("XY.Mired() = %d, want %d", m, tt.want)
+			}
+		})
+	}
+}
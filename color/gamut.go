@@ -0,0 +1,136 @@
+package color
+
+import (
+	"math"
+	"strings"
+)
+
+// Gamut is the triangle of the CIE color space a light is able to render.
+type Gamut struct {
+	Red   XY
+	Green XY
+	Blue  XY
+}
+
+var (
+	// GamutA is rendered by the LivingColors and first Lightstrip lights.
+	GamutA = Gamut{
+		Red:   XY{X: 0.704, Y: 0.296},
+		Green: XY{X: 0.2151, Y: 0.7106},
+		Blue:  XY{X: 0.138, Y: 0.08},
+	}
+
+	// GamutB is rendered by the first generation Hue bulbs.
+	GamutB = Gamut{
+		Red:   XY{X: 0.675, Y: 0.322},
+		Green: XY{X: 0.409, Y: 0.518},
+		Blue:  XY{X: 0.167, Y: 0.04},
+	}
+
+	// GamutC is rendered by the current generation of Hue color lights.
+	GamutC = Gamut{
+		Red:   XY{X: 0.6915, Y: 0.3083},
+		Green: XY{X: 0.17, Y: 0.7},
+		Blue:  XY{X: 0.1532, Y: 0.0475},
+	}
+
+	models = map[string]Gamut{
+		"LLC001": GamutA, "LLC005": GamutA, "LLC006": GamutA,
+		"LLC007": GamutA, "LLC010": GamutA, "LLC011": GamutA,
+		"LLC012": GamutA, "LLC013": GamutA, "LLC014": GamutA,
+		"LST001": GamutA,
+
+		"LCT001": GamutB, "LCT002": GamutB, "LCT003": GamutB,
+		"LCT007": GamutB, "LLM001": GamutB,
+
+		"LCT010": GamutC, "LCT011": GamutC, "LCT012": GamutC,
+		"LCT014": GamutC, "LCT015": GamutC, "LCT016": GamutC,
+		"LCT024": GamutC, "LLC020": GamutC, "LST002": GamutC,
+		"LCA001": GamutC, "LCA002": GamutC, "LCA003": GamutC,
+		"LCG002": GamutC, "LCS001": GamutC, "LCF002": GamutC,
+	}
+)
+
+// GamutForType returns the gamut for a capabilities.control.colorgamuttype
+// value of A, B or C.
+func GamutForType(t string) (Gamut, bool) {
+	switch strings.ToUpper(t) {
+	case "A":
+		return GamutA, true
+	case "B":
+		return GamutB, true
+	case "C":
+		return GamutC, true
+	}
+
+	return Gamut{}, false
+}
+
+// GamutForModel returns the gamut of a light by its modelid, for lights
+// that don't report their capabilities.
+func GamutForModel(modelID string) (Gamut, bool) {
+	g, ok := models[strings.ToUpper(modelID)]
+	return g, ok
+}
+
+// GamutFromPoints builds a gamut from the red, green and blue corners
+// reported in capabilities.control.colorgamut.
+func GamutFromPoints(points [][2]float64) (Gamut, bool) {
+	if len(points) != 3 {
+		return Gamut{}, false
+	}
+
+	return Gamut{
+		Red:   XY{X: points[0][0], Y: points[0][1]},
+		Green: XY{X: points[1][0], Y: points[1][1]},
+		Blue:  XY{X: points[2][0], Y: points[2][1]},
+	}, true
+}
+
+// Contains reports whether p lies within the gamut.
+func (g Gamut) Contains(p XY) bool {
+	d1 := cross(p, g.Red, g.Green)
+	d2 := cross(p, g.Green, g.Blue)
+	d3 := cross(p, g.Blue, g.Red)
+
+	neg := d1 < 0 || d2 < 0 || d3 < 0
+	pos := d1 > 0 || d2 > 0 || d3 > 0
+
+	return !(neg && pos)
+}
+
+// Clamp returns p when it lies within the gamut and otherwise the closest
+// point on the edge of the gamut, which is what the light would render.
+func (g Gamut) Clamp(p XY) XY {
+	if g.Contains(p) {
+		return p
+	}
+
+	best := closest(p, g.Red, g.Green)
+	dist := distance(p, best)
+	for _, edge := range [][2]XY{{g.Green, g.Blue}, {g.Blue, g.Red}} {
+		c := closest(p, edge[0], edge[1])
+		if d := distance(p, c); d < dist {
+			best, dist = c, d
+		}
+	}
+
+	return best
+}
+
+func cross(p, a, b XY) float64 {
+	return (p.X-b.X)*(a.Y-b.Y) - (a.X-b.X)*(p.Y-b.Y)
+}
+
+// closest returns the point on the segment ab closest to p.
+func closest(p, a, b XY) XY {
+	abx, aby := b.X-a.X, b.Y-a.Y
+	t := ((p.X-a.X)*abx + (p.Y-a.Y)*aby) / (abx*abx + aby*aby)
+	t = math.Max(0, math.Min(1, t))
+
+	return XY{X: a.X + t*abx, Y: a.Y + t*aby}
+}
+
+func distance(a, b XY) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
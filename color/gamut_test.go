@@ -0,0 +1,49 @@
+package color
+
+import "testing"
+
+func TestGamut_Clamp(t *testing.T) {
+	tests := []struct {
+		name  string
+		gamut Gamut
+		p     XY
+		want  XY
+	}{
+		{name: "inside", gamut: GamutC, p: XY{X: 0.3, Y: 0.3}, want: XY{X: 0.3, Y: 0.3}},
+		{name: "corner", gamut: GamutB, p: XY{X: 0.675, Y: 0.322}, want: XY{X: 0.675, Y: 0.322}},
+		{name: "beyond red", gamut: GamutB, p: XY{X: 0.8, Y: 0.3}, want: GamutB.Red},
+		{name: "beyond green", gamut: GamutB, p: XY{X: 0.2, Y: 0.8}, want: GamutB.Green},
+		{name: "beyond blue edge", gamut: GamutA, p: XY{X: 0.4, Y: 0.1}, want: XY{X: 0.3733, Y: 0.1698}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.gamut.Clamp(tt.p)
+			if !near(got.X, tt.want.X, 0.001) || !near(got.Y, tt.want.Y, 0.001) {
+				t.Errorf("Gamut.Clamp() = %v, want %v", got, tt.want)
+			}
+			if !tt.gamut.Contains(XY{X: got.X - 1e-9*(got.X-0.3), Y: got.Y - 1e-9*(got.Y-0.3)}) {
+				t.Errorf("Gamut.Clamp() = %v is outside the gamut", got)
+			}
+		})
+	}
+}
+
+func TestGamutLookup(t *testing.T) {
+	if g, ok := GamutForModel("lct001"); !ok || g != GamutB {
+		t.Errorf("GamutForModel(lct001) = %v, %v, want GamutB", g, ok)
+	}
+	if _, ok := GamutForModel("LWB010"); ok {
+		t.Errorf("GamutForModel(LWB010) found a gamut for a white light")
+	}
+	if g, ok := GamutForType("C"); !ok || g != GamutC {
+		t.Errorf("GamutForType(C) = %v, %v, want GamutC", g, ok)
+	}
+
+	g, ok := GamutFromPoints([][2]float64{{0.675, 0.322}, {0.409, 0.518}, {0.167, 0.04}})
+	if !ok || g != GamutB {
+		t.Errorf("GamutFromPoints() = %v, %v, want GamutB", g, ok)
+	}
+	if _, ok := GamutFromPoints(nil); ok {
+		t.Errorf("GamutFromPoints(nil) returned a gamut")
+	}
+}
@@ -1,6 +1,11 @@
 package hue
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
 
 var (
 	// ErrNotImplemented is a placeholder errors message for missing
@@ -11,3 +16,196 @@ var (
 
 	ErrNoHost = errors.New("host parameter missing from context")
 )
+
+// ErrorType is the numeric type of an error reported by the bridge.
+type ErrorType int
+
+// Error types documented by the Hue API.
+const (
+	ErrorUnauthorizedUser        ErrorType = 1
+	ErrorInvalidJSON             ErrorType = 2
+	ErrorResourceNotAvailable    ErrorType = 3
+	ErrorMethodNotAvailable      ErrorType = 4
+	ErrorMissingParameters       ErrorType = 5
+	ErrorParameterNotAvailable   ErrorType = 6
+	ErrorInvalidValue            ErrorType = 7
+	ErrorParameterNotModifiable  ErrorType = 8
+	ErrorTooManyItems            ErrorType = 11
+	ErrorPortalRequired          ErrorType = 12
+	ErrorLinkButtonNotPressed    ErrorType = 101
+	ErrorDHCPNotDisabled         ErrorType = 110
+	ErrorInvalidUpdateState      ErrorType = 111
+	ErrorDeviceOff               ErrorType = 201
+	ErrorGroupTableFull          ErrorType = 301
+	ErrorGroupFull               ErrorType = 302
+	ErrorDeviceUnreachable       ErrorType = 304
+	ErrorGroupTypeNotModifiable  ErrorType = 305
+	ErrorLightInAnotherRoom      ErrorType = 306
+	ErrorSceneBufferFull         ErrorType = 402
+	ErrorSceneNotRemovable       ErrorType = 403
+	ErrorSensorListFull          ErrorType = 501
+	ErrorRuleEngineFull          ErrorType = 601
+	ErrorCondition               ErrorType = 607
+	ErrorAction                  ErrorType = 608
+	ErrorUnableToActivate        ErrorType = 609
+	ErrorScheduleListFull        ErrorType = 701
+	ErrorScheduleTimezoneInvalid ErrorType = 702
+	ErrorScheduleTimeConflict    ErrorType = 703
+	ErrorScheduleNotCreated      ErrorType = 704
+	ErrorScheduleInPast          ErrorType = 705
+	ErrorCommand                 ErrorType = 706
+	ErrorInternal                ErrorType = 901
+)
+
+var (
+	// ErrUnauthorized matches, with errors.Is, an API error caused by an
+	// unknown or missing username.
+	ErrUnauthorized = &APIError{Type: ErrorUnauthorizedUser}
+
+	// ErrResourceNotAvailable matches an API error for a resource that
+	// doesn't exist on the bridge.
+	ErrResourceNotAvailable = &APIError{Type: ErrorResourceNotAvailable}
+
+	// ErrLinkButtonNotPressed matches an API error for a user creation
+	// attempted without pressing the link button on the bridge.
+	ErrLinkButtonNotPressed = &APIError{Type: ErrorLinkButtonNotPressed}
+
+	// ErrDeviceOff matches an API error for a state attribute that can't
+	// be modified while the light is off.
+	ErrDeviceOff = &APIError{Type: ErrorDeviceOff}
+
+	// ErrGroupTableFull matches an API error for a group that couldn't be
+	// created because the bridge has reached its group limit.
+	ErrGroupTableFull = &APIError{Type: ErrorGroupTableFull}
+)
+
+// APIError is a single error reported by the bridge in a response body.
+type APIError struct {
+	Type        ErrorType `json:"type"`
+	Address     string    `json:"address"`
+	Description string    `json:"description"`
+}
+
+func (e *APIError) Error() string {
+	if e.Address == "" {
+		return fmt.Sprintf("hue error %d: %s", e.Type, e.Description)
+	}
+
+	return fmt.Sprintf("hue error %d at '%s': %s", e.Type, e.Address, e.Description)
+}
+
+// Is reports whether target is an *APIError of the same type, target
+// only has to match on the address when it has one.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+
+	return t.Type == e.Type && (t.Address == "" || t.Address == e.Address)
+}
+
+// APIErrors aggregates every error reported by the bridge in a single
+// response, errors.Is and errors.As look through each of them.
+type APIErrors []*APIError
+
+func (e APIErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("%d hue errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the individual errors.
+func (e APIErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// HTTPError is returned when the bridge responds with an unexpected
+// status code and a body that doesn't hold Hue errors.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// FieldError describes a single attribute that failed validation.
+type FieldError struct {
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+func (f FieldError) Error() string {
+	if f.Value == nil {
+		return fmt.Sprintf("%s %s", f.Field, f.Reason)
+	}
+
+	return fmt.Sprintf("%s %s, got %v", f.Field, f.Reason, f.Value)
+}
+
+// ValidationError is returned when a request is rejected client side,
+// before it is sent to the bridge.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (v *ValidationError) Error() string {
+	msgs := make([]string, 0, len(v.Fields))
+	for _, f := range v.Fields {
+		msgs = append(msgs, f.Error())
+	}
+
+	return fmt.Sprintf("validation failed: %s", strings.Join(msgs, "; "))
+}
+
+func (v *ValidationError) add(field string, value interface{}, reason string) {
+	v.Fields = append(v.Fields, FieldError{
+		Field:  field,
+		Value:  value,
+		Reason: reason,
+	})
+}
+
+func (v *ValidationError) check(val *int, field string, lo, hi int) {
+	if val == nil {
+		return
+	}
+
+	if *val < lo || *val > hi {
+		v.add(field, *val, fmt.Sprintf("must be between %d and %d", lo, hi))
+	}
+}
+
+func (v *ValidationError) conflict(conflicting bool, field string, reason string) {
+	if conflicting {
+		v.add(field, nil, reason)
+	}
+}
+
+// err returns v when any field failed validation and nil otherwise.
+func (v *ValidationError) err() error {
+	if len(v.Fields) == 0 {
+		return nil
+	}
+
+	return v
+}
@@ -0,0 +1,62 @@
+package hue
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	notFound := &APIError{
+		Type:        ErrorResourceNotAvailable,
+		Address:     "/lights/42",
+		Description: "resource, /lights/42, not available",
+	}
+	off := &APIError{
+		Type:        ErrorDeviceOff,
+		Address:     "/lights/1/state/bri",
+		Description: "parameter, bri, is not modifiable. Device is set to off.",
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{name: "same type", err: notFound, target: ErrResourceNotAvailable, want: true},
+		{name: "other type", err: notFound, target: ErrUnauthorized, want: false},
+		{name: "matching address", err: off, target: &APIError{Type: ErrorDeviceOff, Address: "/lights/1/state/bri"}, want: true},
+		{name: "other address", err: off, target: &APIError{Type: ErrorDeviceOff, Address: "/lights/2/state/bri"}, want: false},
+		{name: "aggregated", err: APIErrors{notFound, off}, target: ErrDeviceOff, want: true},
+		{name: "aggregated without match", err: APIErrors{notFound}, target: ErrGroupTableFull, want: false},
+		{name: "context error", err: ErrNoHost, target: ErrNoHost, want: true},
+		{name: "context error against api error", err: ErrNoUser, target: ErrUnauthorized, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrors_As(t *testing.T) {
+	var err error = APIErrors{
+		{Type: ErrorParameterNotAvailable, Address: "/lights/1/state/foo", Description: "parameter, foo, not available"},
+		{Type: ErrorLinkButtonNotPressed, Description: "link button not pressed"},
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("errors.As() failed to find an *APIError in %v", err)
+	}
+	if apiErr.Type != ErrorParameterNotAvailable {
+		t.Errorf("errors.As() = %v, want the first error", apiErr)
+	}
+
+	want := "2 hue errors: hue error 6 at '/lights/1/state/foo': parameter, foo, not available; hue error 101: link button not pressed"
+	if err.Error() != want {
+		t.Errorf("APIErrors.Error() = %s, want %s", err.Error(), want)
+	}
+}
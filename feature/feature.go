@@ -0,0 +1,367 @@
+// Package feature bundles the groups, scenes, sensors, schedules and
+// rules an application creates for one feature in a resourcelink, the
+// way the official apps do. Resources created through a feature have
+// their recycle flag set, so the bridge knows they only exist for it, and
+// the feature is listed and deleted as a unit through its resourcelink.
+package feature
+
+import (
+	"context"
+
+	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
+)
+
+// Feature is one feature of an application, such as a wake up routine,
+// and the resourcelink owning its resources.
+type Feature struct {
+	Link hue.ResourceLink
+}
+
+// Find returns the feature with the class and name, false when there is
+// none.
+func Find(ctx context.Context, c hue.Client, classID int, name string) (*Feature, bool, error) {
+	links, err := c.AllResourceLinks(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, l := range links {
+		if l.ClassID == classID && l.Name == name {
+			return &Feature{Link: l}, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// Ensure returns the feature with the class and name, creating its
+// resourcelink when it doesn't exist yet.
+func Ensure(ctx context.Context, c hue.Client, classID int, name, description string) (*Feature, error) {
+	f, ok, err := Find(ctx, c, classID, name)
+	if err != nil || ok {
+		return f, err
+	}
+
+	link := hue.ResourceLink{
+		Name:        name,
+		Description: description,
+		Type:        hue.ResourceLinkType,
+		ClassID:     classID,
+		Links:       []hue.Link{},
+	}
+
+	id, err := c.CreateResourceLink(ctx, &link)
+	if err != nil {
+		return nil, err
+	}
+
+	link.ID = id
+	return &Feature{Link: link}, nil
+}
+
+// Own adds resources to the feature, the ones it already owns are
+// skipped. Resources created elsewhere are listed with the feature but
+// only deleted with it when their recycle flag is set.
+func (f *Feature) Own(ctx context.Context, c hue.Client, links ...hue.Link) error {
+	update := f.Link
+	update.Links = append([]hue.Link{}, f.Link.Links...)
+	for _, l := range links {
+		if !update.Has(l) {
+			update.Links = append(update.Links, l)
+		}
+	}
+
+	if len(update.Links) == len(f.Link.Links) {
+		return nil
+	}
+
+	if _, err := c.UpdateResourceLink(ctx, f.Link.ID, &update); err != nil {
+		return err
+	}
+
+	f.Link.Links = update.Links
+	return nil
+}
+
+// CreateGroup creates the group with its recycle flag set and adds it to
+// the feature. A resource that was created but couldn't be added is
+// deleted by the bridge in time, since nothing links to it.
+func (f *Feature) CreateGroup(ctx context.Context, c hue.Client, g *hue.Group) (int, error) {
+	g.Recycle = true
+	id, err := c.CreateGroup(ctx, g)
+	if err != nil {
+		return 0, err
+	}
+
+	g.ID = id
+	return id, f.Own(ctx, c, hue.GroupLink(id))
+}
+
+// CreateScene creates the scene with its recycle flag set and adds it to
+// the feature.
+func (f *Feature) CreateScene(ctx context.Context, c hue.Client, s *hue.Scene) (string, error) {
+	s.Recycle = true
+	id, err := c.CreateScene(ctx, s)
+	if err != nil {
+		return "", err
+	}
+
+	s.ID = id
+	return id, f.Own(ctx, c, hue.SceneLink(id))
+}
+
+// CreateSensor creates the sensor with its recycle flag set and adds it
+// to the feature.
+func (f *Feature) CreateSensor(ctx context.Context, c hue.Client, s *hue.Sensor) (int, error) {
+	s.Recycle = true
+	id, err := c.CreateSensor(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+
+	s.ID = id
+	return id, f.Own(ctx, c, hue.SensorLink(id))
+}
+
+// CreateSchedule creates the schedule with its recycle flag set and adds
+// it to the feature.
+func (f *Feature) CreateSchedule(ctx context.Context, c hue.Client, s *hue.Schedule) (int, error) {
+	s.Recycle = true
+	id, err := c.CreateSchedule(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+
+	s.ID = id
+	return id, f.Own(ctx, c, hue.ScheduleLink(id))
+}
+
+// CreateRule creates the rule with its recycle flag set and adds it to
+// the feature.
+func (f *Feature) CreateRule(ctx context.Context, c hue.Client, r *hue.Rule) (int, error) {
+	r.Recycle = true
+	id, err := c.CreateRule(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+
+	r.ID = id
+	return id, f.Own(ctx, c, hue.RuleLink(id))
+}
+
+// Resources returns the resources the feature links to that still exist
+// on the bridge, only the kinds of resource it links to are fetched. The
+// resourcelinks it links to are listed in its links.
+func (f *Feature) Resources(ctx context.Context, c hue.Client) (*hue.Resources, error) {
+	kinds := map[hue.LinkKind]bool{}
+	for _, l := range f.Link.Links {
+		kinds[l.Kind] = true
+	}
+
+	return f.fetch(ctx, c, kinds)
+}
+
+// fetch returns the resources of the kinds the feature links to.
+func (f *Feature) fetch(ctx context.Context, c hue.Client, kinds map[hue.LinkKind]bool) (*hue.Resources, error) {
+	var res hue.Resources
+	if kinds[hue.LinkLights] {
+		all, err := c.AllLights(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range all {
+			if f.Link.Has(hue.LightLink(l.ID)) {
+				res.Lights = append(res.Lights, l)
+			}
+		}
+	}
+
+	if kinds[hue.LinkGroups] {
+		all, err := c.AllGroups(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range all {
+			if f.Link.Has(hue.GroupLink(g.ID)) {
+				res.Groups = append(res.Groups, g)
+			}
+		}
+	}
+
+	if kinds[hue.LinkSensors] {
+		all, err := c.AllSensors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range all {
+			if f.Link.Has(hue.SensorLink(s.ID)) {
+				res.Sensors = append(res.Sensors, s)
+			}
+		}
+	}
+
+	if kinds[hue.LinkScenes] {
+		all, err := c.AllScenes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range all {
+			if f.Link.Has(hue.SceneLink(s.ID)) {
+				res.Scenes = append(res.Scenes, s)
+			}
+		}
+	}
+
+	if kinds[hue.LinkSchedules] {
+		all, err := c.AllSchedules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range all {
+			if f.Link.Has(hue.ScheduleLink(s.ID)) {
+				res.Schedules = append(res.Schedules, s)
+			}
+		}
+	}
+
+	if kinds[hue.LinkRules] {
+		all, err := c.AllRules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range all {
+			if f.Link.Has(hue.RuleLink(r.ID)) {
+				res.Rules = append(res.Rules, r)
+			}
+		}
+	}
+
+	return &res, nil
+}
+
+// Delete removes the feature: the resources it owns with their recycle
+// flag set, unless another resourcelink links to them too, and then its
+// resourcelink. Rules go first since they refer to the other resources,
+// lights are never deleted. Resources the bridge already deleted are
+// skipped.
+func (f *Feature) Delete(ctx context.Context, c hue.Client) error {
+	kinds := map[hue.LinkKind]bool{}
+	for _, l := range f.Link.Links {
+		if l.Kind != hue.LinkLights {
+			kinds[l.Kind] = true
+		}
+	}
+
+	res, err := f.fetch(ctx, c, kinds)
+	if err != nil {
+		return err
+	}
+
+	all, err := c.AllResourceLinks(ctx)
+	if err != nil {
+		return err
+	}
+
+	shared := map[hue.Link]bool{}
+	for _, l := range all {
+		if l.ID == f.Link.ID {
+			continue
+		}
+		for _, link := range l.Links {
+			shared[link] = true
+		}
+	}
+
+	var owned []hue.Link
+	for _, r := range res.Rules {
+		if r.Recycle {
+			owned = append(owned, hue.RuleLink(r.ID))
+		}
+	}
+	for _, s := range res.Schedules {
+		if s.Recycle {
+			owned = append(owned, hue.ScheduleLink(s.ID))
+		}
+	}
+	for _, s := range res.Scenes {
+		if s.Recycle {
+			owned = append(owned, hue.SceneLink(s.ID))
+		}
+	}
+	for _, g := range res.Groups {
+		if g.Recycle {
+			owned = append(owned, hue.GroupLink(g.ID))
+		}
+	}
+	for _, s := range res.Sensors {
+		if s.Recycle {
+			owned = append(owned, hue.SensorLink(s.ID))
+		}
+	}
+	for _, l := range all {
+		if l.Recycle && f.Link.Has(hue.ResourceLinkLink(l.ID)) {
+			owned = append(owned, hue.ResourceLinkLink(l.ID))
+		}
+	}
+
+	for _, l := range owned {
+		if shared[l] {
+			continue
+		}
+
+		if err := remove(ctx, c, l); err != nil && !notFound(err) {
+			return errors.Wrapf(err, "failed to delete %s of feature '%s'", l, f.Link.Name)
+		}
+	}
+
+	if err := c.DeleteResourceLink(ctx, f.Link.ID); err != nil && !notFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// remove deletes the linked resource.
+func remove(ctx context.Context, c hue.Client, l hue.Link) error {
+	if l.Kind == hue.LinkScenes {
+		return c.DeleteScene(ctx, l.ID)
+	}
+
+	id, ok := l.Int()
+	if !ok {
+		return errors.Errorf("invalid link '%s'", l)
+	}
+
+	switch l.Kind {
+	case hue.LinkGroups:
+		return c.DeleteGroup(ctx, id)
+	case hue.LinkSensors:
+		return c.DeleteSensor(ctx, id)
+	case hue.LinkSchedules:
+		return c.DeleteSchedule(ctx, id)
+	case hue.LinkRules:
+		return c.DeleteRule(ctx, id)
+	case hue.LinkResourceLinks:
+		return c.DeleteResourceLink(ctx, id)
+	}
+
+	return errors.Errorf("%s can't be deleted with a feature", l)
+}
+
+// notFound reports whether the bridge rejected a request because the
+// resource doesn't exist.
+func notFound(err error) bool {
+	errs, ok := errors.Cause(err).(hue.APIErrors)
+	if !ok || len(errs) == 0 {
+		return false
+	}
+
+	for _, e := range errs {
+		if !e.Is(hue.ErrResourceNotAvailable) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,192 @@
+package feature
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+// bridge is a hue.Client holding resourcelinks, rules, scenes and sensors
+// in memory, the methods that aren't overridden panic.
+type bridge struct {
+	hue.Client
+
+	links   []hue.ResourceLink
+	rules   []hue.Rule
+	scenes  []hue.Scene
+	sensors []hue.Sensor
+	deleted []string
+}
+
+func (b *bridge) AllResourceLinks(ctx context.Context) ([]hue.ResourceLink, error) {
+	return b.links, nil
+}
+
+func (b *bridge) CreateResourceLink(ctx context.Context, l *hue.ResourceLink) (int, error) {
+	if err := l.Validate(); err != nil {
+		return 0, err
+	}
+
+	l.ID = 100 + len(b.links)
+	b.links = append(b.links, *l)
+	return l.ID, nil
+}
+
+func (b *bridge) UpdateResourceLink(ctx context.Context, id int, l *hue.ResourceLink) (*hue.Result, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	for i := range b.links {
+		if b.links[i].ID == id {
+			b.links[i].Links = l.Links
+		}
+	}
+	return &hue.Result{}, nil
+}
+
+func (b *bridge) DeleteResourceLink(ctx context.Context, id int) error {
+	return b.remove(hue.ResourceLinkLink(id))
+}
+
+func (b *bridge) AllRules(ctx context.Context) ([]hue.Rule, error) {
+	return b.rules, nil
+}
+
+func (b *bridge) CreateRule(ctx context.Context, r *hue.Rule) (int, error) {
+	r.ID = len(b.rules) + 1
+	b.rules = append(b.rules, *r)
+	return r.ID, nil
+}
+
+func (b *bridge) DeleteRule(ctx context.Context, id int) error {
+	return b.remove(hue.RuleLink(id))
+}
+
+func (b *bridge) AllScenes(ctx context.Context) ([]hue.Scene, error) {
+	return b.scenes, nil
+}
+
+func (b *bridge) DeleteScene(ctx context.Context, id string) error {
+	return b.remove(hue.SceneLink(id))
+}
+
+func (b *bridge) AllSensors(ctx context.Context) ([]hue.Sensor, error) {
+	return b.sensors, nil
+}
+
+func (b *bridge) CreateSensor(ctx context.Context, s *hue.Sensor) (int, error) {
+	s.ID = len(b.sensors) + 1
+	b.sensors = append(b.sensors, *s)
+	return s.ID, nil
+}
+
+func (b *bridge) DeleteSensor(ctx context.Context, id int) error {
+	return b.remove(hue.SensorLink(id))
+}
+
+// remove records the deletion, deleting a resource twice fails as it
+// would on a bridge.
+func (b *bridge) remove(l hue.Link) error {
+	for _, d := range b.deleted {
+		if d == l.String() {
+			return hue.APIErrors{{Type: hue.ErrorResourceNotAvailable, Address: l.String()}}
+		}
+	}
+
+	b.deleted = append(b.deleted, l.String())
+	return nil
+}
+
+func TestEnsure(t *testing.T) {
+	ctx := context.Background()
+	b := &bridge{
+		links: []hue.ResourceLink{{ID: 1, Name: "Wake up", ClassID: 2, Links: []hue.Link{}}},
+	}
+
+	f, err := Ensure(ctx, b, 1, "Wake up", "Morning routine")
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if f.Link.ID != 101 || f.Link.Type != hue.ResourceLinkType || len(b.links) != 2 {
+		t.Errorf("Ensure() = %+v, want resourcelink 101 created", f.Link)
+	}
+
+	// found again rather than created twice
+	again, err := Ensure(ctx, b, 1, "Wake up", "Morning routine")
+	if err != nil || again.Link.ID != 101 || len(b.links) != 2 {
+		t.Errorf("Ensure() = %+v, %v, want resourcelink 101", again, err)
+	}
+}
+
+func TestFeature_Create(t *testing.T) {
+	ctx := context.Background()
+	b := &bridge{}
+
+	f, err := Ensure(ctx, b, 1, "Wake up", "")
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+
+	if _, err := f.CreateSensor(ctx, b, &hue.Sensor{Name: "Wake up", Type: hue.CLIPGenericFlag}); err != nil {
+		t.Fatalf("Feature.CreateSensor() error = %v", err)
+	}
+	if _, err := f.CreateRule(ctx, b, &hue.Rule{Name: "Wake up"}); err != nil {
+		t.Fatalf("Feature.CreateRule() error = %v", err)
+	}
+	if err := f.Own(ctx, b, hue.LightLink(1), hue.SensorLink(1)); err != nil {
+		t.Fatalf("Feature.Own() error = %v", err)
+	}
+
+	want := []hue.Link{hue.SensorLink(1), hue.RuleLink(1), hue.LightLink(1)}
+	if !reflect.DeepEqual(f.Link.Links, want) || !reflect.DeepEqual(b.links[0].Links, want) {
+		t.Errorf("Feature links = %v, bridge %v, want %v", f.Link.Links, b.links[0].Links, want)
+	}
+
+	if !b.sensors[0].Recycle || !b.rules[0].Recycle {
+		t.Errorf("Feature created %+v and %+v, want them recycled", b.sensors[0], b.rules[0])
+	}
+}
+
+func TestFeature_Delete(t *testing.T) {
+	ctx := context.Background()
+	b := &bridge{
+		links: []hue.ResourceLink{
+			{ID: 1, Name: "Wake up", ClassID: 1, Links: []hue.Link{
+				hue.LightLink(1), hue.SensorLink(3), hue.SceneLink("AB34EF5"), hue.SceneLink("CD56AB7"),
+				hue.RuleLink(1), hue.RuleLink(2), hue.ResourceLinkLink(2),
+			}},
+			{ID: 2, Name: "Wake up lights", ClassID: 1, Recycle: true, Links: []hue.Link{hue.SceneLink("CD56AB7")}},
+			{ID: 3, Name: "Go to sleep", ClassID: 1, Links: []hue.Link{hue.RuleLink(2)}},
+		},
+		rules: []hue.Rule{
+			{ID: 1, Name: "Wake up", Recycle: true},
+			{ID: 2, Name: "Shared", Recycle: true},
+		},
+		scenes: []hue.Scene{
+			{ID: "AB34EF5", Name: "Mine", Recycle: true},
+			{ID: "CD56AB7", Name: "Nested", Recycle: true},
+			{ID: "EF78CD9", Name: "Not linked", Recycle: true},
+		},
+		sensors: []hue.Sensor{
+			{ID: 3, Name: "Already gone", Recycle: true},
+		},
+	}
+	b.deleted = []string{"/sensors/3"}
+
+	f, ok, err := Find(ctx, b, 1, "Wake up")
+	if err != nil || !ok {
+		t.Fatalf("Find() = %v, %v", ok, err)
+	}
+
+	if err := f.Delete(ctx, b); err != nil {
+		t.Fatalf("Feature.Delete() error = %v", err)
+	}
+
+	want := []string{"/sensors/3", "/rules/1", "/scenes/AB34EF5", "/resourcelinks/2", "/resourcelinks/1"}
+	if !reflect.DeepEqual(b.deleted, want) {
+		t.Errorf("Feature.Delete() deleted %v, want %v", b.deleted, want)
+	}
+}
@@ -0,0 +1,168 @@
+package hue
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ninnemana/huego/timepattern"
+	"github.com/pkg/errors"
+)
+
+// FullState is every resource on the bridge along with its configuration,
+// as read in a single request. A snapshot saved as JSON is read back with
+// json.Unmarshal.
+// GET /api/<username>
+type FullState struct {
+	Resources
+
+	// Config is the configuration of the bridge as it was reported.
+	Config json.RawMessage
+}
+
+// fullState is the encoding of FullState, resources are keyed by their
+// identifier.
+type fullState struct {
+	Lights    map[string]Light    `json:"lights"`
+	Groups    map[string]Group    `json:"groups"`
+	Sensors   map[string]Sensor   `json:"sensors"`
+	Scenes    map[string]Scene    `json:"scenes"`
+	Schedules map[string]Schedule `json:"schedules"`
+	Rules     map[string]Rule     `json:"rules"`
+	Config    json.RawMessage     `json:"config,omitempty"`
+}
+
+// LocalTime returns the local time of the bridge when the state was read,
+// in the bridge's timezone.
+func (s *FullState) LocalTime() (time.Time, error) {
+	var config struct {
+		LocalTime string `json:"localtime"`
+		TimeZone  string `json:"timezone"`
+	}
+	if len(s.Config) == 0 {
+		return time.Time{}, errors.New("the state has no config")
+	}
+	if err := json.Unmarshal(s.Config, &config); err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := timepattern.LoadLocation(config.TimeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return timepattern.ParseLocal(config.LocalTime, loc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *FullState) UnmarshalJSON(data []byte) error {
+	var raw fullState
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = FullState{Config: raw.Config}
+
+	for key, l := range raw.Lights {
+		id, err := stateID("light", key)
+		if err != nil {
+			return err
+		}
+		l.ID = id
+		s.Lights = append(s.Lights, l)
+	}
+
+	for key, g := range raw.Groups {
+		id, err := stateID("group", key)
+		if err != nil {
+			return err
+		}
+		g.ID = id
+		s.Groups = append(s.Groups, g)
+	}
+
+	for key, sensor := range raw.Sensors {
+		id, err := stateID("sensor", key)
+		if err != nil {
+			return err
+		}
+		sensor.ID = id
+		s.Sensors = append(s.Sensors, sensor)
+	}
+
+	for key, scene := range raw.Scenes {
+		scene.ID = key
+		s.Scenes = append(s.Scenes, scene)
+	}
+
+	for key, schedule := range raw.Schedules {
+		id, err := stateID("schedule", key)
+		if err != nil {
+			return err
+		}
+		schedule.ID = id
+		s.Schedules = append(s.Schedules, schedule)
+	}
+
+	for key, r := range raw.Rules {
+		id, err := stateID("rule", key)
+		if err != nil {
+			return err
+		}
+		r.ID = id
+		s.Rules = append(s.Rules, r)
+	}
+
+	sort.Slice(s.Lights, func(i, j int) bool { return s.Lights[i].ID < s.Lights[j].ID })
+	sort.Slice(s.Groups, func(i, j int) bool { return s.Groups[i].ID < s.Groups[j].ID })
+	sort.Slice(s.Sensors, func(i, j int) bool { return s.Sensors[i].ID < s.Sensors[j].ID })
+	sort.Slice(s.Scenes, func(i, j int) bool { return s.Scenes[i].ID < s.Scenes[j].ID })
+	sort.Slice(s.Schedules, func(i, j int) bool { return s.Schedules[i].ID < s.Schedules[j].ID })
+	sort.Slice(s.Rules, func(i, j int) bool { return s.Rules[i].ID < s.Rules[j].ID })
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s FullState) MarshalJSON() ([]byte, error) {
+	raw := fullState{
+		Lights:    make(map[string]Light, len(s.Lights)),
+		Groups:    make(map[string]Group, len(s.Groups)),
+		Sensors:   make(map[string]Sensor, len(s.Sensors)),
+		Scenes:    make(map[string]Scene, len(s.Scenes)),
+		Schedules: make(map[string]Schedule, len(s.Schedules)),
+		Rules:     make(map[string]Rule, len(s.Rules)),
+		Config:    s.Config,
+	}
+
+	for _, l := range s.Lights {
+		raw.Lights[strconv.Itoa(l.ID)] = l
+	}
+	for _, g := range s.Groups {
+		raw.Groups[strconv.Itoa(g.ID)] = g
+	}
+	for _, sensor := range s.Sensors {
+		raw.Sensors[strconv.Itoa(sensor.ID)] = sensor
+	}
+	for _, scene := range s.Scenes {
+		raw.Scenes[scene.ID] = scene
+	}
+	for _, schedule := range s.Schedules {
+		raw.Schedules[strconv.Itoa(schedule.ID)] = schedule
+	}
+	for _, r := range s.Rules {
+		raw.Rules[strconv.Itoa(r.ID)] = r
+	}
+
+	return json.Marshal(raw)
+}
+
+func stateID(kind, key string) (int, error) {
+	id, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, errors.Errorf("failed to parse %s key into identifier '%s'", kind, key)
+	}
+
+	return id, nil
+}
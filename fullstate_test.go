@@ -0,0 +1,56 @@
+package hue
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFullState_JSON(t *testing.T) {
+	data := `{
+		"lights": {"10": {"name": "Lamp", "state": {"on": true}}, "2": {"name": "Ceiling", "state": {"on": false}}},
+		"groups": {"1": {"name": "Hall", "type": "Room", "lights": ["2", "10"]}},
+		"sensors": {"5": {"name": "Hall motion", "type": "ZLLPresence", "state": {"presence": true, "lastupdated": "2020-03-27T11:59:00"}}},
+		"scenes": {"CD56AB7": {"name": "Bright", "lights": ["2"]}, "AB34EF5": {"name": "Night", "lights": ["10"]}},
+		"schedules": {},
+		"rules": {"3": {"name": "Motion", "conditions": [{"address": "/sensors/5/state/presence", "operator": "dx"}], "actions": []}},
+		"config": {"timezone": "none", "localtime": "2020-03-27T12:00:00"}
+	}`
+
+	var s FullState
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	var ids []interface{}
+	for _, l := range s.Lights {
+		ids = append(ids, l.ID)
+	}
+	for _, sc := range s.Scenes {
+		ids = append(ids, sc.ID)
+	}
+	if want := []interface{}{2, 10, "AB34EF5", "CD56AB7"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("json.Unmarshal() ids = %v, want %v", ids, want)
+	}
+
+	out, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var again FullState
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatalf("json.Unmarshal() of %s error = %v", out, err)
+	}
+	if len(again.Lights) != 2 || len(again.Groups) != 1 || len(again.Sensors) != 1 || len(again.Scenes) != 2 || len(again.Rules) != 1 {
+		t.Errorf("json.Unmarshal(json.Marshal()) = %+v, want %+v", again, s)
+	}
+
+	if out2, _ := json.Marshal(again); string(out2) != string(out) {
+		t.Errorf("json.Marshal() = %s, want %s", out2, out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"lights": {"one": {}}}`), &s); err == nil {
+		t.Error("json.Unmarshal() expected an error for a light key that isn't an identifier")
+	}
+}
@@ -0,0 +1,207 @@
+package hue
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GroupType is the kind of a group, which decides how lights can be
+// assigned to it.
+type GroupType string
+
+// Group types that can be created through the API.
+const (
+	// GroupLightGroup is a plain set of lights, a light can be in any
+	// number of them.
+	GroupLightGroup GroupType = "LightGroup"
+
+	// GroupRoom is a physical room, a light can only be in one of them.
+	GroupRoom GroupType = "Room"
+
+	// GroupZone is a set of lights that can span rooms.
+	GroupZone GroupType = "Zone"
+
+	// GroupEntertainment is a set of lights used for streaming.
+	GroupEntertainment GroupType = "Entertainment"
+)
+
+// AllLightsGroup is the identifier of the special group that always
+// contains every light on the bridge, it can't be modified or deleted.
+const AllLightsGroup = 0
+
+// RoomClass is the category of a room, zone or entertainment area.
+type RoomClass string
+
+// Room classes documented by the Hue API.
+const (
+	ClassLivingRoom  RoomClass = "Living room"
+	ClassKitchen     RoomClass = "Kitchen"
+	ClassDining      RoomClass = "Dining"
+	ClassBedroom     RoomClass = "Bedroom"
+	ClassKidsBedroom RoomClass = "Kids bedroom"
+	ClassBathroom    RoomClass = "Bathroom"
+	ClassNursery     RoomClass = "Nursery"
+	ClassRecreation  RoomClass = "Recreation"
+	ClassOffice      RoomClass = "Office"
+	ClassGym         RoomClass = "Gym"
+	ClassHallway     RoomClass = "Hallway"
+	ClassToilet      RoomClass = "Toilet"
+	ClassFrontDoor   RoomClass = "Front door"
+	ClassGarage      RoomClass = "Garage"
+	ClassTerrace     RoomClass = "Terrace"
+	ClassGarden      RoomClass = "Garden"
+	ClassDriveway    RoomClass = "Driveway"
+	ClassCarport     RoomClass = "Carport"
+	ClassOther       RoomClass = "Other"
+	ClassHome        RoomClass = "Home"
+	ClassDownstairs  RoomClass = "Downstairs"
+	ClassUpstairs    RoomClass = "Upstairs"
+	ClassTopFloor    RoomClass = "Top floor"
+	ClassAttic       RoomClass = "Attic"
+	ClassGuestRoom   RoomClass = "Guest room"
+	ClassStaircase   RoomClass = "Staircase"
+	ClassLounge      RoomClass = "Lounge"
+	ClassManCave     RoomClass = "Man cave"
+	ClassComputer    RoomClass = "Computer"
+	ClassStudio      RoomClass = "Studio"
+	ClassMusic       RoomClass = "Music"
+	ClassTV          RoomClass = "TV"
+	ClassReading     RoomClass = "Reading"
+	ClassCloset      RoomClass = "Closet"
+	ClassStorage     RoomClass = "Storage"
+	ClassLaundryRoom RoomClass = "Laundry room"
+	ClassBalcony     RoomClass = "Balcony"
+	ClassPorch       RoomClass = "Porch"
+	ClassBarbecue    RoomClass = "Barbecue"
+	ClassPool        RoomClass = "Pool"
+	ClassFree        RoomClass = "Free"
+)
+
+var roomClasses = map[RoomClass]bool{
+	ClassLivingRoom: true, ClassKitchen: true, ClassDining: true, ClassBedroom: true,
+	ClassKidsBedroom: true, ClassBathroom: true, ClassNursery: true, ClassRecreation: true,
+	ClassOffice: true, ClassGym: true, ClassHallway: true, ClassToilet: true,
+	ClassFrontDoor: true, ClassGarage: true, ClassTerrace: true, ClassGarden: true,
+	ClassDriveway: true, ClassCarport: true, ClassOther: true, ClassHome: true,
+	ClassDownstairs: true, ClassUpstairs: true, ClassTopFloor: true, ClassAttic: true,
+	ClassGuestRoom: true, ClassStaircase: true, ClassLounge: true, ClassManCave: true,
+	ClassComputer: true, ClassStudio: true, ClassMusic: true, ClassTV: true,
+	ClassReading: true, ClassCloset: true, ClassStorage: true, ClassLaundryRoom: true,
+	ClassBalcony: true, ClassPorch: true, ClassBarbecue: true, ClassPool: true,
+	ClassFree: true,
+}
+
+// Valid reports whether c is a room class the bridge accepts.
+func (c RoomClass) Valid() bool {
+	return roomClasses[c]
+}
+
+// Group is a set of lights that can be controlled together.
+type Group struct {
+	// ID is the identifier of the group on the bridge, it isn't part of
+	// the group's attributes.
+	ID int `json:"-"`
+
+	Name    string     `json:"name"`
+	Type    GroupType  `json:"type"`
+	Class   RoomClass  `json:"class,omitempty"`
+	Lights  []string   `json:"lights"`
+	Sensors []string   `json:"sensors"`
+	Action  LightState `json:"action"`
+	State   GroupState `json:"state"`
+	Recycle bool       `json:"recycle"`
+
+	// Extra holds any attribute the bridge reported that isn't modelled
+	// above, it is written back out when the group is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// GroupState summarises the on state of the lights in a group.
+type GroupState struct {
+	AnyOn bool `json:"any_on"`
+	AllOn bool `json:"all_on"`
+}
+
+// Validate checks the attributes of the group that can be written to
+// the bridge.
+func (g *Group) Validate() error {
+	v := &ValidationError{}
+
+	if err := ValidateName(g.Name); err != nil {
+		v.Fields = append(v.Fields, err.(*ValidationError).Fields...)
+	}
+
+	switch g.Type {
+	case GroupLightGroup, GroupRoom, GroupZone, GroupEntertainment:
+	case "":
+	default:
+		v.add("type", g.Type, "must be one of LightGroup, Room, Zone or Entertainment")
+	}
+
+	if g.Class != "" {
+		if !g.Class.Valid() {
+			v.add("class", g.Class, "isn't a known room class")
+		}
+
+		v.conflict(g.Type == GroupLightGroup, "class", "can only be set on rooms, zones and entertainment areas")
+	}
+
+	seen := make(map[string]bool, len(g.Lights))
+	for _, l := range g.Lights {
+		if seen[l] {
+			v.add("lights", l, "is listed more than once")
+		}
+		seen[l] = true
+	}
+
+	v.conflict(g.Type == GroupEntertainment && len(g.Lights) == 0, "lights", "entertainment areas need at least one light")
+
+	return v.err()
+}
+
+// CheckRooms verifies g doesn't claim a light that is already assigned
+// to another room in groups, since a light can only be in one room. Groups
+// other than rooms can share lights freely.
+func (g *Group) CheckRooms(groups []Group) error {
+	if g.Type != GroupRoom {
+		return nil
+	}
+
+	rooms := make(map[string]string)
+	for _, other := range groups {
+		if other.Type != GroupRoom || other.ID == g.ID {
+			continue
+		}
+
+		for _, l := range other.Lights {
+			rooms[l] = other.Name
+		}
+	}
+
+	v := &ValidationError{}
+	for _, l := range g.Lights {
+		if room, ok := rooms[l]; ok {
+			v.add("lights", l, fmt.Sprintf("is already in room '%s'", room))
+		}
+	}
+
+	return v.err()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (g *Group) UnmarshalJSON(data []byte) error {
+	type group Group
+	extra, err := decodeExtra(data, (*group)(g))
+	if err != nil {
+		return err
+	}
+
+	g.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (g Group) MarshalJSON() ([]byte, error) {
+	type group Group
+	return encodeExtra(group(g), g.Extra)
+}
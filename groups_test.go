@@ -0,0 +1,107 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroup_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		group      Group
+		wantFields []string
+	}{
+		{
+			name:  "room",
+			group: Group{Name: "Kitchen", Type: GroupRoom, Class: ClassKitchen, Lights: []string{"1"}},
+		},
+		{
+			name:  "light group",
+			group: Group{Name: "Lamps", Type: GroupLightGroup, Lights: []string{"1", "2"}},
+		},
+		{
+			name:       "unknown class",
+			group:      Group{Name: "Kitchen", Type: GroupRoom, Class: "Scullery"},
+			wantFields: []string{"class"},
+		},
+		{
+			name:       "class on light group",
+			group:      Group{Name: "Lamps", Type: GroupLightGroup, Class: ClassOffice},
+			wantFields: []string{"class"},
+		},
+		{
+			name:       "unknown type",
+			group:      Group{Name: "Lamps", Type: "Luminaire"},
+			wantFields: []string{"type"},
+		},
+		{
+			name:       "missing name and duplicate light",
+			group:      Group{Type: GroupZone, Lights: []string{"1", "1"}},
+			wantFields: []string{"name", "lights"},
+		},
+		{
+			name:       "empty entertainment area",
+			group:      Group{Name: "TV", Type: GroupEntertainment, Class: ClassTV},
+			wantFields: []string{"lights"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := tt.group.Validate(); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Group.Validate() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestGroup_CheckRooms(t *testing.T) {
+	groups := []Group{
+		{ID: 1, Name: "Kitchen", Type: GroupRoom, Lights: []string{"1", "2"}},
+		{ID: 2, Name: "Downstairs", Type: GroupZone, Lights: []string{"3"}},
+	}
+
+	tests := []struct {
+		name       string
+		group      Group
+		wantValues []interface{}
+	}{
+		{
+			name:  "free lights",
+			group: Group{Type: GroupRoom, Lights: []string{"3", "4"}},
+		},
+		{
+			name:       "light in another room",
+			group:      Group{Type: GroupRoom, Lights: []string{"2", "4"}},
+			wantValues: []interface{}{"2"},
+		},
+		{
+			name:  "same room",
+			group: Group{ID: 1, Type: GroupRoom, Lights: []string{"1", "2", "4"}},
+		},
+		{
+			name:  "zone",
+			group: Group{Type: GroupZone, Lights: []string{"1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []interface{}
+			if err := tt.group.CheckRooms(groups); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Value)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantValues) {
+				t.Errorf("Group.CheckRooms() values = %v, want %v", got, tt.wantValues)
+			}
+		})
+	}
+}
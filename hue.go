@@ -1,6 +1,9 @@
 package hue
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type AllBridgeParams struct {
 	Method string
@@ -14,55 +17,71 @@ type UserKey struct{}
 type HostKey struct{}
 
 type Client interface {
-	AllLights(context.Context) ([]interface{}, error)
-	NewLights(context.Context) (interface{}, error)
+	AllLights(context.Context) ([]Light, error)
+	NewLights(context.Context) (*ScanResult, error)
 	SearchLights(context.Context, []string) error
-	GetLight(context.Context, int) (interface{}, error)
-	RenameLight(context.Context, string, string) (interface{}, error)
-	LightState(context.Context, int, interface{}) (interface{}, error)
-	Toggle(context.Context, int) (interface{}, error)
+	ScanForLights(context.Context, []string) ([]NewLight, error)
+	GetLight(context.Context, int) (*Light, error)
+	RenameLight(context.Context, int, string) (*Result, error)
+	SetLightConfig(context.Context, int, *LightConfigUpdate) (*Result, error)
+	LightState(context.Context, int, *StateUpdate) (*StateResult, error)
+	Toggle(context.Context, int) (*StateResult, error)
 	DeleteLight(context.Context, string) error
 
-	AllGroups(context.Context) ([]interface{}, error)
-	CreateGroup(context.Context, interface{}) (interface{}, error)
-	GetGroup(context.Context, string) (interface{}, error)
-	SaveGroup(context.Context, string, interface{}) (interface{}, error)
-	SetGroupState(context.Context, string, interface{}) (interface{}, error)
-	DeleteGroup(context.Context, string) error
+	AllGroups(context.Context) ([]Group, error)
+	CreateGroup(context.Context, *Group) (int, error)
+	GetGroup(context.Context, int) (*Group, error)
+	SaveGroup(context.Context, int, *Group) (*Result, error)
+	SetGroupState(context.Context, int, *StateUpdate) (*Result, error)
+	RecallScene(context.Context, int, string, time.Duration) (*Result, error)
+	ToggleGroup(context.Context, int) (*Result, error)
+	SetAllLights(context.Context, *StateUpdate) (*Result, error)
+	DeleteGroup(context.Context, int) error
 
-	AllSchedules() ([]interface{}, error)
-	CreateSchedule(interface{}) (interface{}, error)
-	GetSchedule(string) (interface{}, error)
-	SetSchedule(string, interface{}) (interface{}, error)
-	DeleteSchedule(string) error
+	AllSchedules(context.Context) ([]Schedule, error)
+	CreateSchedule(context.Context, *Schedule) (int, error)
+	GetSchedule(context.Context, int) (*Schedule, error)
+	SetSchedule(context.Context, int, *Schedule) (*Result, error)
+	DeleteSchedule(context.Context, int) error
 
-	AllScenes() ([]interface{}, error)
-	GetScene(string) (interface{}, error)
-	CreateScene(interface{}) (interface{}, error)
-	SetScene(string, interface{}) (interface{}, error)
-	DeleteScene(string) error
+	AllScenes(context.Context) ([]Scene, error)
+	GetScene(context.Context, string) (*Scene, error)
+	CreateScene(context.Context, *Scene) (string, error)
+	SetScene(context.Context, string, *Scene) (*Result, error)
+	CaptureScene(context.Context, *Scene) (string, error)
+	SetSceneLightState(context.Context, string, int, *StateUpdate) (*Result, error)
+	DeleteScene(context.Context, string) error
 
-	AllSensors() ([]interface{}, error)
-	CreateSensor(interface{}) (interface{}, error)
-	SearchSensors() error
-	NewSensors() ([]interface{}, error)
-	GetSensor(string) (interface{}, error)
-	SetSensor(string, interface{}) (interface{}, error)
-	RenameSensor(string, string) (interface{}, error)
-	DeleteSensor(string) error
+	AllSensors(context.Context) ([]Sensor, error)
+	CreateSensor(context.Context, *Sensor) (int, error)
+	SearchSensors(context.Context) error
+	NewSensors(context.Context) (*SensorScanResult, error)
+	PairSensors(context.Context) ([]SensorDevice, error)
+	GetSensor(context.Context, int) (*Sensor, error)
+	RenameSensor(context.Context, int, string) (*Result, error)
+	SetSensorConfig(context.Context, int, *SensorConfigUpdate) (*Result, error)
+	SetSensorState(context.Context, int, SensorState) (*Result, error)
+	DeleteSensor(context.Context, int) error
 
-	AllRules() ([]interface{}, error)
-	GetRule(string) (interface{}, error)
-	CreateRule(interface{}) (interface{}, error)
-	UpdateRule(string, interface{}) (interface{}, error)
-	DeleteRule(string) error
+	AllRules(context.Context) ([]Rule, error)
+	GetRule(context.Context, int) (*Rule, error)
+	CreateRule(context.Context, *Rule) (int, error)
+	UpdateRule(context.Context, int, *Rule) (*Result, error)
+	DeleteRule(context.Context, int) error
+
+	AllResourceLinks(context.Context) ([]ResourceLink, error)
+	GetResourceLink(context.Context, int) (*ResourceLink, error)
+	CreateResourceLink(context.Context, *ResourceLink) (int, error)
+	UpdateResourceLink(context.Context, int, *ResourceLink) (*Result, error)
+	DeleteResourceLink(context.Context, int) error
 
 	AllBridges(context.Context, interface{}) ([]interface{}, error)
-	CreateUser(interface{}) (interface{}, error)
+	CreateUser(context.Context, interface{}) (interface{}, error)
 	GetConfig(context.Context) (interface{}, error)
-	ModifyConfig(interface{}) (interface{}, error)
-	Unwhitelist(string) error
-	GetFullState(context.Context) (interface{}, error)
+	TimeZone(context.Context) (*time.Location, error)
+	ModifyConfig(context.Context, interface{}) (interface{}, error)
+	Unwhitelist(context.Context, string) error
+	GetFullState(context.Context) (*FullState, error)
 }
 
 type Bridge struct {
@@ -0,0 +1,93 @@
+package hue
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// decodeExtra unmarshals data into v and returns every top level key
+// that isn't mapped to a field of v, so it can be written back out
+// untouched by encodeExtra.
+func decodeExtra(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	for _, key := range knownKeys(v) {
+		delete(raw, key)
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	return raw, nil
+}
+
+// encodeExtra marshals v and merges in the preserved extra keys. Keys
+// known to v always win over an extra key of the same name.
+func encodeExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	for key, val := range extra {
+		if _, ok := raw[key]; !ok {
+			raw[key] = val
+		}
+	}
+
+	return json.Marshal(raw)
+}
+
+// knownKeys lists the JSON object keys that are mapped to the exported
+// fields of the struct v points to.
+func knownKeys(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	keys := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			if idx := strings.Index(tag, ","); idx >= 0 {
+				tag = tag[:idx]
+			}
+
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		keys = append(keys, name)
+	}
+
+	return keys
+}
@@ -0,0 +1,88 @@
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Format is the file format a library is written in.
+type Format string
+
+// Library formats.
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// FormatOf returns the format of a library file from its extension, JSON
+// is assumed for unknown extensions.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAML
+	default:
+		return JSON
+	}
+}
+
+// Encode writes the library to w in the given format.
+func (l *Library) Encode(w io.Writer, f Format) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case JSON:
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case YAML:
+		return jsonToYAML(w, data)
+	default:
+		return errors.Errorf("unknown library format '%s'", f)
+	}
+}
+
+// Decode reads a library written in either format, a JSON document starts
+// with an object and anything else is read as YAML. Libraries written by a
+// newer version of the format are refused.
+func Decode(r io.Reader) (*Library, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var lib Library
+	if err := json.Unmarshal(data, &lib); err != nil {
+		return nil, errors.Errorf("invalid library: %v", err)
+	}
+
+	if err := lib.checkVersion(); err != nil {
+		return nil, err
+	}
+
+	return &lib, nil
+}
+
+func (l *Library) checkVersion() error {
+	switch {
+	case l.Version <= 0:
+		return errors.New("invalid library: missing version")
+	case l.Version > Version:
+		return errors.Errorf("library version %d is newer than the supported version %d", l.Version, Version)
+	}
+
+	return nil
+}
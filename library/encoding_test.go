@@ -0,0 +1,163 @@
+package library
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+func testLibrary() *Library {
+	on := true
+	bri := 254
+
+	return &Library{
+		Version: Version,
+		Scenes: []Scene{
+			{
+				Name:    "Reading: \"late\"",
+				AppData: hue.AppData{Version: 1, Data: "r01"},
+				Lights: []Light{
+					{UniqueID: "00:17:88:01:00:00:00:01-0b", Name: "Desk", State: hue.StateUpdate{On: &on, Bri: &bri, XY: []float64{0.4573, 0.41}}},
+					{UniqueID: "00:17:88:01:00:00:00:02-0b", Name: "#2 it's", State: hue.StateUpdate{On: &on}},
+				},
+			},
+			{Name: "Empty"},
+		},
+	}
+}
+
+func TestLibrary_Encode(t *testing.T) {
+	for _, f := range []Format{JSON, YAML} {
+		t.Run(string(f), func(t *testing.T) {
+			var b bytes.Buffer
+			if err := testLibrary().Encode(&b, f); err != nil {
+				t.Fatalf("Library.Encode() error = %v", err)
+			}
+
+			got, err := Decode(&b)
+			if err != nil {
+				t.Fatalf("Decode() error = %v\n%s", err, b.String())
+			}
+
+			if want := testLibrary(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLibrary_EncodeYAML(t *testing.T) {
+	var b bytes.Buffer
+	lib := &Library{
+		Version: Version,
+		Scenes: []Scene{{
+			Name:   "Reading",
+			Lights: []Light{{UniqueID: "u1", Name: "Desk", State: hue.StateUpdate{XY: []float64{0.5, 0.4}}}},
+		}},
+	}
+	if err := lib.Encode(&b, YAML); err != nil {
+		t.Fatalf("Library.Encode() error = %v", err)
+	}
+
+	want := `version: 1
+scenes:
+- name: Reading
+  appdata: {}
+  lights:
+  - uniqueid: u1
+    name: Desk
+    state:
+      xy:
+      - 0.5
+      - 0.4
+`
+	if b.String() != want {
+		t.Errorf("Library.Encode() = \n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	on := true
+
+	tests := []struct {
+		name    string
+		doc     string
+		want    *Library
+		wantErr bool
+	}{
+		{
+			name: "hand written yaml",
+			doc: `# scenes for the study
+version: 1
+scenes:
+- name: Reading  # plain scalar
+  lights:
+  - uniqueid: '00:17:88:01:00:00:00:01-0b'
+    name: Desk
+    state: {"on": true, "xy": [0.5, 0.4]}
+`,
+			want: &Library{Version: 1, Scenes: []Scene{{
+				Name: "Reading",
+				Lights: []Light{{
+					UniqueID: "00:17:88:01:00:00:00:01-0b",
+					Name:     "Desk",
+					State:    hue.StateUpdate{On: &on, XY: []float64{0.5, 0.4}},
+				}},
+			}}},
+		},
+		{
+			name: "json",
+			doc:  `{"version": 1, "scenes": []}`,
+			want: &Library{Version: 1, Scenes: []Scene{}},
+		},
+		{
+			name:    "newer version",
+			doc:     "version: 2\nscenes: []\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing version",
+			doc:     "scenes: []\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad indentation",
+			doc:     "version: 1\n  scenes: []\n",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated sequence",
+			doc:     "version: 1\nscenes: [\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(strings.NewReader(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]Format{
+		"scenes.yaml": YAML,
+		"scenes.YML":  YAML,
+		"scenes.json": JSON,
+		"scenes":      JSON,
+	}
+	for path, want := range tests {
+		if got := FormatOf(path); got != want {
+			t.Errorf("FormatOf(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
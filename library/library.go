@@ -0,0 +1,245 @@
+// Package library moves scenes between bridges. Scenes are exported to a
+// versioned file that refers to lights by their unique ID and name rather
+// than the identifier a single bridge gave them, and imported by matching
+// those lights against the lights of another bridge. Templates describe
+// scenes in terms of light roles so they can be instantiated on any group.
+package library
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
+)
+
+// Version is the version of the library format written by this package.
+const Version = 1
+
+// Library is a portable set of scenes.
+type Library struct {
+	Version int     `json:"version"`
+	Scenes  []Scene `json:"scenes"`
+}
+
+// Scene is a scene detached from the bridge it was exported from.
+type Scene struct {
+	Name    string      `json:"name"`
+	AppData hue.AppData `json:"appdata"`
+	Lights  []Light     `json:"lights"`
+}
+
+// Light is a light of a scene along with the state the scene recalls on
+// it.
+type Light struct {
+	// UniqueID is the hardware address of the light, it matches the
+	// same light on every bridge.
+	UniqueID string `json:"uniqueid"`
+
+	// Name is used to match lights when no light with the unique ID is
+	// found, such as a replaced bulb that kept its name.
+	Name string `json:"name"`
+
+	State hue.StateUpdate `json:"state"`
+}
+
+// Report describes what an import changed on the bridge.
+type Report struct {
+	// Created and Updated map the names of the imported scenes to their
+	// identifier on the bridge.
+	Created map[string]string
+	Updated map[string]string
+
+	// Skipped lists the scenes that weren't imported because none of
+	// their lights were found.
+	Skipped []string
+
+	// Unmatched lists the lights that weren't found on the bridge.
+	Unmatched []Unmatched
+}
+
+// Unmatched is a light of a scene that has no counterpart on the bridge.
+type Unmatched struct {
+	Scene string
+	Light Light
+}
+
+func (u Unmatched) String() string {
+	return fmt.Sprintf("scene '%s': light '%s' (%s)", u.Scene, u.Light.Name, u.Light.UniqueID)
+}
+
+// Export builds a library from the scenes with the given identifiers,
+// every scene on the bridge is exported when none are given. Lights of a
+// scene without a stored state are left out.
+func Export(ctx context.Context, c hue.Client, ids ...string) (*Library, error) {
+	lights, err := c.AllLights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]hue.Light, len(lights))
+	for _, l := range lights {
+		byID[strconv.Itoa(l.ID)] = l
+	}
+
+	if len(ids) == 0 {
+		scenes, err := c.AllScenes(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range scenes {
+			if !s.Recycle {
+				ids = append(ids, s.ID)
+			}
+		}
+	}
+
+	lib := &Library{
+		Version: Version,
+		Scenes:  make([]Scene, 0, len(ids)),
+	}
+	for _, id := range ids {
+		// the light states are only reported for a single scene
+		scene, err := c.GetScene(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		exported := Scene{
+			Name:    scene.Name,
+			AppData: scene.AppData,
+		}
+		for _, lightID := range scene.LightStates.Lights() {
+			l, ok := byID[lightID]
+			if !ok {
+				return nil, errors.Errorf("scene '%s' has a state for unknown light %s", id, lightID)
+			}
+
+			exported.Lights = append(exported.Lights, Light{
+				UniqueID: l.UniqueID,
+				Name:     l.Name,
+				State:    scene.LightStates[lightID],
+			})
+		}
+
+		lib.Scenes = append(lib.Scenes, exported)
+	}
+
+	return lib, nil
+}
+
+// Import creates the scenes of the library on the bridge, or updates the
+// scene with the same name when there is one. Lights are matched by
+// unique ID first and then by name, lights that can't be matched are
+// reported and left out of the scene.
+func Import(ctx context.Context, c hue.Client, lib *Library) (*Report, error) {
+	if err := lib.checkVersion(); err != nil {
+		return nil, err
+	}
+
+	lights, err := c.AllLights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scenes, err := c.AllScenes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]hue.Scene, len(scenes))
+	for _, s := range scenes {
+		if !s.Recycle {
+			existing[s.Name] = s
+		}
+	}
+
+	m := newMatcher(lights)
+	report := &Report{
+		Created: map[string]string{},
+		Updated: map[string]string{},
+	}
+	for _, s := range lib.Scenes {
+		scene := hue.Scene{
+			Name:        s.Name,
+			Type:        hue.LightScene,
+			AppData:     s.AppData,
+			LightStates: hue.LightStates{},
+		}
+		for _, l := range s.Lights {
+			id, ok := m.match(l)
+			if !ok {
+				report.Unmatched = append(report.Unmatched, Unmatched{Scene: s.Name, Light: l})
+				continue
+			}
+
+			scene.Lights = append(scene.Lights, id)
+			scene.LightStates[id] = l.State
+		}
+
+		if len(scene.Lights) == 0 {
+			report.Skipped = append(report.Skipped, s.Name)
+			continue
+		}
+
+		sort.Strings(scene.Lights)
+
+		if old, ok := existing[s.Name]; ok {
+			scene.Type, scene.Group = old.Type, old.Group
+			if _, err := c.SetScene(ctx, old.ID, &scene); err != nil {
+				return report, errors.Wrapf(err, "updating scene '%s'", s.Name)
+			}
+
+			report.Updated[s.Name] = old.ID
+			continue
+		}
+
+		id, err := c.CreateScene(ctx, &scene)
+		if err != nil {
+			return report, errors.Wrapf(err, "creating scene '%s'", s.Name)
+		}
+
+		report.Created[s.Name] = id
+	}
+
+	return report, nil
+}
+
+// matcher finds the lights of a bridge by unique ID or by name.
+type matcher struct {
+	byUniqueID map[string]string
+	byName     map[string][]string
+}
+
+func newMatcher(lights []hue.Light) *matcher {
+	m := &matcher{
+		byUniqueID: make(map[string]string, len(lights)),
+		byName:     make(map[string][]string, len(lights)),
+	}
+	for _, l := range lights {
+		id := strconv.Itoa(l.ID)
+		if l.UniqueID != "" {
+			m.byUniqueID[l.UniqueID] = id
+		}
+		m.byName[l.Name] = append(m.byName[l.Name], id)
+	}
+
+	return m
+}
+
+// match returns the identifier of the light, a name only matches when a
+// single light on the bridge has it.
+func (m *matcher) match(l Light) (string, bool) {
+	if id, ok := m.byUniqueID[l.UniqueID]; ok && l.UniqueID != "" {
+		return id, true
+	}
+
+	if ids := m.byName[l.Name]; len(ids) == 1 && l.Name != "" {
+		return ids[0], true
+	}
+
+	return "", false
+}
@@ -0,0 +1,174 @@
+package library
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+// bridge is a hue.Client holding lights and scenes in memory, the methods
+// that aren't overridden panic.
+type bridge struct {
+	hue.Client
+
+	lights  []hue.Light
+	scenes  map[string]*hue.Scene
+	created []hue.Scene
+	updated map[string]hue.Scene
+}
+
+func (b *bridge) AllLights(ctx context.Context) ([]hue.Light, error) {
+	return b.lights, nil
+}
+
+func (b *bridge) AllScenes(ctx context.Context) ([]hue.Scene, error) {
+	var scenes []hue.Scene
+	for id, s := range b.scenes {
+		scene := *s
+		scene.ID = id
+		scene.LightStates = nil
+		scenes = append(scenes, scene)
+	}
+
+	return scenes, nil
+}
+
+func (b *bridge) GetScene(ctx context.Context, id string) (*hue.Scene, error) {
+	return b.scenes[id], nil
+}
+
+func (b *bridge) CreateScene(ctx context.Context, scene *hue.Scene) (string, error) {
+	if err := scene.Validate(); err != nil {
+		return "", err
+	}
+
+	b.created = append(b.created, *scene)
+	return "new", nil
+}
+
+func (b *bridge) SetScene(ctx context.Context, id string, scene *hue.Scene) (*hue.Result, error) {
+	if err := scene.Validate(); err != nil {
+		return nil, err
+	}
+
+	b.updated[id] = *scene
+	return &hue.Result{}, nil
+}
+
+func TestExport(t *testing.T) {
+	on := true
+	bri := 200
+
+	b := &bridge{
+		lights: []hue.Light{
+			{ID: 1, Name: "Desk", UniqueID: "00:17:88:01:00:00:00:01-0b"},
+			{ID: 2, Name: "Sofa", UniqueID: "00:17:88:01:00:00:00:02-0b"},
+		},
+		scenes: map[string]*hue.Scene{
+			"abc": {
+				Name:        "Reading",
+				Lights:      []string{"1", "2"},
+				AppData:     hue.AppData{Version: 1, Data: "reading"},
+				LightStates: hue.LightStates{"2": {On: &on, Bri: &bri}, "1": {On: &on}},
+			},
+			"old": {Name: "Recycled", Recycle: true},
+		},
+	}
+
+	got, err := Export(context.Background(), b)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := &Library{
+		Version: Version,
+		Scenes: []Scene{{
+			Name:    "Reading",
+			AppData: hue.AppData{Version: 1, Data: "reading"},
+			Lights: []Light{
+				{UniqueID: "00:17:88:01:00:00:00:01-0b", Name: "Desk", State: hue.StateUpdate{On: &on}},
+				{UniqueID: "00:17:88:01:00:00:00:02-0b", Name: "Sofa", State: hue.StateUpdate{On: &on, Bri: &bri}},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Export() = %+v, want %+v", got, want)
+	}
+}
+
+func TestImport(t *testing.T) {
+	on := true
+
+	b := &bridge{
+		lights: []hue.Light{
+			{ID: 4, Name: "Desk", UniqueID: "00:17:88:01:00:00:00:01-0b"},
+			{ID: 7, Name: "Sofa", UniqueID: "00:17:88:01:00:00:00:99-0b"},
+			{ID: 8, Name: "Hall"},
+			{ID: 9, Name: "Hall"},
+		},
+		scenes: map[string]*hue.Scene{
+			"xyz": {Name: "Relax", Type: hue.GroupScene, Group: "1"},
+		},
+		updated: map[string]hue.Scene{},
+	}
+
+	lib := &Library{
+		Version: Version,
+		Scenes: []Scene{
+			{Name: "Reading", Lights: []Light{
+				// matched by unique ID even though it was renamed
+				{UniqueID: "00:17:88:01:00:00:00:01-0b", Name: "Study", State: hue.StateUpdate{On: &on}},
+				// replaced bulb, matched by name
+				{UniqueID: "00:17:88:01:00:00:00:02-0b", Name: "Sofa", State: hue.StateUpdate{On: &on}},
+				// two lights share the name
+				{UniqueID: "00:17:88:01:00:00:00:03-0b", Name: "Hall", State: hue.StateUpdate{On: &on}},
+			}},
+			{Name: "Relax", Lights: []Light{
+				{UniqueID: "00:17:88:01:00:00:00:01-0b", Name: "Desk", State: hue.StateUpdate{On: &on}},
+			}},
+			{Name: "Garden", Lights: []Light{
+				{UniqueID: "00:17:88:01:00:00:00:05-0b", Name: "Porch", State: hue.StateUpdate{On: &on}},
+			}},
+		},
+	}
+
+	got, err := Import(context.Background(), b, lib)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	want := &Report{
+		Created: map[string]string{"Reading": "new"},
+		Updated: map[string]string{"Relax": "xyz"},
+		Skipped: []string{"Garden"},
+		Unmatched: []Unmatched{
+			{Scene: "Reading", Light: lib.Scenes[0].Lights[2]},
+			{Scene: "Garden", Light: lib.Scenes[2].Lights[0]},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Import() = %+v, want %+v", got, want)
+	}
+
+	created := []hue.Scene{{
+		Name:        "Reading",
+		Type:        hue.LightScene,
+		Lights:      []string{"4", "7"},
+		LightStates: hue.LightStates{"4": {On: &on}, "7": {On: &on}},
+	}}
+	if !reflect.DeepEqual(b.created, created) {
+		t.Errorf("Import() created %+v, want %+v", b.created, created)
+	}
+
+	if s := b.updated["xyz"]; s.Type != hue.GroupScene || s.Group != "1" || !reflect.DeepEqual(s.Lights, []string{"4"}) {
+		t.Errorf("Import() updated %+v", s)
+	}
+}
+
+func TestImport_version(t *testing.T) {
+	if _, err := Import(context.Background(), &bridge{}, &Library{Version: Version + 1}); err == nil {
+		t.Error("Import() of a newer library version succeeded")
+	}
+}
@@ -0,0 +1,314 @@
+package library
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/ninnemana/huego"
+	"github.com/ninnemana/huego/color"
+	"github.com/pkg/errors"
+)
+
+// Role selects the lights of a group a look applies to.
+type Role string
+
+// Light roles.
+const (
+	// AllLights matches every light of the group.
+	AllLights Role = "all"
+
+	// ColorLights matches the lights that render colors.
+	ColorLights Role = "color"
+
+	// AmbianceLights matches the lights that only render shades of
+	// white.
+	AmbianceLights Role = "ambiance"
+
+	// DimmableLights matches the lights that can only be dimmed.
+	DimmableLights Role = "dimmable"
+
+	// AccentLights matches the decorative lights, such as light strips,
+	// and lights configured with the decorative function.
+	AccentLights Role = "accent"
+)
+
+// accentArchetypes are the archetypes of lights used as accents.
+var accentArchetypes = map[string]bool{
+	"huelightstrip": true,
+	"huego":         true,
+	"hueplay":       true,
+	"hueiris":       true,
+	"huebloom":      true,
+	"flexiblelamp":  true,
+}
+
+// Template is a scene expressed in terms of light roles, so it can be
+// instantiated on any group.
+type Template struct {
+	Name string `json:"name"`
+
+	// Looks are matched against each light in order, the first look
+	// whose role matches the light sets its state. Lights matching no
+	// look get no state, they're still part of the scene as a group
+	// scene holds every light of its group, and the bridge stores the
+	// state they're in when the scene is saved.
+	Looks []Look `json:"looks"`
+}
+
+// Look is the state of the lights with a role, relative to what each
+// light can render.
+type Look struct {
+	Role Role `json:"role"`
+
+	// Off turns the lights off, the other attributes are ignored.
+	Off bool `json:"off,omitempty"`
+
+	// Brightness is from 0 to 1, it is scaled to the lowest brightness
+	// the bridge accepts.
+	Brightness float64 `json:"brightness"`
+
+	// Kelvin is the color temperature, it is clamped to the range of
+	// each light and approximated by color lights that have no range.
+	Kelvin int `json:"kelvin,omitempty"`
+
+	// Hue in degrees and Saturation from 0 to 1 set a color, rendered by
+	// color lights only. Other lights fall back to Kelvin when it's set.
+	// Saturation defaults to 1.
+	Hue        *float64 `json:"hue,omitempty"`
+	Saturation *float64 `json:"saturation,omitempty"`
+}
+
+// Templates shipped with the package.
+var (
+	Focus = Template{
+		Name: "Focus",
+		Looks: []Look{
+			{Role: AllLights, Brightness: 1, Kelvin: 5000},
+		},
+	}
+
+	Relax = Template{
+		Name: "Relax",
+		Looks: []Look{
+			{Role: AccentLights, Brightness: 0.3, Hue: floatPtr(30), Kelvin: 2200},
+			{Role: AllLights, Brightness: 0.55, Kelvin: 2700},
+		},
+	}
+
+	Night = Template{
+		Name: "Night",
+		Looks: []Look{
+			{Role: AccentLights, Off: true},
+			{Role: AllLights, Brightness: 0.05, Kelvin: 2200},
+		},
+	}
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+// Validate checks the looks of the template.
+func (t *Template) Validate() error {
+	if err := hue.ValidateName(t.Name); err != nil {
+		return err
+	}
+
+	if len(t.Looks) == 0 {
+		return errors.Errorf("template '%s' has no looks", t.Name)
+	}
+
+	for i, l := range t.Looks {
+		switch l.Role {
+		case AllLights, ColorLights, AmbianceLights, DimmableLights, AccentLights:
+		default:
+			return errors.Errorf("template '%s': look %d has unknown role '%s'", t.Name, i, l.Role)
+		}
+
+		if l.Brightness < 0 || l.Brightness > 1 {
+			return errors.Errorf("template '%s': look %d brightness must be between 0 and 1", t.Name, i)
+		}
+
+		if l.Saturation != nil && (*l.Saturation < 0 || *l.Saturation > 1) {
+			return errors.Errorf("template '%s': look %d saturation must be between 0 and 1", t.Name, i)
+		}
+
+		if l.Kelvin < 0 {
+			return errors.Errorf("template '%s': look %d kelvin can't be negative", t.Name, i)
+		}
+	}
+
+	return nil
+}
+
+// States returns the state of each light of the group matched by a look,
+// lights that aren't part of the group are ignored.
+func (t *Template) States(group *hue.Group, lights []hue.Light) (hue.LightStates, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]hue.Light, len(lights))
+	for _, l := range lights {
+		byID[strconv.Itoa(l.ID)] = l
+	}
+
+	states := hue.LightStates{}
+	for _, id := range group.Lights {
+		l, ok := byID[id]
+		if !ok {
+			return nil, errors.Errorf("group %d has unknown light %s", group.ID, id)
+		}
+
+		f := featuresOf(&l)
+		for _, look := range t.Looks {
+			if !f.is(look.Role) {
+				continue
+			}
+
+			state, err := look.state(&l, f)
+			if err != nil {
+				return nil, errors.Wrapf(err, "light %s", id)
+			}
+
+			states[id] = *state
+			break
+		}
+	}
+
+	return states, nil
+}
+
+// Apply instantiates the template on the group as a group scene named
+// after the template, the scene is updated when the group already has one.
+// It returns the identifier of the scene.
+func (t *Template) Apply(ctx context.Context, c hue.Client, group *hue.Group) (string, error) {
+	lights, err := c.AllLights(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	states, err := t.States(group, lights)
+	if err != nil {
+		return "", err
+	}
+
+	if len(states) == 0 {
+		return "", errors.Errorf("template '%s' matches none of the lights of group %d", t.Name, group.ID)
+	}
+
+	scene := hue.Scene{
+		Name:        t.Name,
+		Type:        hue.GroupScene,
+		Group:       strconv.Itoa(group.ID),
+		Lights:      states.Lights(),
+		LightStates: states,
+	}
+
+	scenes, err := c.AllScenes(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, s := range scenes {
+		if s.Type == hue.GroupScene && s.Group == scene.Group && s.Name == scene.Name && !s.Recycle {
+			if _, err := c.SetScene(ctx, s.ID, &scene); err != nil {
+				return "", err
+			}
+
+			return s.ID, nil
+		}
+	}
+
+	return c.CreateScene(ctx, &scene)
+}
+
+// state builds the state of a light for the look.
+func (l *Look) state(light *hue.Light, f features) (*hue.StateUpdate, error) {
+	b := light.StateBuilder().On(!l.Off)
+	if l.Off {
+		return b.Build()
+	}
+
+	if f&dimmable != 0 {
+		b.Bri(int(math.Max(1, math.Round(l.Brightness*254))))
+	}
+
+	switch {
+	case l.Hue != nil && f&colored != 0:
+		sat := 1.0
+		if l.Saturation != nil {
+			sat = *l.Saturation
+		}
+
+		// passed as xy so the hue doesn't override the brightness
+		b.Color(color.HSV{H: *l.Hue, S: sat, V: 1}.XY())
+	case l.Kelvin > 0 && f&ambiance != 0:
+		// sent as ct clamped to the range of the light
+		b.Color(color.Kelvin(l.Kelvin))
+	case l.Kelvin > 0 && f&colored != 0:
+		b.Color(color.Kelvin(l.Kelvin).XY())
+	}
+
+	return b.Build()
+}
+
+// features is what a light can render.
+type features uint8
+
+const (
+	dimmable features = 1 << iota
+	ambiance
+	colored
+	accent
+)
+
+// featuresOf reads the features of a light from its type, falling back to
+// its capabilities for types that aren't known.
+func featuresOf(l *hue.Light) features {
+	var f features
+	switch l.Type {
+	case "Extended color light":
+		f = dimmable | ambiance | colored
+	case "Color light":
+		f = dimmable | colored
+	case "Color temperature light":
+		f = dimmable | ambiance
+	case "Dimmable light":
+		f = dimmable
+	case "On/Off plug-in unit", "On/Off light":
+	default:
+		f = dimmable
+		if l.Capabilities.Control.CT != nil {
+			f |= ambiance
+		}
+		if _, ok := l.Gamut(); ok {
+			f |= colored
+		}
+	}
+
+	if accentArchetypes[l.Config.Archetype] || l.Config.Function == "decorative" {
+		f |= accent
+	}
+
+	return f
+}
+
+// is reports whether a light with the features has the role.
+func (f features) is(r Role) bool {
+	switch r {
+	case AllLights:
+		return true
+	case ColorLights:
+		return f&colored != 0
+	case AmbianceLights:
+		return f&ambiance != 0 && f&colored == 0
+	case DimmableLights:
+		return f&dimmable != 0 && f&(ambiance|colored) == 0
+	case AccentLights:
+		return f&accent != 0
+	default:
+		return false
+	}
+}
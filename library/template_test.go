@@ -0,0 +1,158 @@
+package library
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+var templateLights = []hue.Light{
+	{
+		ID:   1,
+		Type: "Extended color light",
+		Capabilities: hue.LightCapabilities{
+			Certified: true,
+			Control:   hue.LightControl{ColorGamutType: "C", CT: &hue.CTRange{Min: 153, Max: 454}},
+		},
+	},
+	{
+		ID:   2,
+		Type: "Color temperature light",
+		Capabilities: hue.LightCapabilities{
+			Certified: true,
+			Control:   hue.LightControl{CT: &hue.CTRange{Min: 153, Max: 370}},
+		},
+	},
+	{ID: 3, Type: "Dimmable light", Capabilities: hue.LightCapabilities{Certified: true}},
+	{
+		ID:     4,
+		Type:   "Extended color light",
+		Config: hue.LightConfig{Archetype: "huelightstrip"},
+		Capabilities: hue.LightCapabilities{
+			Certified: true,
+			Control:   hue.LightControl{ColorGamutType: "C", CT: &hue.CTRange{Min: 153, Max: 500}},
+		},
+	},
+	{ID: 5, Type: "On/Off plug-in unit"},
+	{ID: 6, Type: "Color light", ModelID: "LLC010"},
+}
+
+func TestTemplate_States(t *testing.T) {
+	group := &hue.Group{ID: 1, Lights: []string{"1", "2", "3", "4", "5", "6"}}
+
+	tests := []struct {
+		name     string
+		template Template
+		want     hue.LightStates
+		wantErr  bool
+	}{
+		{
+			name:     "warm white everywhere",
+			template: Template{Name: "Warm", Looks: []Look{{Role: AllLights, Brightness: 0.4, Kelvin: 2700}}},
+			want: hue.LightStates{
+				"1": {On: boolPtr(true), Bri: intPtr(102), CT: intPtr(370)},
+				// 370 mired is the warmest this light renders
+				"2": {On: boolPtr(true), Bri: intPtr(102), CT: intPtr(370)},
+				"3": {On: boolPtr(true), Bri: intPtr(102)},
+				"4": {On: boolPtr(true), Bri: intPtr(102), CT: intPtr(370)},
+				"5": {On: boolPtr(true)},
+				// no color temperature, approximated in its gamut
+				"6": {On: boolPtr(true), Bri: intPtr(102), XY: []float64{0.4591, 0.4106}},
+			},
+		},
+		{
+			name:     "night",
+			template: Night,
+			want: hue.LightStates{
+				"1": {On: boolPtr(true), Bri: intPtr(13), CT: intPtr(454)},
+				"2": {On: boolPtr(true), Bri: intPtr(13), CT: intPtr(370)},
+				"3": {On: boolPtr(true), Bri: intPtr(13)},
+				"4": {On: boolPtr(false)},
+				"5": {On: boolPtr(true)},
+				"6": {On: boolPtr(true), Bri: intPtr(13), XY: []float64{0.5057, 0.4152}},
+			},
+		},
+		{
+			name: "accent hue with roles",
+			template: Template{Name: "Party", Looks: []Look{
+				{Role: AccentLights, Brightness: 1, Hue: floatPtr(240)},
+				{Role: ColorLights, Brightness: 0.5, Hue: floatPtr(0), Saturation: floatPtr(1)},
+				{Role: AmbianceLights, Brightness: 0, Kelvin: 6500},
+			}},
+			want: hue.LightStates{
+				"1": {On: boolPtr(true), Bri: intPtr(127), XY: []float64{0.6915, 0.3083}},
+				"2": {On: boolPtr(true), Bri: intPtr(1), CT: intPtr(154)},
+				"4": {On: boolPtr(true), Bri: intPtr(254), XY: []float64{0.1532, 0.0475}},
+				"6": {On: boolPtr(true), Bri: intPtr(127), XY: []float64{0.7004, 0.2991}},
+			},
+		},
+		{
+			name:     "unknown role",
+			template: Template{Name: "Odd", Looks: []Look{{Role: "spot"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "brightness out of range",
+			template: Template{Name: "Odd", Looks: []Look{{Role: AllLights, Brightness: 2}}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.template.States(group, templateLights)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Template.States() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Template.States() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate_Apply(t *testing.T) {
+	b := &bridge{
+		lights: templateLights,
+		scenes: map[string]*hue.Scene{
+			"focus2": {Name: "Focus", Type: hue.GroupScene, Group: "2"},
+			"relax1": {Name: "Relax", Type: hue.GroupScene, Group: "1"},
+		},
+		updated: map[string]hue.Scene{},
+	}
+
+	group := &hue.Group{ID: 1, Lights: []string{"2", "3"}}
+
+	id, err := Focus.Apply(context.Background(), b, group)
+	if err != nil || id != "new" {
+		t.Fatalf("Template.Apply() = %v, %v, want new", id, err)
+	}
+
+	if len(b.created) != 1 || b.created[0].Group != "1" || !reflect.DeepEqual(b.created[0].Lights, []string{"2", "3"}) {
+		t.Errorf("Template.Apply() created %+v", b.created)
+	}
+
+	id, err = Relax.Apply(context.Background(), b, group)
+	if err != nil || id != "relax1" {
+		t.Fatalf("Template.Apply() = %v, %v, want relax1", id, err)
+	}
+
+	if _, ok := b.updated["relax1"]; !ok {
+		t.Errorf("Template.Apply() didn't update the existing scene")
+	}
+
+	empty := Template{Name: "Accents", Looks: []Look{{Role: AccentLights, Brightness: 1}}}
+	if _, err := empty.Apply(context.Background(), b, group); err == nil {
+		t.Errorf("Template.Apply() without matching lights succeeded")
+	}
+}
@@ -0,0 +1,66 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The library types only carry json tags and marshallers, YAML documents
+// are converted from and to JSON so both formats share them.
+
+// jsonToYAML writes the JSON document data to w as YAML. JSON is read as
+// YAML so the keys keep the order of the struct fields.
+func jsonToYAML(w io.Writer, data []byte) error {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// yamlToJSON converts a YAML document to JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Errorf("invalid library: %v", err)
+	}
+
+	if doc == nil {
+		return nil, errors.New("invalid library: empty document")
+	}
+
+	return json.Marshal(jsonValue(doc))
+}
+
+// jsonValue replaces the mappings decoded from YAML, which can have keys
+// of any type, by objects that can be encoded as JSON.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			obj[fmt.Sprint(k)] = jsonValue(val)
+		}
+
+		return obj
+	case []interface{}:
+		for i, val := range v {
+			v[i] = jsonValue(val)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,109 @@
+package hue
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the longest name, in characters, the bridge accepts for
+// a light.
+const MaxNameLength = 32
+
+// Power-on behaviours a light can be configured with through
+// LightConfigUpdate.
+const (
+	StartupSafety      = "safety"
+	StartupPowerfail   = "powerfail"
+	StartupLastOnState = "lastonstate"
+	StartupCustom      = "custom"
+)
+
+// ValidateName checks name is within the length the bridge accepts for
+// the name of a light.
+func ValidateName(name string) error {
+	v := &ValidationError{}
+
+	n := utf8.RuneCountInString(name)
+	switch {
+	case strings.TrimSpace(name) == "":
+		v.add("name", name, "can't be empty")
+	case n > MaxNameLength:
+		v.add("name", name, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	}
+
+	return v.err()
+}
+
+// LightConfigUpdate is the body of a configuration write to a light.
+// PUT /api/<username>/lights/<id>/config
+type LightConfigUpdate struct {
+	Startup *StartupUpdate `json:"startup,omitempty"`
+}
+
+// StartupUpdate changes the power-on behaviour of a light. CustomSettings
+// are only applied when Mode is StartupCustom.
+type StartupUpdate struct {
+	Mode           string           `json:"mode,omitempty"`
+	CustomSettings *StartupSettings `json:"customsettings,omitempty"`
+}
+
+// StartupSettings is the state a light powers on with in custom mode.
+type StartupSettings struct {
+	Bri *int      `json:"bri,omitempty"`
+	XY  []float64 `json:"xy,omitempty"`
+	CT  *int      `json:"ct,omitempty"`
+}
+
+// Validate checks the update against the ranges documented by the Hue
+// API. When l is provided, startup changes are rejected for lights that
+// don't report a configurable startup behaviour.
+func (u *LightConfigUpdate) Validate(l *Light) error {
+	v := &ValidationError{}
+
+	if u == nil {
+		v.add("config", nil, "is required")
+		return v
+	}
+
+	s := u.Startup
+	if s == nil {
+		return nil
+	}
+
+	if l != nil && l.Config.Startup == nil {
+		v.add("startup", nil, "light doesn't support configuring startup behaviour")
+	}
+
+	switch s.Mode {
+	case StartupSafety, StartupPowerfail, StartupLastOnState, StartupCustom:
+	case "":
+		if s.CustomSettings == nil {
+			v.add("startup.mode", s.Mode, "can't be empty")
+		}
+	default:
+		v.add("startup.mode", s.Mode, "must be one of safety, powerfail, lastonstate or custom")
+	}
+
+	if cs := s.CustomSettings; cs != nil {
+		v.conflict(s.Mode != "" && s.Mode != StartupCustom, "startup.customsettings", "can only be set with the custom mode")
+
+		v.check(cs.Bri, "startup.customsettings.bri", 1, 254)
+
+		minCT, maxCT := MinCT, MaxCT
+		if l != nil && l.Capabilities.Control.CT != nil {
+			minCT, maxCT = int(l.Capabilities.Control.CT.Min), int(l.Capabilities.Control.CT.Max)
+		}
+		v.check(cs.CT, "startup.customsettings.ct", minCT, maxCT)
+
+		if cs.XY != nil {
+			if len(cs.XY) != 2 || cs.XY[0] < 0 || cs.XY[0] > 1 || cs.XY[1] < 0 || cs.XY[1] > 1 {
+				v.add("startup.customsettings.xy", cs.XY, "must be two coordinates between 0 and 1")
+			}
+		}
+
+		v.conflict(cs.XY != nil && cs.CT != nil, "startup.customsettings", "only one of xy or ct can be set at once")
+	}
+
+	return v.err()
+}
@@ -0,0 +1,125 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		wantErr bool
+	}{
+		{name: "valid", arg: "Desk"},
+		{name: "longest", arg: "abcdefghijklmnopqrstuvwxyz012345"},
+		{name: "multibyte", arg: "Küche Decke"},
+		{name: "empty", arg: "", wantErr: true},
+		{name: "blank", arg: "   ", wantErr: true},
+		{name: "too long", arg: "abcdefghijklmnopqrstuvwxyz0123456", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateName(tt.arg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLightConfigUpdate_Validate(t *testing.T) {
+	bri := 200
+	ct := 366
+	warm := 454
+
+	configurable := &Light{
+		Config: LightConfig{
+			Startup: &LightStartup{Mode: StartupSafety, Configured: true},
+		},
+		Capabilities: LightCapabilities{
+			Control: LightControl{CT: &CTRange{Min: 153, Max: 400}},
+		},
+	}
+	fixed := &Light{}
+
+	tests := []struct {
+		name       string
+		update     *LightConfigUpdate
+		light      *Light
+		wantFields []string
+	}{
+		{
+			name:   "empty",
+			update: &LightConfigUpdate{},
+			light:  fixed,
+		},
+		{
+			name:   "mode",
+			update: &LightConfigUpdate{Startup: &StartupUpdate{Mode: StartupLastOnState}},
+			light:  configurable,
+		},
+		{
+			name: "custom settings",
+			update: &LightConfigUpdate{Startup: &StartupUpdate{
+				Mode:           StartupCustom,
+				CustomSettings: &StartupSettings{Bri: &bri, CT: &ct},
+			}},
+			light: configurable,
+		},
+		{
+			name:       "nil",
+			light:      configurable,
+			wantFields: []string{"config"},
+		},
+		{
+			name:   "without light",
+			update: &LightConfigUpdate{Startup: &StartupUpdate{Mode: StartupPowerfail}},
+		},
+		{
+			name:       "unsupported",
+			update:     &LightConfigUpdate{Startup: &StartupUpdate{Mode: StartupPowerfail}},
+			light:      fixed,
+			wantFields: []string{"startup"},
+		},
+		{
+			name:       "unknown mode",
+			update:     &LightConfigUpdate{Startup: &StartupUpdate{Mode: "on"}},
+			light:      configurable,
+			wantFields: []string{"startup.mode"},
+		},
+		{
+			name: "custom settings on another mode",
+			update: &LightConfigUpdate{Startup: &StartupUpdate{
+				Mode:           StartupSafety,
+				CustomSettings: &StartupSettings{Bri: &bri},
+			}},
+			light:      configurable,
+			wantFields: []string{"startup.customsettings"},
+		},
+		{
+			name: "custom settings outside light range",
+			update: &LightConfigUpdate{Startup: &StartupUpdate{
+				Mode:           StartupCustom,
+				CustomSettings: &StartupSettings{CT: &warm, XY: []float64{0.3, 1.2}},
+			}},
+			light:      configurable,
+			wantFields: []string{"startup.customsettings.ct", "startup.customsettings.xy", "startup.customsettings"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.update.Validate(tt.light)
+
+			var got []string
+			if err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("LightConfigUpdate.Validate() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package hue
+
+import (
+	"encoding/json"
+
+	"github.com/ninnemana/huego/color"
+)
+
+// Light is a single light registered on the bridge.
+// GET /api/<username>/lights/<id>
+type Light struct {
+	// ID is the bridge local identifier of the light, it is taken from
+	// the request path or the key of the lights object rather than the
+	// light body.
+	ID int `json:"-"`
+
+	State            LightState        `json:"state"`
+	SWUpdate         SWUpdate          `json:"swupdate"`
+	Type             string            `json:"type"`
+	Name             string            `json:"name"`
+	ModelID          string            `json:"modelid"`
+	ManufacturerName string            `json:"manufacturername"`
+	ProductName      string            `json:"productname,omitempty"`
+	Capabilities     LightCapabilities `json:"capabilities"`
+	Config           LightConfig       `json:"config"`
+	UniqueID         string            `json:"uniqueid"`
+	SWVersion        string            `json:"swversion"`
+	SWConfigID       string            `json:"swconfigid,omitempty"`
+	ProductID        string            `json:"productid,omitempty"`
+
+	// Extra holds any attribute the bridge reported that isn't modelled
+	// above, it is written back out when the light is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LightState is the current state of a light as reported by the bridge.
+type LightState struct {
+	On        bool      `json:"on"`
+	Bri       uint8     `json:"bri,omitempty"`
+	Hue       uint16    `json:"hue,omitempty"`
+	Sat       uint8     `json:"sat,omitempty"`
+	Effect    string    `json:"effect,omitempty"`
+	XY        []float64 `json:"xy,omitempty"`
+	CT        uint16    `json:"ct,omitempty"`
+	Alert     string    `json:"alert,omitempty"`
+	ColorMode string    `json:"colormode,omitempty"`
+	Mode      string    `json:"mode,omitempty"`
+	Reachable bool      `json:"reachable"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LightCapabilities describes what a light is able to render.
+type LightCapabilities struct {
+	Certified bool           `json:"certified"`
+	Control   LightControl   `json:"control"`
+	Streaming LightStreaming `json:"streaming"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LightControl holds the dimming and color limits of a light. Color
+// fields are only reported by lights that support them.
+type LightControl struct {
+	MinDimLevel    int          `json:"mindimlevel,omitempty"`
+	MaxLumen       int          `json:"maxlumen,omitempty"`
+	ColorGamutType string       `json:"colorgamuttype,omitempty"`
+	ColorGamut     [][2]float64 `json:"colorgamut,omitempty"`
+	CT             *CTRange     `json:"ct,omitempty"`
+}
+
+// CTRange is the supported color temperature range of a light in mired.
+type CTRange struct {
+	Min uint16 `json:"min"`
+	Max uint16 `json:"max"`
+}
+
+// LightStreaming reports the entertainment capabilities of a light.
+type LightStreaming struct {
+	Renderer bool `json:"renderer"`
+	Proxy    bool `json:"proxy"`
+}
+
+// LightConfig holds the configuration attributes of a light.
+type LightConfig struct {
+	Archetype string        `json:"archetype,omitempty"`
+	Function  string        `json:"function,omitempty"`
+	Direction string        `json:"direction,omitempty"`
+	Startup   *LightStartup `json:"startup,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LightStartup is the power-on behaviour of a light, it is only reported
+// by lights that support configuring it.
+type LightStartup struct {
+	Mode           string           `json:"mode"`
+	Configured     bool             `json:"configured"`
+	CustomSettings *StartupSettings `json:"customsettings,omitempty"`
+}
+
+// SWUpdate is the firmware update status of a light.
+type SWUpdate struct {
+	State       string `json:"state"`
+	LastInstall string `json:"lastinstall,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Gamut returns the color gamut of the light, preferring the gamut it
+// reports in its capabilities over the gamut known for its model.
+func (l *Light) Gamut() (color.Gamut, bool) {
+	if g, ok := l.Capabilities.Gamut(); ok {
+		return g, true
+	}
+
+	return color.GamutForModel(l.ModelID)
+}
+
+// StateBuilder returns a StateBuilder that validates against the
+// capabilities of the light and clamps colors into its gamut.
+func (l *Light) StateBuilder() *StateBuilder {
+	b := NewStateBuilder(&l.Capabilities)
+	if g, ok := l.Gamut(); ok {
+		b.gamut = &g
+	}
+
+	return b
+}
+
+// Color returns the current color of the light in sRGB, for display
+// purposes. Lights that are off are reported as black.
+func (s LightState) Color() color.RGB {
+	if !s.On {
+		return color.RGB{}
+	}
+
+	bri := float64(s.Bri) / 254
+	if s.Bri == 0 {
+		bri = 1
+	}
+
+	switch s.ColorMode {
+	case "xy":
+		if len(s.XY) == 2 {
+			return color.XY{X: s.XY[0], Y: s.XY[1]}.RGB(bri)
+		}
+	case "ct":
+		return color.Mired(s.CT).XY().RGB(bri)
+	case "hs":
+		return color.FromHueSat(int(s.Hue), int(s.Sat), int(s.Bri)).RGB()
+	}
+
+	return color.WhitePoint.RGB(bri)
+}
+
+// Gamut returns the color gamut reported by the light, either as the
+// corners of the triangle or the gamut type.
+func (c LightCapabilities) Gamut() (color.Gamut, bool) {
+	if g, ok := color.GamutFromPoints(c.Control.ColorGamut); ok {
+		return g, true
+	}
+
+	return color.GamutForType(c.Control.ColorGamutType)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *Light) UnmarshalJSON(data []byte) error {
+	type light Light
+	extra, err := decodeExtra(data, (*light)(l))
+	if err != nil {
+		return err
+	}
+
+	l.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l Light) MarshalJSON() ([]byte, error) {
+	type light Light
+	return encodeExtra(light(l), l.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *LightState) UnmarshalJSON(data []byte) error {
+	type state LightState
+	extra, err := decodeExtra(data, (*state)(s))
+	if err != nil {
+		return err
+	}
+
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s LightState) MarshalJSON() ([]byte, error) {
+	type state LightState
+	return encodeExtra(state(s), s.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *LightCapabilities) UnmarshalJSON(data []byte) error {
+	type capabilities LightCapabilities
+	extra, err := decodeExtra(data, (*capabilities)(c))
+	if err != nil {
+		return err
+	}
+
+	c.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c LightCapabilities) MarshalJSON() ([]byte, error) {
+	type capabilities LightCapabilities
+	return encodeExtra(capabilities(c), c.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *LightConfig) UnmarshalJSON(data []byte) error {
+	type config LightConfig
+	extra, err := decodeExtra(data, (*config)(c))
+	if err != nil {
+		return err
+	}
+
+	c.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c LightConfig) MarshalJSON() ([]byte, error) {
+	type config LightConfig
+	return encodeExtra(config(c), c.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *SWUpdate) UnmarshalJSON(data []byte) error {
+	type update SWUpdate
+	extra, err := decodeExtra(data, (*update)(s))
+	if err != nil {
+		return err
+	}
+
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s SWUpdate) MarshalJSON() ([]byte, error) {
+	type update SWUpdate
+	return encodeExtra(update(s), s.Extra)
+}
@@ -0,0 +1,114 @@
+package hue
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const colorLamp = `{
+	"state": {"on": true, "bri": 144, "hue": 13088, "sat": 212, "effect": "none", "xy": [0.5128, 0.4147], "ct": 467, "alert": "none", "colormode": "xy", "mode": "homeautomation", "reachable": true},
+	"swupdate": {"state": "noupdates", "lastinstall": "2018-01-02T19:24:20"},
+	"type": "Extended color light",
+	"name": "Hue color lamp 7",
+	"modelid": "LCT007",
+	"manufacturername": "Philips",
+	"productname": "Hue color lamp",
+	"capabilities": {
+		"certified": true,
+		"control": {"mindimlevel": 5000, "maxlumen": 600, "colorgamuttype": "B", "colorgamut": [[0.675, 0.322], [0.409, 0.518], [0.167, 0.04]], "ct": {"min": 153, "max": 500}},
+		"streaming": {"renderer": true, "proxy": false}
+	},
+	"config": {"archetype": "sultanbulb", "function": "mixed", "direction": "omnidirectional", "startup": {"mode": "safety", "configured": true}},
+	"uniqueid": "00:17:88:01:00:bd:c7:b9-0b",
+	"swversion": "5.105.0.21169",
+	"pointsymbol": {"1": "none"}
+}`
+
+func TestLight_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		check   func(*testing.T, Light)
+		wantErr bool
+	}{
+		{
+			name: "extended color light",
+			data: colorLamp,
+			check: func(t *testing.T, l Light) {
+				if !l.State.On || l.State.Bri != 144 || l.State.ColorMode != "xy" {
+					t.Errorf("unexpected state %+v", l.State)
+				}
+				if l.Capabilities.Control.CT == nil || l.Capabilities.Control.CT.Max != 500 {
+					t.Errorf("unexpected ct range %+v", l.Capabilities.Control.CT)
+				}
+				if l.Capabilities.Control.ColorGamut[2] != [2]float64{0.167, 0.04} {
+					t.Errorf("unexpected color gamut %v", l.Capabilities.Control.ColorGamut)
+				}
+				if l.Config.Startup == nil || l.Config.Startup.Mode != "safety" {
+					t.Errorf("unexpected startup %+v", l.Config.Startup)
+				}
+				if _, ok := l.Extra["pointsymbol"]; !ok || len(l.Extra) != 1 {
+					t.Errorf("expected only 'pointsymbol' in extra, got %v", l.Extra)
+				}
+				if l.State.Extra != nil {
+					t.Errorf("expected no extra state attributes, got %v", l.State.Extra)
+				}
+			},
+		},
+		{
+			name: "missing fields",
+			data: `{"state": {"on": false, "reachable": true}, "name": "Hue white"}`,
+			check: func(t *testing.T, l Light) {
+				if l.Name != "Hue white" || l.State.On || l.State.XY != nil {
+					t.Errorf("unexpected light %+v", l)
+				}
+				if l.Config.Startup != nil {
+					t.Errorf("expected no startup, got %+v", l.Config.Startup)
+				}
+			},
+		},
+		{
+			name:    "invalid",
+			data:    `{"state": []}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l Light
+			err := json.Unmarshal([]byte(tt.data), &l)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Light.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.check != nil {
+				tt.check(t, l)
+			}
+		})
+	}
+}
+
+func TestLight_MarshalJSON(t *testing.T) {
+	var l Light
+	if err := json.Unmarshal([]byte(colorLamp), &l); err != nil {
+		t.Fatalf("failed to decode light: %v", err)
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		t.Fatalf("Light.MarshalJSON() error = %v", err)
+	}
+
+	var got, want map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(colorLamp), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Light.MarshalJSON() = %s, want %s", data, colorLamp)
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/ninnemana/huego"
 )
@@ -10,7 +11,7 @@ func (c *client) AllBridges(ctx context.Context, q interface{}) ([]interface{},
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) CreateUser(interface{}) (interface{}, error) {
+func (c *client) CreateUser(ctx context.Context, user interface{}) (interface{}, error) {
 	return nil, hue.ErrNotImplemented
 }
 
@@ -18,14 +19,18 @@ func (c *client) GetConfig(ctx context.Context) (interface{}, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) ModifyConfig(interface{}) (interface{}, error) {
+func (c *client) TimeZone(ctx context.Context) (*time.Location, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) Unwhitelist(string) error {
+func (c *client) ModifyConfig(ctx context.Context, config interface{}) (interface{}, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) Unwhitelist(ctx context.Context, user string) error {
 	return hue.ErrNotImplemented
 }
 
-func (c *client) GetFullState(ctx context.Context) (interface{}, error) {
+func (c *client) GetFullState(ctx context.Context) (*hue.FullState, error) {
 	return nil, hue.ErrNotImplemented
 }
@@ -1,9 +1,20 @@
 package client
 
-import "cloud.google.com/go/trace"
+import (
+	"sync"
+
+	"cloud.google.com/go/trace"
+	"github.com/ninnemana/huego"
+)
+
+var _ hue.Client = &client{}
 
 type client struct {
 	trace *trace.Client
+
+	mu       sync.Mutex
+	scans    []hue.ScanResult
+	searches [][]string
 }
 
 func New() (*client, error) {
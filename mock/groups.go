@@ -1,27 +1,44 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
+	"time"
 
-func (c *client) AllGroups() ([]interface{}, error) {
+	"github.com/ninnemana/huego"
+)
+
+func (c *client) AllGroups(ctx context.Context) ([]hue.Group, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) CreateGroup(ctx context.Context, group *hue.Group) (int, error) {
+	return 0, hue.ErrNotImplemented
+}
+
+func (c *client) GetGroup(ctx context.Context, id int) (*hue.Group, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) SaveGroup(ctx context.Context, id int, group *hue.Group) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) CreateGroup(interface{}) (interface{}, error) {
+func (c *client) SetGroupState(ctx context.Context, id int, state *hue.StateUpdate) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) GetGroup(string) (interface{}, error) {
+func (c *client) RecallScene(ctx context.Context, id int, scene string, transition time.Duration) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) SaveGroup(string, interface{}) (interface{}, error) {
+func (c *client) ToggleGroup(ctx context.Context, id int) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) SetGroupState(string, interface{}) (interface{}, error) {
+func (c *client) SetAllLights(ctx context.Context, state *hue.StateUpdate) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) DeleteGroup(string) error {
+func (c *client) DeleteGroup(ctx context.Context, id int) error {
 	return hue.ErrNotImplemented
 }
@@ -4,36 +4,107 @@ import (
 	"context"
 
 	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
 )
 
-func (c *client) AllLights(ctx context.Context) ([]interface{}, error) {
+func (c *client) AllLights(ctx context.Context) ([]hue.Light, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) NewLights(ctx context.Context) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+// ScriptScan queues the results NewLights reports for the next searches,
+// one per call, repeating the last one once the script runs out.
+func (c *client) ScriptScan(steps ...hue.ScanResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.scans = append(c.scans, steps...)
+}
+
+// Searches returns the serial numbers each search was started with.
+func (c *client) Searches() [][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.searches
+}
+
+func (c *client) NewLights(ctx context.Context) (*hue.ScanResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.scans) == 0 {
+		return nil, hue.ErrNotImplemented
+	}
+
+	scan := c.scans[0]
+	if len(c.scans) > 1 {
+		c.scans = c.scans[1:]
+	}
+
+	return &scan, nil
 }
 
 func (c *client) SearchLights(ctx context.Context, deviceIDs []string) error {
-	return hue.ErrNotImplemented
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.scans) == 0 {
+		return hue.ErrNotImplemented
+	}
+
+	c.searches = append(c.searches, deviceIDs)
+	return nil
+}
+
+// ScanForLights starts a search and steps through the scripted results
+// until one is no longer active, without waiting between them.
+func (c *client) ScanForLights(ctx context.Context, serials []string) ([]hue.NewLight, error) {
+	if err := c.SearchLights(ctx, serials); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	steps := len(c.scans)
+	c.mu.Unlock()
+
+	for i := 0; i < steps; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		scan, err := c.NewLights(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !scan.Active() {
+			return scan.Lights, nil
+		}
+	}
+
+	return nil, errors.New("scripted scan never completes")
+}
+
+func (c *client) GetLight(ctx context.Context, id int) (*hue.Light, error) {
+	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) GetLight(ctx context.Context, id int) (interface{}, error) {
+func (c *client) RenameLight(ctx context.Context, id int, name string) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) RenameLight(string, string) (interface{}, error) {
+func (c *client) SetLightConfig(ctx context.Context, id int, config *hue.LightConfigUpdate) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) LightState(ctx context.Context, id int, state interface{}) (interface{}, error) {
+func (c *client) LightState(ctx context.Context, id int, state *hue.StateUpdate) (*hue.StateResult, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) Toggle(ctx context.Context, id int) (interface{}, error) {
+func (c *client) Toggle(ctx context.Context, id int) (*hue.StateResult, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) DeleteLight(string) error {
+func (c *client) DeleteLight(ctx context.Context, id string) error {
 	return hue.ErrNotImplemented
 }
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+func Test_client_ScriptScan(t *testing.T) {
+	ctx := context.Background()
+	found := []hue.NewLight{{ID: 7, Name: "Hue color lamp 7"}}
+
+	c, _ := New()
+	if _, err := c.NewLights(ctx); err != hue.ErrNotImplemented {
+		t.Errorf("client.NewLights() error = %v, want %v before a scan is scripted", err, hue.ErrNotImplemented)
+	}
+	if err := c.SearchLights(ctx, nil); err != hue.ErrNotImplemented {
+		t.Errorf("client.SearchLights() error = %v, want %v before a scan is scripted", err, hue.ErrNotImplemented)
+	}
+
+	c.ScriptScan(
+		hue.ScanResult{LastScan: hue.ScanActive},
+		hue.ScanResult{LastScan: hue.ScanActive},
+		hue.ScanResult{LastScan: "2020-03-27T12:00:00", Lights: found},
+	)
+
+	got, err := c.ScanForLights(ctx, []string{"ABC123"})
+	if err != nil {
+		t.Fatalf("client.ScanForLights() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, found) {
+		t.Errorf("client.ScanForLights() = %+v, want %+v", got, found)
+	}
+
+	// the last result is repeated once the script runs out
+	scan, err := c.NewLights(ctx)
+	want := &hue.ScanResult{LastScan: "2020-03-27T12:00:00", Lights: found}
+	if err != nil || !reflect.DeepEqual(scan, want) {
+		t.Errorf("client.NewLights() = %+v, %v, want %+v", scan, err, want)
+	}
+
+	if err := c.SearchLights(ctx, []string{"DEF456"}); err != nil {
+		t.Fatalf("client.SearchLights() error = %v", err)
+	}
+	if searches, want := c.Searches(), [][]string{{"ABC123"}, {"DEF456"}}; !reflect.DeepEqual(searches, want) {
+		t.Errorf("client.Searches() = %v, want %v", searches, want)
+	}
+}
+
+func Test_client_ScanForLights(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		steps   []hue.ScanResult
+		want    []hue.NewLight
+		wantErr bool
+	}{
+		{
+			name:  "nothing found",
+			ctx:   context.Background(),
+			steps: []hue.ScanResult{{LastScan: hue.ScanActive}, {LastScan: "2020-03-27T12:00:00"}},
+		},
+		{
+			name:    "never completes",
+			ctx:     context.Background(),
+			steps:   []hue.ScanResult{{LastScan: hue.ScanActive}},
+			wantErr: true,
+		},
+		{
+			name:    "cancelled",
+			ctx:     cancelled,
+			steps:   []hue.ScanResult{{LastScan: "2020-03-27T12:00:00"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := New()
+			c.ScriptScan(tt.steps...)
+
+			got, err := c.ScanForLights(tt.ctx, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("client.ScanForLights() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("client.ScanForLights() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
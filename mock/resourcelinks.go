@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ninnemana/huego"
+)
+
+func (c *client) AllResourceLinks(ctx context.Context) ([]hue.ResourceLink, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) GetResourceLink(ctx context.Context, id int) (*hue.ResourceLink, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) CreateResourceLink(ctx context.Context, link *hue.ResourceLink) (int, error) {
+	return 0, hue.ErrNotImplemented
+}
+
+func (c *client) UpdateResourceLink(ctx context.Context, id int, link *hue.ResourceLink) (*hue.Result, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) DeleteResourceLink(ctx context.Context, id int) error {
+	return hue.ErrNotImplemented
+}
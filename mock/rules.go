@@ -1,23 +1,27 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
 
-func (c *client) AllRules() ([]interface{}, error) {
+	"github.com/ninnemana/huego"
+)
+
+func (c *client) AllRules(ctx context.Context) ([]hue.Rule, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) GetRule(string) (interface{}, error) {
+func (c *client) GetRule(ctx context.Context, id int) (*hue.Rule, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) CreateRule(interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+func (c *client) CreateRule(ctx context.Context, rule *hue.Rule) (int, error) {
+	return 0, hue.ErrNotImplemented
 }
 
-func (c *client) UpdateRule(string, interface{}) (interface{}, error) {
+func (c *client) UpdateRule(ctx context.Context, id int, rule *hue.Rule) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) DeleteRule(string) error {
+func (c *client) DeleteRule(ctx context.Context, id int) error {
 	return hue.ErrNotImplemented
 }
@@ -1,23 +1,35 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
 
-func (c *client) AllScenes() ([]interface{}, error) {
+	"github.com/ninnemana/huego"
+)
+
+func (c *client) AllScenes(ctx context.Context) ([]hue.Scene, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) GetScene(string) (interface{}, error) {
+func (c *client) GetScene(ctx context.Context, id string) (*hue.Scene, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) CreateScene(interface{}) (interface{}, error) {
+func (c *client) CreateScene(ctx context.Context, scene *hue.Scene) (string, error) {
+	return "", hue.ErrNotImplemented
+}
+
+func (c *client) SetScene(ctx context.Context, id string, scene *hue.Scene) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) SetScene(string, interface{}) (interface{}, error) {
+func (c *client) CaptureScene(ctx context.Context, scene *hue.Scene) (string, error) {
+	return "", hue.ErrNotImplemented
+}
+
+func (c *client) SetSceneLightState(ctx context.Context, id string, light int, state *hue.StateUpdate) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) DeleteScene(string) error {
+func (c *client) DeleteScene(ctx context.Context, id string) error {
 	return hue.ErrNotImplemented
 }
@@ -1,23 +1,27 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
 
-func (c *client) AllSchedules() ([]interface{}, error) {
+	"github.com/ninnemana/huego"
+)
+
+func (c *client) AllSchedules(ctx context.Context) ([]hue.Schedule, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) CreateSchedule(interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+func (c *client) CreateSchedule(ctx context.Context, schedule *hue.Schedule) (int, error) {
+	return 0, hue.ErrNotImplemented
 }
 
-func (c *client) GetSchedule(string) (interface{}, error) {
+func (c *client) GetSchedule(ctx context.Context, id int) (*hue.Schedule, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) SetSchedule(string, interface{}) (interface{}, error) {
+func (c *client) SetSchedule(ctx context.Context, id int, schedule *hue.Schedule) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) DeleteSchedule(string) error {
+func (c *client) DeleteSchedule(ctx context.Context, id int) error {
 	return hue.ErrNotImplemented
 }
@@ -1,35 +1,47 @@
 package client
 
-import "github.com/ninnemana/huego"
+import (
+	"context"
 
-func (c *client) AllSensors() ([]interface{}, error) {
+	"github.com/ninnemana/huego"
+)
+
+func (c *client) AllSensors(ctx context.Context) ([]hue.Sensor, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) CreateSensor(interface{}) (interface{}, error) {
-	return nil, hue.ErrNotImplemented
+func (c *client) CreateSensor(ctx context.Context, sensor *hue.Sensor) (int, error) {
+	return 0, hue.ErrNotImplemented
 }
 
-func (c *client) SearchSensors() error {
+func (c *client) SearchSensors(ctx context.Context) error {
 	return hue.ErrNotImplemented
 }
 
-func (c *client) NewSensors() ([]interface{}, error) {
+func (c *client) NewSensors(ctx context.Context) (*hue.SensorScanResult, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) PairSensors(ctx context.Context) ([]hue.SensorDevice, error) {
+	return nil, hue.ErrNotImplemented
+}
+
+func (c *client) GetSensor(ctx context.Context, id int) (*hue.Sensor, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) GetSensor(string) (interface{}, error) {
+func (c *client) RenameSensor(ctx context.Context, id int, name string) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) SetSensor(string, interface{}) (interface{}, error) {
+func (c *client) SetSensorConfig(ctx context.Context, id int, config *hue.SensorConfigUpdate) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) RenameSensor(string, string) (interface{}, error) {
+func (c *client) SetSensorState(ctx context.Context, id int, state hue.SensorState) (*hue.Result, error) {
 	return nil, hue.ErrNotImplemented
 }
 
-func (c *client) DeleteSensor(string) error {
+func (c *client) DeleteSensor(ctx context.Context, id int) error {
 	return hue.ErrNotImplemented
 }
@@ -0,0 +1,200 @@
+package hue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// MaxResourceLinkLinks is the most resources the bridge accepts in a
+// single resourcelink.
+const MaxResourceLinkLinks = 64
+
+// ResourceLinkType is the only type of resourcelink.
+const ResourceLinkType = "Link"
+
+// LinkKind is the kind of resource a link refers to.
+type LinkKind string
+
+// Kinds of resource that can be linked.
+const (
+	LinkLights        LinkKind = "lights"
+	LinkGroups        LinkKind = "groups"
+	LinkSensors       LinkKind = "sensors"
+	LinkScenes        LinkKind = "scenes"
+	LinkSchedules     LinkKind = "schedules"
+	LinkRules         LinkKind = "rules"
+	LinkResourceLinks LinkKind = "resourcelinks"
+)
+
+var linkKinds = map[LinkKind]bool{
+	LinkLights:        true,
+	LinkGroups:        true,
+	LinkSensors:       true,
+	LinkScenes:        true,
+	LinkSchedules:     true,
+	LinkRules:         true,
+	LinkResourceLinks: true,
+}
+
+// Link refers to a resource from a resourcelink, it is encoded as the
+// address of the resource such as /scenes/AB34EF5.
+type Link struct {
+	Kind LinkKind
+	ID   string
+}
+
+// LightLink, GroupLink, SensorLink, SceneLink, ScheduleLink, RuleLink and
+// ResourceLinkLink return links to the resource with the identifier.
+func LightLink(id int) Link        { return Link{LinkLights, strconv.Itoa(id)} }
+func GroupLink(id int) Link        { return Link{LinkGroups, strconv.Itoa(id)} }
+func SensorLink(id int) Link       { return Link{LinkSensors, strconv.Itoa(id)} }
+func SceneLink(id string) Link     { return Link{LinkScenes, id} }
+func ScheduleLink(id int) Link     { return Link{LinkSchedules, strconv.Itoa(id)} }
+func RuleLink(id int) Link         { return Link{LinkRules, strconv.Itoa(id)} }
+func ResourceLinkLink(id int) Link { return Link{LinkResourceLinks, strconv.Itoa(id)} }
+
+// ParseLink parses the address of a linked resource.
+func ParseLink(address string) (Link, error) {
+	parts := strings.Split(address, "/")
+	if len(parts) != 3 || parts[0] != "" || parts[1] == "" || parts[2] == "" {
+		return Link{}, errors.Errorf("invalid link '%s', it must be written as /<resource>/<id>", address)
+	}
+
+	return Link{Kind: LinkKind(parts[1]), ID: parts[2]}, nil
+}
+
+func (l Link) String() string {
+	return fmt.Sprintf("/%s/%s", l.Kind, l.ID)
+}
+
+// Int returns the identifier of the linked resource as an int, every
+// kind of resource but scenes has one.
+func (l Link) Int() (int, bool) {
+	id, err := strconv.Atoi(l.ID)
+	return id, err == nil && l.Kind != LinkScenes
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l Link) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *Link) UnmarshalJSON(data []byte) error {
+	var address string
+	if err := json.Unmarshal(data, &address); err != nil {
+		return err
+	}
+
+	link, err := ParseLink(address)
+	if err != nil {
+		return err
+	}
+
+	*l = link
+	return nil
+}
+
+// ResourceLink bundles the resources belonging to one feature of an
+// application, such as the rules, scenes and sensors of a wake up
+// routine.
+// GET /api/<username>/resourcelinks/<id>
+type ResourceLink struct {
+	// ID is the identifier of the resourcelink on the bridge, it isn't
+	// part of its attributes.
+	ID int `json:"-"`
+
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type,omitempty"`
+
+	// ClassID is chosen by the application to tell resourcelinks with
+	// the same purpose apart from others, such as one per kind of
+	// routine.
+	ClassID int `json:"classid"`
+
+	Owner string `json:"owner,omitempty"`
+
+	// Recycle has the bridge delete the resourcelink once no other
+	// resourcelink refers to it.
+	Recycle bool `json:"recycle"`
+
+	Links []Link `json:"links"`
+
+	// Extra holds any attribute the bridge reported that isn't modelled
+	// above, it is written back out when the resourcelink is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Has reports whether the resourcelink refers to the resource.
+func (r *ResourceLink) Has(l Link) bool {
+	for _, link := range r.Links {
+		if link == l {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks the attributes of the resourcelink that can be written
+// to the bridge.
+func (r *ResourceLink) Validate() error {
+	v := &ValidationError{}
+
+	if r.Name == "" {
+		v.add("name", r.Name, "can't be empty")
+	} else if utf8.RuneCountInString(r.Name) > MaxNameLength {
+		v.add("name", r.Name, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	}
+
+	if utf8.RuneCountInString(r.Description) > maxDescriptionLength {
+		v.add("description", r.Description, fmt.Sprintf("must be at most %d characters", maxDescriptionLength))
+	}
+
+	v.check(&r.ClassID, "classid", 1, 65535)
+
+	if len(r.Links) > MaxResourceLinkLinks {
+		v.add("links", len(r.Links), fmt.Sprintf("must be at most %d links", MaxResourceLinkLinks))
+	}
+
+	seen := make(map[Link]bool, len(r.Links))
+	for i, l := range r.Links {
+		field := fmt.Sprintf("links.%d", i)
+		switch {
+		case !linkKinds[l.Kind]:
+			v.add(field, l.String(), "must refer to lights, groups, sensors, scenes, schedules, rules or resourcelinks")
+		case l.ID == "":
+			v.add(field, l.String(), "needs the identifier of the resource")
+		case seen[l]:
+			v.add(field, l.String(), "is listed more than once")
+		}
+
+		seen[l] = true
+	}
+
+	return v.err()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *ResourceLink) UnmarshalJSON(data []byte) error {
+	type resourceLink ResourceLink
+	extra, err := decodeExtra(data, (*resourceLink)(r))
+	if err != nil {
+		return err
+	}
+
+	r.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r ResourceLink) MarshalJSON() ([]byte, error) {
+	type resourceLink ResourceLink
+	return encodeExtra(resourceLink(r), r.Extra)
+}
@@ -0,0 +1,121 @@
+package hue
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLink(t *testing.T) {
+	tests := []struct {
+		address string
+		want    Link
+		wantErr bool
+	}{
+		{address: "/scenes/AB34EF5", want: SceneLink("AB34EF5")},
+		{address: "/rules/3", want: RuleLink(3)},
+		{address: "/groups/", wantErr: true},
+		{address: "sensors/2", wantErr: true},
+		{address: "/sensors/2/state", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := ParseLink(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseLink() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceLink_Validate(t *testing.T) {
+	tooMany := make([]Link, MaxResourceLinkLinks+1)
+	for i := range tooMany {
+		tooMany[i] = SensorLink(i + 1)
+	}
+
+	tests := []struct {
+		name       string
+		link       ResourceLink
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			link: ResourceLink{Name: "Wake up", ClassID: 1, Links: []Link{RuleLink(1), SceneLink("AB34EF5")}},
+		},
+		{
+			name:       "missing name and class",
+			link:       ResourceLink{Links: []Link{}},
+			wantFields: []string{"name", "classid"},
+		},
+		{
+			name:       "unknown kind and duplicate",
+			link:       ResourceLink{Name: "Wake up", ClassID: 1, Links: []Link{{Kind: "config", ID: "1"}, RuleLink(1), RuleLink(1)}},
+			wantFields: []string{"links.0", "links.2"},
+		},
+		{
+			name:       "too many links",
+			link:       ResourceLink{Name: "Wake up", ClassID: 1, Links: tooMany},
+			wantFields: []string{"links"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := tt.link.Validate(); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("ResourceLink.Validate() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestResourceLink_JSON(t *testing.T) {
+	data := `{"name":"Wake up","description":"Morning routine","type":"Link","classid":10010,"owner":"abc","recycle":false,` +
+		`"links":["/schedules/2","/scenes/AB34EF5","/rules/4"],"flags":1}`
+
+	var l ResourceLink
+	if err := json.Unmarshal([]byte(data), &l); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := ResourceLink{
+		Name:        "Wake up",
+		Description: "Morning routine",
+		Type:        ResourceLinkType,
+		ClassID:     10010,
+		Owner:       "abc",
+		Links:       []Link{ScheduleLink(2), SceneLink("AB34EF5"), RuleLink(4)},
+		Extra:       map[string]json.RawMessage{"flags": json.RawMessage("1")},
+	}
+	if !reflect.DeepEqual(l, want) {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", l, want)
+	}
+
+	out, err := json.Marshal(l)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var got, wantRaw map[string]interface{}
+	json.Unmarshal(out, &got)
+	json.Unmarshal([]byte(data), &wantRaw)
+	if !reflect.DeepEqual(got, wantRaw) {
+		t.Errorf("json.Marshal() = %s, want %s", out, data)
+	}
+
+	err = json.Unmarshal([]byte(`{"links":["scenes"]}`), &l)
+	if err == nil || !strings.Contains(err.Error(), "invalid link") {
+		t.Errorf("json.Unmarshal() error = %v, want an invalid link", err)
+	}
+}
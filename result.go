@@ -0,0 +1,55 @@
+package hue
+
+import "strings"
+
+// Result is the outcome of a write to the bridge, which reports every
+// attribute it was asked to change separately.
+type Result struct {
+	// Success lists each attribute that was applied, in the order the
+	// bridge reported them.
+	Success []AttributeResult
+
+	// Errors lists each attribute that was rejected.
+	Errors APIErrors
+}
+
+// AttributeResult is a single attribute that was applied by the bridge.
+type AttributeResult struct {
+	// Address is the resource path of the attribute, for example
+	// /lights/1/state/bri.
+	Address string
+
+	// Value is the value of the attribute after the write.
+	Value interface{}
+}
+
+// Value returns the new value of the attribute with the given name, the
+// last element of its address, when it was applied.
+func (r *Result) Value(attr string) (interface{}, bool) {
+	for _, s := range r.Success {
+		if s.Address == attr || strings.HasSuffix(s.Address, "/"+attr) {
+			return s.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Err returns the rejected attributes as an error, or nil when every
+// attribute was applied.
+func (r *Result) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	return r.Errors
+}
+
+// StateResult is the outcome of a state write to a light.
+type StateResult struct {
+	Result
+
+	// Light is the light as it was fetched after the write, it is only
+	// populated by clients created with the WithRefresh option.
+	Light *Light
+}
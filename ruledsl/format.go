@@ -0,0 +1,357 @@
+package ruledsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
+)
+
+// decompile writes the rule as a statement.
+func decompile(b *strings.Builder, r *hue.Rule, res *hue.Resources) error {
+	if r.Name != "" {
+		fmt.Fprintf(b, "rule %s\n", strconv.Quote(r.Name))
+	}
+
+	for i, c := range r.Conditions {
+		s, err := formatCondition(c, res)
+		if err != nil {
+			return errors.Wrapf(err, "condition %d", i)
+		}
+
+		if i == 0 {
+			fmt.Fprintf(b, "when %s\n", s)
+		} else {
+			fmt.Fprintf(b, "  and %s\n", s)
+		}
+	}
+
+	for i, a := range r.Actions {
+		s, err := formatAction(a, res)
+		if err != nil {
+			return errors.Wrapf(err, "action %d", i)
+		}
+
+		if i == 0 {
+			fmt.Fprintf(b, "then %s\n", s)
+		} else {
+			fmt.Fprintf(b, "  and %s\n", s)
+		}
+	}
+
+	return nil
+}
+
+func formatCondition(c hue.Condition, res *hue.Resources) (string, error) {
+	if c.Address == hue.LocalTimeAddress && (c.Operator == hue.OpIn || c.Operator == hue.OpNotIn) {
+		return fmt.Sprintf("time %s %s", c.Operator, strconv.Quote(c.Value)), nil
+	}
+
+	subject := formatAddress(c.Address, res)
+
+	switch c.Operator {
+	case hue.OpEq:
+		return fmt.Sprintf("%s == %s", subject, formatValue(c.Value)), nil
+	case hue.OpGt, hue.OpLt:
+		if _, err := strconv.Atoi(c.Value); err != nil {
+			return "", errors.Errorf("invalid %s value '%s'", c.Operator, c.Value)
+		}
+
+		op := ">"
+		if c.Operator == hue.OpLt {
+			op = "<"
+		}
+		return fmt.Sprintf("%s %s %s", subject, op, c.Value), nil
+	case hue.OpDx:
+		return fmt.Sprintf("%s changed", subject), nil
+	case hue.OpDdx, hue.OpStable, hue.OpNotStable:
+		d, err := parseDurationValue(c.Value)
+		if err != nil {
+			return "", err
+		}
+
+		if c.Operator == hue.OpDdx {
+			return fmt.Sprintf("%s changed %s ago", subject, formatDuration(d)), nil
+		}
+		return fmt.Sprintf("%s %s %s", subject, c.Operator, formatDuration(d)), nil
+	case hue.OpIn, hue.OpNotIn:
+		return fmt.Sprintf("%s %s %s", subject, c.Operator, strconv.Quote(c.Value)), nil
+	default:
+		return "", errors.Errorf("unknown operator '%s'", c.Operator)
+	}
+}
+
+// formatAddress writes the address of a condition as the resource it
+// refers to followed by its attribute, or quoted when it refers to
+// anything else.
+func formatAddress(address string, res *hue.Resources) string {
+	parts := strings.Split(strings.TrimPrefix(address, "/"), "/")
+	if !strings.HasPrefix(address, "/") || len(parts) < 3 {
+		return strconv.Quote(address)
+	}
+
+	var keyword string
+	for k, kind := range kinds {
+		if kind == parts[0] && k != "schedule" {
+			keyword = k
+		}
+	}
+
+	// state attributes are written on their own, any other path needs
+	// at least two attributes so it isn't read as one
+	path := parts[2:]
+	switch {
+	case len(path) == 2 && path[0] == "state":
+		path = path[1:]
+	case len(path) == 1:
+		return strconv.Quote(address)
+	}
+
+	for _, p := range path {
+		if !isIdent(p) {
+			return strconv.Quote(address)
+		}
+	}
+
+	ref, ok := formatRef(res, keyword, parts[1])
+	if keyword == "" || !ok {
+		return strconv.Quote(address)
+	}
+
+	return fmt.Sprintf("%s %s.%s", keyword, ref, strings.Join(path, "."))
+}
+
+// formatRef refers to a resource by its name when no other resource of
+// its kind shares it, by its identifier otherwise. It is false when the
+// identifier can't be written as a reference.
+func formatRef(res *hue.Resources, kind, id string) (string, bool) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return "", false
+	}
+
+	var name string
+	count := map[string]int{}
+	for _, r := range resources(res, kind) {
+		count[r.name]++
+		if r.id == id {
+			name = r.name
+		}
+	}
+
+	if name != "" && count[name] == 1 {
+		return strconv.Quote(name), true
+	}
+
+	return id, true
+}
+
+// formatValue writes the value of an eq condition, unquoted when it reads
+// as a boolean or a number.
+func formatValue(v string) string {
+	if v == "true" || v == "false" {
+		return v
+	}
+
+	if _, err := strconv.Atoi(v); err == nil {
+		return v
+	}
+
+	return strconv.Quote(v)
+}
+
+func formatAction(a hue.RuleAction, res *hue.Resources) (string, error) {
+	if a.Method == http.MethodPut {
+		if s, ok := formatKnownAction(a, res); ok {
+			return s, nil
+		}
+	}
+
+	switch a.Method {
+	case http.MethodPut, http.MethodPost, http.MethodDelete:
+	default:
+		return "", errors.Errorf("unknown method '%s'", a.Method)
+	}
+
+	s := fmt.Sprintf("%s %s", strings.ToLower(a.Method), strconv.Quote(a.Address))
+	if len(a.Body) > 0 {
+		body, err := json.Marshal(a.Body)
+		if err != nil {
+			return "", err
+		}
+
+		s += " " + string(body)
+	}
+
+	return s, nil
+}
+
+// formatKnownAction writes the PUT actions the language has a statement
+// for, it is false for any other action.
+func formatKnownAction(a hue.RuleAction, res *hue.Resources) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(a.Address, "/"), "/")
+	if !strings.HasPrefix(a.Address, "/") || len(parts) < 2 {
+		return "", false
+	}
+
+	switch {
+	case len(parts) == 3 && (parts[0] == "groups" && parts[2] == "action" || parts[0] == "lights" && parts[2] == "state"):
+		kind := strings.TrimSuffix(parts[0], "s")
+		ref, ok := formatRef(res, kind, parts[1])
+		if !ok || len(a.Body) == 0 {
+			return "", false
+		}
+
+		settings, ok := formatSettings(a.Body, res, kind, parts[1])
+		if !ok {
+			return "", false
+		}
+
+		return fmt.Sprintf("%s %s %s", kind, ref, settings), true
+	case len(parts) == 3 && parts[0] == "sensors" && parts[2] == "state" && len(a.Body) == 1:
+		ref, ok := formatRef(res, "sensor", parts[1])
+		if !ok {
+			return "", false
+		}
+
+		for attr, v := range a.Body {
+			lit, ok := formatLiteral(v)
+			if !ok || !isIdent(attr) {
+				return "", false
+			}
+
+			return fmt.Sprintf("sensor %s.%s = %s", ref, attr, lit), true
+		}
+	case len(parts) == 2 && parts[0] == "schedules" && len(a.Body) == 1:
+		ref, ok := formatRef(res, "schedule", parts[1])
+		if !ok {
+			return "", false
+		}
+
+		switch a.Body["status"] {
+		case hue.ScheduleEnabled:
+			return fmt.Sprintf("schedule %s enable", ref), true
+		case hue.ScheduleDisabled:
+			return fmt.Sprintf("schedule %s disable", ref), true
+		}
+	}
+
+	return "", false
+}
+
+// formatSettings writes the body of a group or light action as its
+// settings, it is false when the body has attributes that aren't
+// settings.
+func formatSettings(body map[string]interface{}, res *hue.Resources, kind, id string) (string, bool) {
+	var settings []string
+	for key, v := range body {
+		switch key {
+		case "on":
+			on, ok := v.(bool)
+			if !ok {
+				return "", false
+			}
+
+			if on {
+				settings = append(settings, "on")
+			} else {
+				settings = append(settings, "off")
+			}
+		case "scene":
+			sceneID, ok := v.(string)
+			if !ok || kind != "group" {
+				return "", false
+			}
+
+			var name string
+			for _, s := range res.Scenes {
+				if s.ID == sceneID {
+					name = s.Name
+				}
+			}
+
+			found := scenes(res, name, id)
+			if name == "" || len(found) != 1 || found[0].ID != sceneID {
+				return "", false
+			}
+
+			settings = append(settings, "scene "+strconv.Quote(name))
+		case "bri":
+			bri, ok := intValue(v)
+			if !ok || bri < 1 || bri > 254 {
+				return "", false
+			}
+
+			settings = append(settings, fmt.Sprintf("bri %d", bri))
+		case "transitiontime":
+			ds, ok := intValue(v)
+			if !ok || ds < 0 || ds > 65535 {
+				return "", false
+			}
+
+			settings = append(settings, "transition "+formatDuration(time.Duration(ds)*100*time.Millisecond))
+		default:
+			return "", false
+		}
+	}
+
+	order := map[string]int{"on": 0, "off": 0, "scene": 1, "bri": 2, "transition": 3}
+	sort.Slice(settings, func(i, j int) bool {
+		return order[strings.Fields(settings[i])[0]] < order[strings.Fields(settings[j])[0]]
+	})
+
+	return strings.Join(settings, " "), true
+}
+
+// formatLiteral writes a value of an action body as a literal, it is
+// false for values literals can't express.
+func formatLiteral(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v), true
+	case string:
+		return strconv.Quote(v), true
+	}
+
+	if n, ok := intValue(v); ok {
+		return strconv.Itoa(n), true
+	}
+
+	return "", false
+}
+
+// intValue returns v as an int, whether it was decoded from JSON or set
+// by the parser.
+func intValue(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt32 {
+			return int(v), true
+		}
+	}
+
+	return 0, false
+}
+
+// isIdent reports whether s can be written as an attribute name.
+func isIdent(s string) bool {
+	if s == "" || !isLetter(s[0]) {
+		return false
+	}
+
+	for i := 1; i < len(s); i++ {
+		if !isLetter(s[i]) && !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
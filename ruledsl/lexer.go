@@ -0,0 +1,217 @@
+package ruledsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// kind is the kind of a token.
+type kind int
+
+const (
+	eof kind = iota
+	ident
+	number
+	duration
+	str
+	object
+	punct
+)
+
+func (k kind) String() string {
+	switch k {
+	case eof:
+		return "end of input"
+	case ident:
+		return "word"
+	case number:
+		return "number"
+	case duration:
+		return "duration"
+	case str:
+		return "string"
+	case object:
+		return "JSON object"
+	default:
+		return "symbol"
+	}
+}
+
+// token is a lexed token. Text is the unquoted value of strings and the
+// raw JSON of objects.
+type token struct {
+	kind kind
+	text string
+	pos  pos
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case eof:
+		return t.kind.String()
+	case str:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+// pos is the line and column of a token, both counted from 1.
+type pos struct {
+	line, col int
+}
+
+func (p pos) String() string {
+	return fmt.Sprintf("%d:%d", p.line, p.col)
+}
+
+// lex splits src into tokens, the last token is always eof.
+func lex(src string) ([]token, error) {
+	var (
+		tokens []token
+		i      int
+		at     = pos{line: 1, col: 1}
+	)
+
+	advance := func(n int) {
+		for _, r := range src[i : i+n] {
+			if r == '\n' {
+				at.line++
+				at.col = 1
+			} else {
+				at.col++
+			}
+		}
+		i += n
+	}
+
+	for {
+		// skip blanks and comments, which run from # to the end of the
+		// line
+		for i < len(src) {
+			switch {
+			case unicode.IsSpace(rune(src[i])):
+				advance(1)
+				continue
+			case src[i] == '#':
+				n := strings.IndexByte(src[i:], '\n')
+				if n < 0 {
+					n = len(src) - i
+				}
+				advance(n)
+				continue
+			}
+			break
+		}
+
+		if i == len(src) {
+			return append(tokens, token{kind: eof, pos: at}), nil
+		}
+
+		start, c := at, src[i]
+		switch {
+		case c == '"':
+			n := quotedLength(src[i:])
+			if n < 0 {
+				return nil, errors.Errorf("%s: unterminated string", start)
+			}
+
+			text, err := strconv.Unquote(src[i : i+n])
+			if err != nil {
+				return nil, errors.Errorf("%s: invalid string %s", start, src[i:i+n])
+			}
+
+			tokens = append(tokens, token{kind: str, text: text, pos: start})
+			advance(n)
+		case c == '{':
+			dec := json.NewDecoder(strings.NewReader(src[i:]))
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, errors.Errorf("%s: invalid JSON object: %v", start, err)
+			}
+
+			var compact bytes.Buffer
+			json.Compact(&compact, raw)
+			tokens = append(tokens, token{kind: object, text: compact.String(), pos: start})
+			advance(int(dec.InputOffset()))
+		case isDigit(c) || c == '-' && i+1 < len(src) && isDigit(src[i+1]):
+			n := numberLength(src[i:])
+			t := token{kind: number, text: src[i : i+n], pos: start}
+			if _, err := strconv.Atoi(t.text); err != nil {
+				t.kind = duration
+			}
+
+			tokens = append(tokens, t)
+			advance(n)
+		case isLetter(c):
+			n := 1
+			for i+n < len(src) && (isLetter(src[i+n]) || isDigit(src[i+n])) {
+				n++
+			}
+
+			tokens = append(tokens, token{kind: ident, text: src[i : i+n], pos: start})
+			advance(n)
+		case strings.HasPrefix(src[i:], "=="):
+			tokens = append(tokens, token{kind: punct, text: "==", pos: start})
+			advance(2)
+		case strings.IndexByte(".,=<>", c) >= 0:
+			tokens = append(tokens, token{kind: punct, text: string(c), pos: start})
+			advance(1)
+		default:
+			return nil, errors.Errorf("%s: unexpected character %q", start, c)
+		}
+	}
+}
+
+// quotedLength returns the length of the double quoted string at the
+// start of s including its quotes, -1 when it isn't terminated.
+func quotedLength(s string) int {
+	for n := 1; n < len(s); n++ {
+		switch s[n] {
+		case '\\':
+			n++
+		case '"':
+			return n + 1
+		case '\n':
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// numberLength returns the length of the number or duration at the
+// start of s, such as 1002, -5, 4s or 1.5h.
+func numberLength(s string) int {
+	n := 1
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+
+	if n+1 < len(s) && s[n] == '.' && isDigit(s[n+1]) {
+		n++
+		for n < len(s) && isDigit(s[n]) {
+			n++
+		}
+	}
+
+	for n < len(s) && (isLetter(s[n]) || isDigit(s[n])) {
+		n++
+	}
+
+	return n
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
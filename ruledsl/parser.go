@@ -0,0 +1,551 @@
+package ruledsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
+)
+
+// parser compiles statements from a list of tokens, resolving the
+// resources they refer to as they are parsed.
+type parser struct {
+	tokens []token
+	i      int
+	res    *hue.Resources
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != eof {
+		p.i++
+	}
+
+	return t
+}
+
+// is reports whether the next token is the keyword or symbol text.
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == ident || t.kind == punct) && t.text == text
+}
+
+// accept consumes the next token when it is the keyword or symbol text.
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf(p.peek(), "expected '%s', got %s", text, p.peek())
+	}
+
+	return nil
+}
+
+func (p *parser) expectKind(k kind) (token, error) {
+	t := p.next()
+	if t.kind != k {
+		return t, p.errorf(t, "expected %s, got %s", k, t)
+	}
+
+	return t, nil
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return errors.Errorf("%s: %s", t.pos, fmt.Sprintf(format, args...))
+}
+
+// statement compiles the next statement to a rule for each of its
+// alternative sets of conditions, or more when it has more actions than
+// a rule holds.
+func (p *parser) statement() ([]hue.Rule, error) {
+	start := p.peek()
+
+	var name string
+	if p.accept("rule") {
+		t, err := p.expectKind(str)
+		if err != nil {
+			return nil, err
+		}
+		name = t.text
+	}
+
+	if err := p.expect("when"); err != nil {
+		return nil, err
+	}
+
+	alternatives := [][]hue.Condition{nil}
+	for {
+		c, err := p.condition()
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(alternatives) - 1
+		alternatives[last] = append(alternatives[last], c)
+
+		if p.accept("or") {
+			alternatives = append(alternatives, nil)
+			continue
+		}
+		if !p.accept("and") {
+			break
+		}
+	}
+
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+
+	var actions []hue.RuleAction
+	for {
+		a, err := p.action()
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, a)
+		if !p.accept("and") {
+			break
+		}
+	}
+
+	var rules []hue.Rule
+	for _, conditions := range alternatives {
+		if len(conditions) > hue.MaxRuleConditions {
+			return nil, p.errorf(start, "a rule can't have more than %d conditions, split them with or", hue.MaxRuleConditions)
+		}
+
+		for i := 0; i < len(actions); i += hue.MaxRuleActions {
+			end := i + hue.MaxRuleActions
+			if end > len(actions) {
+				end = len(actions)
+			}
+
+			rules = append(rules, hue.Rule{
+				Conditions: conditions,
+				Actions:    actions[i:end],
+			})
+		}
+	}
+
+	for i := range rules {
+		rules[i].Name = name
+		if name != "" && i > 0 {
+			rules[i].Name = fmt.Sprintf("%s (%d)", name, i+1)
+		}
+
+		if err := rules[i].Validate(); err != nil {
+			return nil, p.errorf(start, "%v", err)
+		}
+		if err := rules[i].CheckAddresses(p.res); err != nil {
+			return nil, p.errorf(start, "%v", err)
+		}
+	}
+
+	return rules, nil
+}
+
+func (p *parser) condition() (hue.Condition, error) {
+	if p.accept("time") {
+		c := hue.Condition{Address: hue.LocalTimeAddress, Operator: hue.OpIn}
+		if p.accept("not") {
+			c.Operator = hue.OpNotIn
+		}
+
+		if err := p.expect("in"); err != nil {
+			return c, err
+		}
+
+		t, err := p.expectKind(str)
+		c.Value = t.text
+		return c, err
+	}
+
+	address, err := p.address()
+	if err != nil {
+		return hue.Condition{}, err
+	}
+
+	c := hue.Condition{Address: address}
+	t := p.next()
+	switch {
+	case t.text == "==" && t.kind == punct:
+		c.Operator = hue.OpEq
+		c.Value, err = p.value()
+	case (t.text == ">" || t.text == "<") && t.kind == punct:
+		c.Operator = hue.OpGt
+		if t.text == "<" {
+			c.Operator = hue.OpLt
+		}
+
+		var n token
+		n, err = p.expectKind(number)
+		c.Value = n.text
+	case t.text == "changed" && t.kind == ident:
+		c.Operator = hue.OpDx
+		if p.peek().kind == duration || p.peek().kind == number {
+			c.Operator = hue.OpDdx
+			if c.Value, err = p.durationValue(); err == nil {
+				err = p.expect("ago")
+			}
+		}
+	case t.text == "stable" && t.kind == ident:
+		c.Operator = hue.OpStable
+		c.Value, err = p.durationValue()
+	case t.text == "not" && t.kind == ident:
+		switch {
+		case p.accept("stable"):
+			c.Operator = hue.OpNotStable
+			c.Value, err = p.durationValue()
+		case p.accept("in"):
+			c.Operator = hue.OpNotIn
+			var s token
+			s, err = p.expectKind(str)
+			c.Value = s.text
+		default:
+			err = p.errorf(p.peek(), "expected 'stable' or 'in', got %s", p.peek())
+		}
+	case t.text == "in" && t.kind == ident:
+		c.Operator = hue.OpIn
+		var s token
+		s, err = p.expectKind(str)
+		c.Value = s.text
+	default:
+		err = p.errorf(t, "expected ==, >, <, changed, stable, not stable, in or not in, got %s", t)
+	}
+
+	return c, err
+}
+
+// address parses the attribute a condition compares, either a resource
+// followed by its attribute or a quoted address.
+func (p *parser) address() (string, error) {
+	t := p.peek()
+	if t.kind == str {
+		p.next()
+		if !strings.HasPrefix(t.text, "/") {
+			return "", p.errorf(t, "address %s must start with /", t)
+		}
+
+		return t.text, nil
+	}
+
+	if t.kind != ident || (t.text != "sensor" && t.text != "light" && t.text != "group") {
+		return "", p.errorf(t, "expected sensor, light, group, time or a quoted address, got %s", t)
+	}
+
+	p.next()
+	id, err := p.ref(t.text)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.expect("."); err != nil {
+		return "", err
+	}
+
+	var path []string
+	for {
+		attr, err := p.expectKind(ident)
+		if err != nil {
+			return "", err
+		}
+
+		path = append(path, attr.text)
+		if !p.accept(".") {
+			break
+		}
+	}
+
+	if len(path) == 1 {
+		path = append([]string{"state"}, path...)
+	}
+
+	return fmt.Sprintf("/%s/%s/%s", kinds[t.text], id, strings.Join(path, "/")), nil
+}
+
+// ref resolves the name or identifier of a resource of the kind to its
+// identifier.
+func (p *parser) ref(kind string) (string, error) {
+	t := p.next()
+	list := resources(p.res, kind)
+
+	switch t.kind {
+	case number:
+		for _, r := range list {
+			if r.id == t.text {
+				return r.id, nil
+			}
+		}
+
+		return "", p.errorf(t, "%s %s doesn't exist", kind, t.text)
+	case str:
+		var found []string
+		for _, r := range list {
+			if r.name == t.text {
+				found = append(found, r.id)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			return "", p.errorf(t, "no %s is named %s", kind, t)
+		case 1:
+			return found[0], nil
+		default:
+			return "", p.errorf(t, "%d %ss are named %s, refer to one by identifier: %s", len(found), kind, t, strings.Join(found, ", "))
+		}
+	default:
+		return "", p.errorf(t, "expected the name or identifier of a %s, got %s", kind, t)
+	}
+}
+
+// value parses the value of an eq condition.
+func (p *parser) value() (string, error) {
+	t := p.next()
+	switch {
+	case t.kind == ident && (t.text == "true" || t.text == "false"):
+		return t.text, nil
+	case t.kind == number || t.kind == str:
+		return t.text, nil
+	default:
+		return "", p.errorf(t, "expected true, false, a number or a string, got %s", t)
+	}
+}
+
+// literal parses the value of an attribute written by an action.
+func (p *parser) literal() (interface{}, error) {
+	t := p.next()
+	switch {
+	case t.kind == ident && (t.text == "true" || t.text == "false"):
+		return t.text == "true", nil
+	case t.kind == number:
+		return strconv.Atoi(t.text)
+	case t.kind == str:
+		return t.text, nil
+	default:
+		return nil, p.errorf(t, "expected true, false, a number or a string, got %s", t)
+	}
+}
+
+func (p *parser) duration() (time.Duration, error) {
+	t := p.next()
+	if t.kind != duration && !(t.kind == number && t.text == "0") {
+		return 0, p.errorf(t, "expected a duration such as 4s or 15m, got %s", t)
+	}
+
+	d, err := time.ParseDuration(t.text)
+	if err != nil || d < 0 {
+		return 0, p.errorf(t, "invalid duration %s", t)
+	}
+
+	return d, nil
+}
+
+// durationValue parses a duration as the value of a condition.
+func (p *parser) durationValue() (string, error) {
+	t := p.peek()
+	d, err := p.duration()
+	if err != nil {
+		return "", err
+	}
+
+	v, err := durationValue(d)
+	if err != nil {
+		return "", p.errorf(t, "%v", err)
+	}
+
+	return v, nil
+}
+
+func (p *parser) action() (hue.RuleAction, error) {
+	t := p.next()
+	if t.kind != ident {
+		return hue.RuleAction{}, p.errorf(t, "expected group, light, sensor, schedule, put, post or delete, got %s", t)
+	}
+
+	switch t.text {
+	case "group", "light":
+		return p.lightAction(t.text)
+	case "sensor":
+		id, err := p.ref(t.text)
+		if err != nil {
+			return hue.RuleAction{}, err
+		}
+
+		if err := p.expect("."); err != nil {
+			return hue.RuleAction{}, err
+		}
+
+		attr, err := p.expectKind(ident)
+		if err != nil {
+			return hue.RuleAction{}, err
+		}
+
+		if err := p.expect("="); err != nil {
+			return hue.RuleAction{}, err
+		}
+
+		v, err := p.literal()
+		if err != nil {
+			return hue.RuleAction{}, err
+		}
+
+		return hue.RuleAction{
+			Address: fmt.Sprintf("/sensors/%s/state", id),
+			Method:  http.MethodPut,
+			Body:    map[string]interface{}{attr.text: v},
+		}, nil
+	case "schedule":
+		id, err := p.ref(t.text)
+		if err != nil {
+			return hue.RuleAction{}, err
+		}
+
+		status := hue.ScheduleEnabled
+		switch {
+		case p.accept("enable"):
+		case p.accept("disable"):
+			status = hue.ScheduleDisabled
+		default:
+			return hue.RuleAction{}, p.errorf(p.peek(), "expected enable or disable, got %s", p.peek())
+		}
+
+		return hue.RuleAction{
+			Address: fmt.Sprintf("/schedules/%s", id),
+			Method:  http.MethodPut,
+			Body:    map[string]interface{}{"status": status},
+		}, nil
+	case "put", "post", "delete":
+		address, err := p.expectKind(str)
+		if err != nil {
+			return hue.RuleAction{}, err
+		}
+
+		a := hue.RuleAction{Address: address.text, Method: strings.ToUpper(t.text)}
+		if p.peek().kind == object {
+			if err := json.Unmarshal([]byte(p.next().text), &a.Body); err != nil {
+				return a, err
+			}
+		}
+
+		return a, nil
+	default:
+		return hue.RuleAction{}, p.errorf(t, "expected group, light, sensor, schedule, put, post or delete, got %s", t)
+	}
+}
+
+// settingKeys maps the settings of group and light actions to the
+// attributes of their body.
+var settingKeys = map[string]string{
+	"on":         "on",
+	"off":        "on",
+	"scene":      "scene",
+	"bri":        "bri",
+	"transition": "transitiontime",
+}
+
+// lightAction parses the settings of a group or light action.
+func (p *parser) lightAction(kind string) (hue.RuleAction, error) {
+	id, err := p.ref(kind)
+	if err != nil {
+		return hue.RuleAction{}, err
+	}
+
+	a := hue.RuleAction{
+		Address: fmt.Sprintf("/lights/%s/state", id),
+		Method:  http.MethodPut,
+		Body:    map[string]interface{}{},
+	}
+	if kind == "group" {
+		a.Address = fmt.Sprintf("/groups/%s/action", id)
+	}
+
+settings:
+	for {
+		t := p.peek()
+		if t.kind != ident {
+			break
+		}
+
+		var v interface{}
+		switch t.text {
+		case "on", "off":
+			p.next()
+			v = t.text == "on"
+		case "scene":
+			if kind != "group" {
+				return a, p.errorf(t, "scenes can only be recalled on groups")
+			}
+
+			p.next()
+			name, err := p.expectKind(str)
+			if err != nil {
+				return a, err
+			}
+
+			found := scenes(p.res, name.text, id)
+			switch len(found) {
+			case 0:
+				return a, p.errorf(name, "no scene of group %s is named %s", id, name)
+			case 1:
+				v = found[0].ID
+			default:
+				return a, p.errorf(name, "%d scenes of group %s are named %s", len(found), id, name)
+			}
+		case "bri":
+			p.next()
+			n, err := p.expectKind(number)
+			if err != nil {
+				return a, err
+			}
+
+			bri, _ := strconv.Atoi(n.text)
+			if bri < 1 || bri > 254 {
+				return a, p.errorf(n, "bri must be between 1 and 254")
+			}
+			v = bri
+		case "transition":
+			p.next()
+			d, err := p.duration()
+			if err != nil {
+				return a, err
+			}
+
+			if d%(100*time.Millisecond) != 0 || d > 65535*100*time.Millisecond {
+				return a, p.errorf(t, "transition %s must be a multiple of 100ms up to 1h49m13.5s", formatDuration(d))
+			}
+			v = int(d / (100 * time.Millisecond))
+		default:
+			break settings
+		}
+
+		key := settingKeys[t.text]
+		if _, ok := a.Body[key]; ok {
+			return a, p.errorf(t, "%s is set more than once", key)
+		}
+		a.Body[key] = v
+	}
+
+	if len(a.Body) == 0 {
+		return a, p.errorf(p.peek(), "expected on, off, scene, bri or transition, got %s", p.peek())
+	}
+
+	return a, nil
+}
@@ -0,0 +1,219 @@
+// Package ruledsl compiles a small text language to bridge rules and
+// renders bridge rules back into it for review. A statement reads
+//
+//	rule "Hall at night"
+//	when sensor "Hall motion".presence == true
+//	  and sensor "Daylight".daylight == false
+//	then group "Hall" scene "Night" transition 4s
+//
+// Lights, groups, sensors, scenes and schedules are referred to by name,
+// or by identifier when their name isn't unique, and resolved against
+// the resources of a bridge.
+//
+// Conditions compare an attribute of a sensor, light or group, written
+// after a dot, with == value, > number, < number, changed, changed
+// <duration> ago, stable <duration> or not stable <duration>. The local
+// time is compared with time in "T18:00:00/T23:00:00" or time not in.
+// Attributes outside the state are written as a path, such as
+// sensor 2.config.on, and any other address can be compared by quoting
+// it. Conditions are joined with and, or splits a statement into a rule
+// for each alternative.
+//
+// Actions are joined with and. Groups and lights are set with any of on,
+// off, bri <number>, transition <duration> and, for groups, scene
+// <name>. Sensor states are written with sensor "Flag".flag = true and
+// schedules are switched with schedule "Wake up" enable or disable. Any
+// other request is written as put, post or delete followed by its quoted
+// address and JSON body. A statement with more actions than a rule can
+// hold is split into several rules with the same conditions.
+//
+// Comments run from # to the end of the line.
+package ruledsl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
+)
+
+// Fetch returns the resources of the bridge that statements are resolved
+// against, along with its rules.
+func Fetch(ctx context.Context, c hue.Client) (*hue.Resources, error) {
+	var (
+		res hue.Resources
+		err error
+	)
+
+	if res.Lights, err = c.AllLights(ctx); err != nil {
+		return nil, err
+	}
+	if res.Groups, err = c.AllGroups(ctx); err != nil {
+		return nil, err
+	}
+	if res.Sensors, err = c.AllSensors(ctx); err != nil {
+		return nil, err
+	}
+	if res.Scenes, err = c.AllScenes(ctx); err != nil {
+		return nil, err
+	}
+	if res.Schedules, err = c.AllSchedules(ctx); err != nil {
+		return nil, err
+	}
+	if res.Rules, err = c.AllRules(ctx); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Compile parses the statements of src and compiles them to rules,
+// resolving the resources they refer to against res. The rules are
+// validated but not written to the bridge.
+func Compile(src string, res *hue.Resources) ([]hue.Rule, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, res: res}
+
+	var rules []hue.Rule
+	for p.peek().kind != eof {
+		compiled, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, compiled...)
+	}
+
+	return rules, nil
+}
+
+// Decompile renders rules as statements, one per rule, referring to the
+// resources of res by name where their name is unique.
+func Decompile(rules []hue.Rule, res *hue.Resources) (string, error) {
+	var b strings.Builder
+	for i := range rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		if err := decompile(&b, &rules[i], res); err != nil {
+			return "", errors.Wrapf(err, "failed to decompile rule %d", rules[i].ID)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// resource is a resource that can be referred to by name.
+type resource struct {
+	id, name string
+}
+
+// resources lists the resources of a kind, identified by the keyword
+// statements refer to them with.
+func resources(res *hue.Resources, kind string) []resource {
+	var list []resource
+	switch kind {
+	case "light":
+		for _, l := range res.Lights {
+			list = append(list, resource{strconv.Itoa(l.ID), l.Name})
+		}
+	case "group":
+		list = append(list, resource{id: strconv.Itoa(hue.AllLightsGroup)})
+		for _, g := range res.Groups {
+			list = append(list, resource{strconv.Itoa(g.ID), g.Name})
+		}
+	case "sensor":
+		for _, s := range res.Sensors {
+			list = append(list, resource{strconv.Itoa(s.ID), s.Name})
+		}
+	case "schedule":
+		for _, s := range res.Schedules {
+			list = append(list, resource{strconv.Itoa(s.ID), s.Name})
+		}
+	}
+
+	return list
+}
+
+// kinds maps the keywords of resources to the kind of resource in their
+// addresses.
+var kinds = map[string]string{
+	"light":    "lights",
+	"group":    "groups",
+	"sensor":   "sensors",
+	"schedule": "schedules",
+}
+
+// scenes returns the scenes named name that can be recalled on the
+// group: its own group scenes or, when it has none of that name, light
+// scenes. Any scene can be recalled on the group of all lights.
+func scenes(res *hue.Resources, name, group string) []hue.Scene {
+	var own, others []hue.Scene
+	for _, s := range res.Scenes {
+		switch {
+		case s.Name != name:
+		case s.Group == group:
+			own = append(own, s)
+		case s.Group == "" || group == strconv.Itoa(hue.AllLightsGroup):
+			others = append(others, s)
+		}
+	}
+
+	if len(own) > 0 {
+		return own
+	}
+
+	return others
+}
+
+// durationValue formats d as the PThh:mm:ss durations of conditions.
+func durationValue(d time.Duration) (string, error) {
+	if d <= 0 || d%time.Second != 0 || d >= 100*time.Hour {
+		return "", errors.Errorf("duration %s must be a whole number of seconds below 100h", d)
+	}
+
+	s := int(d / time.Second)
+	return fmt.Sprintf("PT%02d:%02d:%02d", s/3600, s/60%60, s%60), nil
+}
+
+// parseDurationValue parses the PThh:mm:ss durations of conditions.
+func parseDurationValue(v string) (time.Duration, error) {
+	var h, m, s int
+	if _, err := fmt.Sscanf(v, "PT%02d:%02d:%02d", &h, &m, &s); err != nil || len(v) != len("PT00:00:00") {
+		return 0, errors.Errorf("invalid duration '%s'", v)
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second, nil
+}
+
+// formatDuration formats d the way durations are written in statements,
+// such as 1h30m or 4s.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+
+	var b strings.Builder
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dh", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dm", m)
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		b.WriteString(d.String())
+	}
+
+	return b.String()
+}
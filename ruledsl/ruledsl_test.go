@@ -0,0 +1,271 @@
+package ruledsl
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ninnemana/huego"
+)
+
+var bridge = &hue.Resources{
+	Lights: []hue.Light{
+		{ID: 1, Name: "Hall ceiling"},
+		{ID: 2, Name: "Lamp"},
+		{ID: 3, Name: "Lamp"},
+	},
+	Groups: []hue.Group{
+		{ID: 1, Name: "Hall"},
+		{ID: 2, Name: "Kitchen"},
+	},
+	Sensors: []hue.Sensor{
+		{ID: 1, Name: "Daylight", Type: hue.Daylight},
+		{ID: 2, Name: "Dimmer", Type: hue.ZLLSwitch, ModelID: "RWL021"},
+		{ID: 5, Name: "Hall motion", Type: hue.ZLLPresence},
+		{ID: 9, Name: "Away", Type: hue.CLIPGenericFlag},
+	},
+	Scenes: []hue.Scene{
+		{ID: "AB34EF5", Name: "Night", Group: "1"},
+		{ID: "CD56AB7", Name: "Night", Group: "2"},
+		{ID: "EF78CD9", Name: "Bright", Group: "2"},
+	},
+	Schedules: []hue.Schedule{
+		{ID: 1, Name: "Wake up"},
+	},
+}
+
+func put(address string, body map[string]interface{}) hue.RuleAction {
+	return hue.RuleAction{Address: address, Method: http.MethodPut, Body: body}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []hue.Rule
+		wantErr string
+	}{
+		{
+			name: "scene at night",
+			src: `rule "Hall at night"
+				when sensor "Hall motion".presence == true
+				  and sensor "Daylight".daylight == false
+				then group "Hall" scene "Night" transition 4s`,
+			want: []hue.Rule{{
+				Name: "Hall at night",
+				Conditions: []hue.Condition{
+					{Address: "/sensors/5/state/presence", Operator: hue.OpEq, Value: "true"},
+					{Address: "/sensors/1/state/daylight", Operator: hue.OpEq, Value: "false"},
+				},
+				Actions: []hue.RuleAction{put("/groups/1/action", map[string]interface{}{"scene": "AB34EF5", "transitiontime": 40})},
+			}},
+		},
+		{
+			name: "operators and actions",
+			src: `# leaving the house
+				when sensor "Hall motion".presence stable 15m
+				  and sensor "Hall motion".presence changed 1h30m ago
+				  and sensor 2.buttonevent > 1000
+				  and sensor 2.lastupdated changed
+				  and sensor "Away".config.on == true
+				  and time not in "W124/T08:00:00/T18:00:00"
+				  and "/groups/0/state/any_on" == true
+				then light 2 off
+				  and group 0 on bri 254
+				  and sensor "Away".flag = true
+				  and schedule "Wake up" disable
+				  and put "/lights/1/state" {"alert": "select"}
+				  and delete "/scenes/EF78CD9"`,
+			want: []hue.Rule{{
+				Conditions: []hue.Condition{
+					{Address: "/sensors/5/state/presence", Operator: hue.OpStable, Value: "PT00:15:00"},
+					{Address: "/sensors/5/state/presence", Operator: hue.OpDdx, Value: "PT01:30:00"},
+					{Address: "/sensors/2/state/buttonevent", Operator: hue.OpGt, Value: "1000"},
+					{Address: "/sensors/2/state/lastupdated", Operator: hue.OpDx},
+					{Address: "/sensors/9/config/on", Operator: hue.OpEq, Value: "true"},
+					{Address: hue.LocalTimeAddress, Operator: hue.OpNotIn, Value: "W124/T08:00:00/T18:00:00"},
+					{Address: "/groups/0/state/any_on", Operator: hue.OpEq, Value: "true"},
+				},
+				Actions: []hue.RuleAction{
+					put("/lights/2/state", map[string]interface{}{"on": false}),
+					put("/groups/0/action", map[string]interface{}{"on": true, "bri": 254}),
+					put("/sensors/9/state", map[string]interface{}{"flag": true}),
+					put("/schedules/1", map[string]interface{}{"status": hue.ScheduleDisabled}),
+					put("/lights/1/state", map[string]interface{}{"alert": "select"}),
+					{Address: "/scenes/EF78CD9", Method: http.MethodDelete},
+				},
+			}},
+		},
+		{
+			name: "alternatives",
+			src: `rule "Dimmer"
+				when sensor "Dimmer".buttonevent == 1002 or sensor "Dimmer".buttonevent == 1000
+				then group "Kitchen" scene "Bright"`,
+			want: []hue.Rule{
+				{
+					Name:       "Dimmer",
+					Conditions: []hue.Condition{{Address: "/sensors/2/state/buttonevent", Operator: hue.OpEq, Value: "1002"}},
+					Actions:    []hue.RuleAction{put("/groups/2/action", map[string]interface{}{"scene": "EF78CD9"})},
+				},
+				{
+					Name:       "Dimmer (2)",
+					Conditions: []hue.Condition{{Address: "/sensors/2/state/buttonevent", Operator: hue.OpEq, Value: "1000"}},
+					Actions:    []hue.RuleAction{put("/groups/2/action", map[string]interface{}{"scene": "EF78CD9"})},
+				},
+			},
+		},
+		{
+			name:    "ambiguous name",
+			src:     `when sensor 2.lastupdated changed then light "Lamp" on`,
+			wantErr: `1:46: 2 lights are named "Lamp", refer to one by identifier: 2, 3`,
+		},
+		{
+			name:    "unknown name",
+			src:     "when sensor \"Hallway\".presence == true\nthen group 0 off",
+			wantErr: `1:13: no sensor is named "Hallway"`,
+		},
+		{
+			name:    "missing attribute",
+			src:     `when sensor "Dimmer".presence == true then group 0 off`,
+			wantErr: "1:1: validation failed: conditions.0.address ZLLSwitch sensors have no state attribute presence, got /sensors/2/state/presence",
+		},
+		{
+			name:    "scene of another group",
+			src:     `when sensor 2.lastupdated changed then group "Hall" scene "Bright"`,
+			wantErr: `1:59: no scene of group 1 is named "Bright"`,
+		},
+		{
+			name:    "syntax",
+			src:     `when sensor "Dimmer".buttonevent = 1002 then group 0 off`,
+			wantErr: `1:34: expected ==, >, <, changed, stable, not stable, in or not in, got '='`,
+		},
+		{
+			name:    "uneven transition",
+			src:     `when sensor 2.lastupdated changed then group 0 on transition 150ms`,
+			wantErr: "1:51: transition 150ms must be a multiple of 100ms up to 1h49m13.5s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compile(tt.src, bridge)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Compile() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_splitsActions(t *testing.T) {
+	src := `rule "All off" when sensor 2.lastupdated changed then ` + strings.Repeat(`group 0 off and `, hue.MaxRuleActions) + `light 1 off`
+
+	got, err := Compile(src, bridge)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	if len(got) != 2 || len(got[0].Actions) != hue.MaxRuleActions || len(got[1].Actions) != 1 {
+		t.Fatalf("Compile() = %+v, want rules of %d and 1 actions", got, hue.MaxRuleActions)
+	}
+
+	if got[0].Name != "All off" || got[1].Name != "All off (2)" {
+		t.Errorf("Compile() names = %s, %s", got[0].Name, got[1].Name)
+	}
+}
+
+func TestDecompile(t *testing.T) {
+	rules := []hue.Rule{
+		{
+			ID:   1,
+			Name: "Hall at night",
+			Conditions: []hue.Condition{
+				{Address: "/sensors/5/state/presence", Operator: hue.OpEq, Value: "true"},
+				{Address: "/sensors/1/state/daylight", Operator: hue.OpEq, Value: "false"},
+			},
+			// bodies decoded from the bridge hold float64 numbers
+			Actions: []hue.RuleAction{put("/groups/1/action", map[string]interface{}{"scene": "AB34EF5", "transitiontime": float64(40)})},
+		},
+		{
+			ID: 2,
+			Conditions: []hue.Condition{
+				{Address: "/sensors/5/state/presence", Operator: hue.OpNotStable, Value: "PT00:00:30"},
+				{Address: "/sensors/2/state/buttonevent", Operator: hue.OpLt, Value: "2000"},
+				{Address: "/sensors/7/state/status", Operator: hue.OpEq, Value: "idle"},
+				{Address: "/groups/2/state", Operator: hue.OpDx},
+				{Address: hue.LocalTimeAddress, Operator: hue.OpIn, Value: "T18:00:00/T23:00:00"},
+			},
+			Actions: []hue.RuleAction{
+				put("/lights/3/state", map[string]interface{}{"bri": float64(127), "on": true}),
+				put("/groups/2/action", map[string]interface{}{"scene": "CD56AB7"}),
+				put("/groups/2/action", map[string]interface{}{"alert": "lselect"}),
+				put("/sensors/9/state", map[string]interface{}{"flag": false}),
+				put("/schedules/1", map[string]interface{}{"status": "enabled"}),
+				{Address: "/scenes/EF78CD9", Method: http.MethodDelete},
+			},
+		},
+	}
+
+	got, err := Decompile(rules, bridge)
+	if err != nil {
+		t.Fatalf("Decompile() error = %v", err)
+	}
+
+	want := `rule "Hall at night"
+when sensor "Hall motion".presence == true
+  and sensor "Daylight".daylight == false
+then group "Hall" scene "Night" transition 4s
+
+when sensor "Hall motion".presence not stable 30s
+  and sensor "Dimmer".buttonevent < 2000
+  and sensor 7.status == "idle"
+  and "/groups/2/state" changed
+  and time in "T18:00:00/T23:00:00"
+then light 3 on bri 127
+  and group "Kitchen" scene "Night"
+  and put "/groups/2/action" {"alert":"lselect"}
+  and sensor "Away".flag = false
+  and schedule "Wake up" enable
+  and delete "/scenes/EF78CD9"
+`
+	if got != want {
+		t.Errorf("Decompile() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDecompile_roundTrip(t *testing.T) {
+	src := `rule "Leaving"
+		when sensor "Hall motion".presence stable 1h2m3s
+		  and sensor "Away".config.on == true
+		then group 0 off transition 1.5s
+		  and post "/schedules" {"name": "later", "localtime": "PT00:05:00", "command": {"address": "/api/user/groups/0/action", "method": "PUT", "body": {"on": true}}}`
+
+	rules, err := Compile(src, bridge)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	text, err := Decompile(rules, bridge)
+	if err != nil {
+		t.Fatalf("Decompile() error = %v", err)
+	}
+
+	again, err := Compile(text, bridge)
+	if err != nil {
+		t.Fatalf("Compile() of\n%s\nerror = %v", text, err)
+	}
+
+	if !reflect.DeepEqual(again, rules) {
+		t.Errorf("Compile(Decompile()) = %+v, want %+v", again, rules)
+	}
+}
@@ -0,0 +1,409 @@
+package hue
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule statuses.
+const (
+	RuleEnabled  = "enabled"
+	RuleDisabled = "disabled"
+
+	// RuleResourceDeleted is reported by the bridge when a resource the
+	// rule refers to was deleted, the rule no longer triggers.
+	RuleResourceDeleted = "resourcedeleted"
+)
+
+// MaxRuleConditions and MaxRuleActions are the most conditions and
+// actions the bridge accepts in a single rule.
+const (
+	MaxRuleConditions = 8
+	MaxRuleActions    = 8
+)
+
+// Operator compares the attribute of a rule condition to its value.
+type Operator string
+
+// Condition operators.
+const (
+	// OpEq, OpGt and OpLt compare the attribute to the value, gt and lt
+	// only apply to integers.
+	OpEq Operator = "eq"
+	OpGt Operator = "gt"
+	OpLt Operator = "lt"
+
+	// OpDx is true when the attribute changed, it takes no value.
+	OpDx Operator = "dx"
+
+	// OpDdx is true once the attribute changed the time in the value
+	// ago, written as PThh:mm:ss.
+	OpDdx Operator = "ddx"
+
+	// OpStable and OpNotStable are true when the attribute did or didn't
+	// change for the time in the value, written as PThh:mm:ss.
+	OpStable    Operator = "stable"
+	OpNotStable Operator = "not stable"
+
+	// OpIn and OpNotIn are true when the local time is or isn't in the
+	// time interval of the value, written as Thh:mm:ss/Thh:mm:ss and
+	// optionally preceded by a weekday mask such as W124/.
+	OpIn    Operator = "in"
+	OpNotIn Operator = "not in"
+)
+
+// LocalTimeAddress is the condition address of the bridge's local time,
+// it is only compared with the in and not in operators.
+const LocalTimeAddress = "/config/localtime"
+
+var (
+	ruleDuration = regexp.MustCompile(`^PT\d{2}:\d{2}:\d{2}$`)
+	ruleInterval = regexp.MustCompile(`^(W\d{1,3}/)?T\d{2}:\d{2}:\d{2}/T\d{2}:\d{2}:\d{2}$`)
+)
+
+// Rule triggers actions when all its conditions are met.
+// GET /api/<username>/rules/<id>
+type Rule struct {
+	// ID is the identifier of the rule on the bridge, it isn't part of
+	// the rule's attributes.
+	ID int `json:"-"`
+
+	Name           string       `json:"name"`
+	Owner          string       `json:"owner,omitempty"`
+	Created        string       `json:"created,omitempty"`
+	LastTriggered  string       `json:"lasttriggered,omitempty"`
+	TimesTriggered int          `json:"timestriggered,omitempty"`
+	Status         string       `json:"status,omitempty"`
+	Recycle        bool         `json:"recycle,omitempty"`
+	Conditions     []Condition  `json:"conditions"`
+	Actions        []RuleAction `json:"actions"`
+
+	// Extra holds any attribute the bridge reported that isn't modelled
+	// above, it is written back out when the rule is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Condition compares an attribute of a resource, it is met while the
+// comparison holds.
+type Condition struct {
+	// Address is the attribute compared, such as
+	// /sensors/2/state/buttonevent, without the /api/<username> prefix.
+	Address  string   `json:"address"`
+	Operator Operator `json:"operator"`
+	Value    string   `json:"value,omitempty"`
+}
+
+// RuleAction is a request the bridge sends to itself when a rule
+// triggers.
+type RuleAction struct {
+	// Address is the resource the action is sent to, such as
+	// /groups/0/action, without the /api/<username> prefix.
+	Address string `json:"address"`
+
+	// Method is the HTTP method of the action, POST, PUT or DELETE.
+	Method string `json:"method"`
+
+	Body map[string]interface{} `json:"body"`
+}
+
+// Validate checks the attributes of the rule that can be written to the
+// bridge.
+func (r *Rule) Validate() error {
+	v := &ValidationError{}
+
+	if utf8.RuneCountInString(r.Name) > MaxNameLength {
+		v.add("name", r.Name, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	}
+
+	switch r.Status {
+	case "", RuleEnabled, RuleDisabled:
+	default:
+		v.add("status", r.Status, "must be one of enabled or disabled")
+	}
+
+	switch n := len(r.Conditions); {
+	case n == 0:
+		v.add("conditions", nil, "need at least one condition")
+	case n > MaxRuleConditions:
+		v.add("conditions", n, fmt.Sprintf("must be at most %d conditions", MaxRuleConditions))
+	}
+
+	switch n := len(r.Actions); {
+	case n == 0:
+		v.add("actions", nil, "need at least one action")
+	case n > MaxRuleActions:
+		v.add("actions", n, fmt.Sprintf("must be at most %d actions", MaxRuleActions))
+	}
+
+	for i := range r.Conditions {
+		if err := r.Conditions[i].Validate(); err != nil {
+			v.Fields = append(v.Fields, prefixed(err, fmt.Sprintf("conditions.%d.", i))...)
+		}
+	}
+
+	for i := range r.Actions {
+		if err := r.Actions[i].Validate(); err != nil {
+			v.Fields = append(v.Fields, prefixed(err, fmt.Sprintf("actions.%d.", i))...)
+		}
+	}
+
+	return v.err()
+}
+
+// Validate checks the operator of the condition applies to its address
+// and value.
+func (c *Condition) Validate() error {
+	v := &ValidationError{}
+
+	if _, _, _, ok := splitAddress(c.Address); !ok && c.Address != LocalTimeAddress {
+		v.add("address", c.Address, "must be a resource attribute such as /sensors/<id>/state/<attribute>")
+	}
+
+	localTime := c.Address == LocalTimeAddress
+
+	switch c.Operator {
+	case OpEq:
+		v.conflict(c.Value == "", "value", "is needed by the eq operator")
+	case OpGt, OpLt:
+		if _, err := strconv.Atoi(c.Value); err != nil {
+			v.add("value", c.Value, fmt.Sprintf("must be an integer for the %s operator", c.Operator))
+		}
+	case OpDx:
+		v.conflict(c.Value != "", "value", "can't be set for the dx operator")
+	case OpDdx, OpStable, OpNotStable:
+		if !ruleDuration.MatchString(c.Value) {
+			v.add("value", c.Value, fmt.Sprintf("must be a duration written as PThh:mm:ss for the %s operator", c.Operator))
+		}
+	case OpIn, OpNotIn:
+		if !ruleInterval.MatchString(c.Value) {
+			v.add("value", c.Value, fmt.Sprintf("must be a time interval written as Thh:mm:ss/Thh:mm:ss for the %s operator", c.Operator))
+		}
+		v.conflict(!localTime, "operator", fmt.Sprintf("%s only applies to %s", c.Operator, LocalTimeAddress))
+	default:
+		v.add("operator", c.Operator, "must be one of eq, gt, lt, dx, ddx, stable, not stable, in or not in")
+	}
+
+	if localTime {
+		switch c.Operator {
+		case OpIn, OpNotIn:
+		default:
+			v.add("operator", c.Operator, fmt.Sprintf("%s can only be compared with in or not in", LocalTimeAddress))
+		}
+	}
+
+	return v.err()
+}
+
+// Validate checks the action can be sent by the bridge.
+func (a *RuleAction) Validate() error {
+	v := &ValidationError{}
+
+	switch {
+	case strings.HasPrefix(a.Address, "/api/"):
+		v.add("address", a.Address, "must not include the /api/<username> prefix")
+	case !strings.HasPrefix(a.Address, "/"):
+		v.add("address", a.Address, "must start with /")
+	}
+
+	switch a.Method {
+	case "PUT", "POST":
+		v.conflict(len(a.Body) == 0, "body", fmt.Sprintf("is needed by %s actions", a.Method))
+	case "DELETE":
+	default:
+		v.add("method", a.Method, "must be one of POST, PUT or DELETE")
+	}
+
+	return v.err()
+}
+
+// Resources are the resources on a bridge that rules can refer to.
+type Resources struct {
+	Lights    []Light
+	Groups    []Group
+	Sensors   []Sensor
+	Scenes    []Scene
+	Schedules []Schedule
+	Rules     []Rule
+}
+
+// Kinds returns the kinds of resource, such as lights or sensors, the
+// addresses of the rule refer to.
+func (r *Rule) Kinds() []string {
+	seen := map[string]bool{}
+	var kinds []string
+	add := func(address string) {
+		if kind, _, _, ok := splitAddress(address); ok && !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+
+	for _, c := range r.Conditions {
+		add(c.Address)
+	}
+	for _, a := range r.Actions {
+		add(a.Address)
+	}
+
+	return kinds
+}
+
+// CheckAddresses checks the resources the conditions and actions of the
+// rule refer to exist. Sensor conditions are also checked against the
+// state attributes of the sensor's type.
+func (r *Rule) CheckAddresses(res *Resources) error {
+	v := &ValidationError{}
+
+	for i, c := range r.Conditions {
+		if c.Address == LocalTimeAddress {
+			continue
+		}
+
+		field := fmt.Sprintf("conditions.%d.address", i)
+		if reason := res.check(c.Address, true); reason != "" {
+			v.add(field, c.Address, reason)
+		}
+	}
+
+	for i, a := range r.Actions {
+		field := fmt.Sprintf("actions.%d.address", i)
+		if reason := res.check(a.Address, false); reason != "" {
+			v.add(field, a.Address, reason)
+		}
+	}
+
+	return v.err()
+}
+
+// check returns why the address doesn't refer to an existing resource, or
+// an empty string when it does.
+func (res *Resources) check(address string, condition bool) string {
+	kind, id, rest, ok := splitAddress(address)
+	if !ok {
+		return ""
+	}
+
+	missing := fmt.Sprintf("%s %s doesn't exist", strings.TrimSuffix(kind, "s"), id)
+
+	switch kind {
+	case "lights":
+		for _, l := range res.Lights {
+			if strconv.Itoa(l.ID) == id {
+				return ""
+			}
+		}
+	case "groups":
+		if id == strconv.Itoa(AllLightsGroup) {
+			return ""
+		}
+		for _, g := range res.Groups {
+			if strconv.Itoa(g.ID) == id {
+				return ""
+			}
+		}
+	case "sensors":
+		for _, s := range res.Sensors {
+			if strconv.Itoa(s.ID) == id {
+				if condition && strings.HasPrefix(rest, "state/") && !hasStateAttribute(s.Type, rest[len("state/"):]) {
+					return fmt.Sprintf("%s sensors have no state attribute %s", s.Type, rest[len("state/"):])
+				}
+				return ""
+			}
+		}
+	case "scenes":
+		for _, s := range res.Scenes {
+			if s.ID == id {
+				return ""
+			}
+		}
+	case "schedules":
+		for _, s := range res.Schedules {
+			if strconv.Itoa(s.ID) == id {
+				return ""
+			}
+		}
+	case "rules":
+		for _, r := range res.Rules {
+			if strconv.Itoa(r.ID) == id {
+				return ""
+			}
+		}
+	default:
+		// other resources, such as the config, aren't checked
+		return ""
+	}
+
+	return missing
+}
+
+// splitAddress splits an address such as /sensors/2/state/buttonevent
+// into the kind of resource, its identifier and the attribute path.
+func splitAddress(address string) (string, string, string, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(address, "/"), "/", 3)
+	if !strings.HasPrefix(address, "/") || len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", false
+	}
+
+	rest := ""
+	if len(parts) == 3 {
+		rest = parts[2]
+	}
+
+	return parts[0], parts[1], rest, true
+}
+
+// hasStateAttribute reports whether the state of sensors of type t has
+// the attribute, states of types that aren't modelled accept any.
+func hasStateAttribute(t SensorType, attr string) bool {
+	state := NewSensorState(t)
+	if _, ok := state.(*UnknownState); ok || attr == "lastupdated" {
+		return true
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return true
+	}
+
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return true
+	}
+
+	_, ok := attrs[attr]
+	return ok
+}
+
+// prefixed returns the fields of a validation error with their names
+// prefixed.
+func prefixed(err error, prefix string) []FieldError {
+	var fields []FieldError
+	for _, f := range err.(*ValidationError).Fields {
+		f.Field = prefix + f.Field
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	type rule Rule
+	extra, err := decodeExtra(data, (*rule)(r))
+	if err != nil {
+		return err
+	}
+
+	r.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r Rule) MarshalJSON() ([]byte, error) {
+	type rule Rule
+	return encodeExtra(rule(r), r.Extra)
+}
@@ -0,0 +1,219 @@
+package hue
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRule_Validate(t *testing.T) {
+	pressed := Condition{Address: "/sensors/2/state/buttonevent", Operator: OpEq, Value: "1002"}
+	updated := Condition{Address: "/sensors/2/state/lastupdated", Operator: OpDx}
+	allOn := RuleAction{Address: "/groups/0/action", Method: "PUT", Body: map[string]interface{}{"on": true}}
+
+	many := func(n int) []Condition {
+		conditions := make([]Condition, n)
+		for i := range conditions {
+			conditions[i] = pressed
+		}
+		return conditions
+	}
+
+	tests := []struct {
+		name       string
+		rule       Rule
+		wantFields []string
+	}{
+		{
+			name: "button press",
+			rule: Rule{Name: "Dimmer on", Conditions: []Condition{pressed, updated}, Actions: []RuleAction{allOn}},
+		},
+		{
+			name: "time interval",
+			rule: Rule{
+				Conditions: []Condition{
+					{Address: LocalTimeAddress, Operator: OpIn, Value: "W124/T18:00:00/T23:00:00"},
+					{Address: "/sensors/5/state/presence", Operator: OpStable, Value: "PT00:15:00"},
+				},
+				Actions: []RuleAction{{Address: "/scenes/AB34EF5", Method: "DELETE"}},
+			},
+		},
+		{
+			name:       "empty",
+			rule:       Rule{Status: "paused"},
+			wantFields: []string{"status", "conditions", "actions"},
+		},
+		{
+			name:       "too many conditions",
+			rule:       Rule{Conditions: many(MaxRuleConditions + 1), Actions: []RuleAction{allOn}},
+			wantFields: []string{"conditions"},
+		},
+		{
+			name: "operator values",
+			rule: Rule{
+				Conditions: []Condition{
+					{Address: "/sensors/2/state/buttonevent", Operator: OpEq},
+					{Address: "/sensors/3/state/temperature", Operator: OpGt, Value: "21.5"},
+					{Address: "/sensors/2/state/lastupdated", Operator: OpDx, Value: "true"},
+					{Address: "/sensors/5/state/presence", Operator: OpDdx, Value: "15m"},
+					{Address: "/sensors/5/state/presence", Operator: "changed"},
+				},
+				Actions: []RuleAction{allOn},
+			},
+			wantFields: []string{"conditions.0.value", "conditions.1.value", "conditions.2.value", "conditions.3.value", "conditions.4.operator"},
+		},
+		{
+			name: "local time",
+			rule: Rule{
+				Conditions: []Condition{
+					{Address: LocalTimeAddress, Operator: OpEq, Value: "T18:00:00"},
+					{Address: "/sensors/1/state/daylight", Operator: OpNotIn, Value: "T18:00:00/T23:00:00"},
+					{Address: LocalTimeAddress, Operator: OpIn, Value: "18:00-23:00"},
+				},
+				Actions: []RuleAction{allOn},
+			},
+			wantFields: []string{"conditions.0.operator", "conditions.1.operator", "conditions.2.value"},
+		},
+		{
+			name: "invalid address",
+			rule: Rule{
+				Conditions: []Condition{{Address: "sensors", Operator: OpDx}},
+				Actions:    []RuleAction{allOn},
+			},
+			wantFields: []string{"conditions.0.address"},
+		},
+		{
+			name: "invalid actions",
+			rule: Rule{
+				Conditions: []Condition{pressed},
+				Actions: []RuleAction{
+					{Address: "/api/user/groups/0/action", Method: "PUT", Body: map[string]interface{}{"on": true}},
+					{Address: "/lights/1/state", Method: "PUT"},
+					{Address: "lights/1/state", Method: "GET"},
+				},
+			},
+			wantFields: []string{"actions.0.address", "actions.1.body", "actions.2.address", "actions.2.method"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := tt.rule.Validate(); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Rule.Validate() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestRule_CheckAddresses(t *testing.T) {
+	res := &Resources{
+		Lights:  []Light{{ID: 1}},
+		Groups:  []Group{{ID: 1}},
+		Sensors: []Sensor{{ID: 2, Type: ZLLSwitch}, {ID: 9, Type: "ZLLRelativeRotary"}},
+		Scenes:  []Scene{{ID: "AB34EF5"}},
+	}
+
+	tests := []struct {
+		name       string
+		rule       Rule
+		wantKinds  []string
+		wantFields []string
+	}{
+		{
+			name: "existing",
+			rule: Rule{
+				Conditions: []Condition{
+					{Address: "/sensors/2/state/buttonevent", Operator: OpEq, Value: "1002"},
+					{Address: "/sensors/9/state/rotaryevent", Operator: OpDx},
+					{Address: LocalTimeAddress, Operator: OpIn, Value: "T18:00:00/T23:00:00"},
+				},
+				Actions: []RuleAction{
+					{Address: "/groups/0/action", Method: "PUT"},
+					{Address: "/groups/1/action", Method: "PUT"},
+					{Address: "/lights/1/state", Method: "PUT"},
+					{Address: "/scenes/AB34EF5", Method: "DELETE"},
+					{Address: "/config", Method: "PUT"},
+				},
+			},
+			wantKinds: []string{"sensors", "config", "groups", "lights", "scenes"},
+		},
+		{
+			name: "missing",
+			rule: Rule{
+				Conditions: []Condition{
+					{Address: "/sensors/3/state/presence", Operator: OpEq, Value: "true"},
+					{Address: "/sensors/2/state/presence", Operator: OpEq, Value: "true"},
+				},
+				Actions: []RuleAction{
+					{Address: "/groups/4/action", Method: "PUT"},
+					{Address: "/schedules/1", Method: "DELETE"},
+				},
+			},
+			wantKinds:  []string{"sensors", "groups", "schedules"},
+			wantFields: []string{"conditions.0.address", "conditions.1.address", "actions.0.address", "actions.1.address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Kinds(); !reflect.DeepEqual(got, tt.wantKinds) {
+				t.Errorf("Rule.Kinds() = %v, want %v", got, tt.wantKinds)
+			}
+
+			var got []string
+			if err := tt.rule.CheckAddresses(res); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Rule.CheckAddresses() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestRule_JSON(t *testing.T) {
+	data := `{"name":"Dimmer on","owner":"abc","created":"2019-01-02T10:00:00","lasttriggered":"none","timestriggered":3,"status":"enabled","recycle":true,` +
+		`"conditions":[{"address":"/sensors/2/state/lastupdated","operator":"dx"}],` +
+		`"actions":[{"address":"/groups/0/action","method":"PUT","body":{"on":true}}],"flags":1}`
+
+	var r Rule
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := Rule{
+		Name:           "Dimmer on",
+		Owner:          "abc",
+		Created:        "2019-01-02T10:00:00",
+		LastTriggered:  "none",
+		TimesTriggered: 3,
+		Status:         RuleEnabled,
+		Recycle:        true,
+		Conditions:     []Condition{{Address: "/sensors/2/state/lastupdated", Operator: OpDx}},
+		Actions:        []RuleAction{{Address: "/groups/0/action", Method: "PUT", Body: map[string]interface{}{"on": true}}},
+		Extra:          map[string]json.RawMessage{"flags": json.RawMessage("1")},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", r, want)
+	}
+
+	out, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var got, wantRaw map[string]interface{}
+	json.Unmarshal(out, &got)
+	json.Unmarshal([]byte(data), &wantRaw)
+	if !reflect.DeepEqual(got, wantRaw) {
+		t.Errorf("json.Marshal() = %s, want %s", out, data)
+	}
+}
@@ -0,0 +1,177 @@
+package hue
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ScanNone is the scan state of a bridge that hasn't searched for
+	// lights since it started.
+	ScanNone = "none"
+
+	// ScanActive is the scan state while a search is running.
+	ScanActive = "active"
+
+	// MaxSearchSerials is the most serial numbers a single search for
+	// lights accepts.
+	MaxSearchSerials = 10
+
+	// ScanDuration is roughly how long the bridge searches for lights
+	// once a search has been started.
+	ScanDuration = 40 * time.Second
+)
+
+// ScanResult is the outcome of the latest search for lights.
+// GET /api/<username>/lights/new
+type ScanResult struct {
+	// LastScan is ScanNone, ScanActive or the local time the latest
+	// search completed at, formatted as YYYY-MM-DDThh:mm:ss.
+	LastScan string
+
+	// Lights are the lights found by the latest search, ordered by ID.
+	Lights []NewLight
+}
+
+// NewLight is a light found by a search.
+type NewLight struct {
+	ID   int
+	Name string
+}
+
+// Active reports whether the bridge is still searching for lights.
+func (s *ScanResult) Active() bool {
+	return s.LastScan == ScanActive
+}
+
+// Completed returns the time the latest search completed at, in the
+// bridge's local time, and false when no search has completed yet.
+func (s *ScanResult) Completed() (time.Time, bool) {
+	if s.LastScan == ScanNone || s.LastScan == ScanActive {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse("2006-01-02T15:04:05", s.LastScan)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// UnmarshalJSON implements json.Unmarshaler, separating the lastscan
+// entry from the lights the bridge lists alongside it.
+func (s *ScanResult) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = ScanResult{}
+	for key, val := range raw {
+		if key == "lastscan" {
+			if err := json.Unmarshal(val, &s.LastScan); err != nil {
+				return errors.Errorf("failed to read lastscan '%s': %v", val, err)
+			}
+			continue
+		}
+
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return errors.Errorf("failed to parse scan key into identifier '%s'", key)
+		}
+
+		var l struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(val, &l); err != nil {
+			return errors.Errorf("failed to read scan entry '%s': %v", key, err)
+		}
+
+		s.Lights = append(s.Lights, NewLight{
+			ID:   id,
+			Name: l.Name,
+		})
+	}
+
+	sort.Slice(s.Lights, func(i, j int) bool {
+		return s.Lights[i].ID < s.Lights[j].ID
+	})
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, producing the same shape the
+// bridge reports.
+func (s ScanResult) MarshalJSON() ([]byte, error) {
+	raw := make(map[string]interface{}, len(s.Lights)+1)
+	raw["lastscan"] = s.LastScan
+	for _, l := range s.Lights {
+		raw[strconv.Itoa(l.ID)] = struct {
+			Name string `json:"name"`
+		}{
+			Name: l.Name,
+		}
+	}
+
+	return json.Marshal(raw)
+}
+
+// SensorScanResult is the outcome of the latest search for sensors, it
+// is reported in the same shape as a search for lights.
+// GET /api/<username>/sensors/new
+type SensorScanResult struct {
+	// LastScan is ScanNone, ScanActive or the local time the latest
+	// search completed at, formatted as YYYY-MM-DDThh:mm:ss.
+	LastScan string
+
+	// Sensors are the sensors found by the latest search, ordered by ID.
+	Sensors []NewSensor
+}
+
+// NewSensor is a sensor found by a search.
+type NewSensor struct {
+	ID   int
+	Name string
+}
+
+// Active reports whether the bridge is still searching for sensors.
+func (s *SensorScanResult) Active() bool {
+	return s.LastScan == ScanActive
+}
+
+// Completed returns the time the latest search completed at, in the
+// bridge's local time, and false when no search has completed yet.
+func (s *SensorScanResult) Completed() (time.Time, bool) {
+	return (&ScanResult{LastScan: s.LastScan}).Completed()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *SensorScanResult) UnmarshalJSON(data []byte) error {
+	var scan ScanResult
+	if err := scan.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	*s = SensorScanResult{LastScan: scan.LastScan}
+	for _, l := range scan.Lights {
+		s.Sensors = append(s.Sensors, NewSensor(l))
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, producing the same shape the
+// bridge reports.
+func (s SensorScanResult) MarshalJSON() ([]byte, error) {
+	scan := ScanResult{LastScan: s.LastScan}
+	for _, n := range s.Sensors {
+		scan.Lights = append(scan.Lights, NewLight(n))
+	}
+
+	return scan.MarshalJSON()
+}
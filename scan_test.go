@@ -0,0 +1,103 @@
+package hue
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanResult_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		want          ScanResult
+		wantActive    bool
+		wantCompleted time.Time
+		wantErr       bool
+	}{
+		{
+			name: "never scanned",
+			data: `{"lastscan": "none"}`,
+			want: ScanResult{LastScan: ScanNone},
+		},
+		{
+			name:       "active",
+			data:       `{"lastscan": "active", "7": {"name": "Hue Lamp 7"}}`,
+			want:       ScanResult{LastScan: ScanActive, Lights: []NewLight{{ID: 7, Name: "Hue Lamp 7"}}},
+			wantActive: true,
+		},
+		{
+			name: "completed",
+			data: `{"12": {"name": "Hue Lamp 12"}, "lastscan": "2012-10-29T12:00:00", "7": {"name": "Hue Lamp 7"}}`,
+			want: ScanResult{
+				LastScan: "2012-10-29T12:00:00",
+				Lights:   []NewLight{{ID: 7, Name: "Hue Lamp 7"}, {ID: 12, Name: "Hue Lamp 12"}},
+			},
+			wantCompleted: time.Date(2012, 10, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid light key",
+			data:    `{"lastscan": "none", "lamp": {"name": "Hue Lamp"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ScanResult
+			if err := json.Unmarshal([]byte(tt.data), &got); (err != nil) != tt.wantErr {
+				t.Fatalf("ScanResult.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanResult.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+			if got.Active() != tt.wantActive {
+				t.Errorf("ScanResult.Active() = %v, want %v", got.Active(), tt.wantActive)
+			}
+			completed, ok := got.Completed()
+			if ok != !tt.wantCompleted.IsZero() || !completed.Equal(tt.wantCompleted) {
+				t.Errorf("ScanResult.Completed() = %v, %v, want %v", completed, ok, tt.wantCompleted)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("ScanResult.MarshalJSON() error = %v", err)
+			}
+
+			var again ScanResult
+			if err := json.Unmarshal(data, &again); err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("ScanResult round trip = %+v, %v, want %+v", again, err, got)
+			}
+		})
+	}
+}
+
+func TestSensorScanResult_UnmarshalJSON(t *testing.T) {
+	var got SensorScanResult
+	data := `{"lastscan": "2019-03-01T10:20:30", "7": {"name": "Hue temperature sensor 1"}, "5": {"name": "Hue motion sensor 1"}}`
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("SensorScanResult.UnmarshalJSON() error = %v", err)
+	}
+
+	want := SensorScanResult{
+		LastScan: "2019-03-01T10:20:30",
+		Sensors: []NewSensor{
+			{ID: 5, Name: "Hue motion sensor 1"},
+			{ID: 7, Name: "Hue temperature sensor 1"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SensorScanResult.UnmarshalJSON() = %+v, want %+v", got, want)
+	}
+
+	if got.Active() {
+		t.Errorf("SensorScanResult.Active() = true")
+	}
+
+	if _, ok := got.Completed(); !ok {
+		t.Errorf("SensorScanResult.Completed() = false")
+	}
+}
@@ -0,0 +1,138 @@
+package hue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// SceneType is the kind of a scene, which decides how its lights are
+// chosen.
+type SceneType string
+
+const (
+	// LightScene is a scene on an explicit list of lights.
+	LightScene SceneType = "LightScene"
+
+	// GroupScene is a scene bound to a group, its lights follow the
+	// lights of the group.
+	GroupScene SceneType = "GroupScene"
+)
+
+// maxAppDataLength is the longest appdata data the bridge accepts.
+const maxAppDataLength = 16
+
+// Scene is a stored set of light states that can be recalled at once.
+type Scene struct {
+	// ID is the identifier of the scene on the bridge, it isn't part of
+	// the scene's attributes.
+	ID string `json:"-"`
+
+	Name        string    `json:"name"`
+	Type        SceneType `json:"type,omitempty"`
+	Group       string    `json:"group,omitempty"`
+	Lights      []string  `json:"lights"`
+	Owner       string    `json:"owner,omitempty"`
+	Recycle     bool      `json:"recycle"`
+	Locked      bool      `json:"locked"`
+	AppData     AppData   `json:"appdata"`
+	Picture     string    `json:"picture,omitempty"`
+	LastUpdated string    `json:"lastupdated,omitempty"`
+	Version     int       `json:"version,omitempty"`
+
+	// LightStates are the states recalled on each light, keyed by light
+	// ID. They're only reported when a single scene is fetched.
+	LightStates LightStates `json:"lightstates,omitempty"`
+
+	// Extra holds any attribute the bridge reported that isn't modelled
+	// above, it is written back out when the scene is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// AppData is free form data an application stores with a scene.
+type AppData struct {
+	Version int    `json:"version,omitempty"`
+	Data    string `json:"data,omitempty"`
+}
+
+// LightStates maps light IDs to the state a scene recalls on them.
+type LightStates map[string]StateUpdate
+
+// Lights returns the IDs of the lights with a state, in order.
+func (l LightStates) Lights() []string {
+	ids := make([]string, 0, len(l))
+	for id := range l {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// Validate checks the attributes of the scene that can be written to the
+// bridge.
+func (s *Scene) Validate() error {
+	v := &ValidationError{}
+
+	if err := ValidateName(s.Name); err != nil {
+		v.Fields = append(v.Fields, err.(*ValidationError).Fields...)
+	}
+
+	switch s.Type {
+	case "", LightScene:
+		v.conflict(s.Group != "", "group", "can only be set on group scenes")
+		v.conflict(len(s.Lights) == 0, "lights", "light scenes need at least one light")
+	case GroupScene:
+		v.conflict(s.Group == "", "group", "group scenes need a group")
+	default:
+		v.add("type", s.Type, "must be one of LightScene or GroupScene")
+	}
+
+	if utf8.RuneCountInString(s.AppData.Data) > maxAppDataLength {
+		v.add("appdata.data", s.AppData.Data, fmt.Sprintf("must be at most %d characters", maxAppDataLength))
+	}
+
+	members := make(map[string]bool, len(s.Lights))
+	for _, l := range s.Lights {
+		members[l] = true
+	}
+
+	for _, id := range s.LightStates.Lights() {
+		state := s.LightStates[id]
+		field := fmt.Sprintf("lightstates.%s", id)
+
+		if len(s.Lights) > 0 && !members[id] {
+			v.add(field, id, "isn't one of the scene's lights")
+		}
+
+		v.conflict(state.Scene != "", field+".scene", "can't be stored in a scene")
+
+		if err := state.Validate(nil); err != nil {
+			for _, f := range err.(*ValidationError).Fields {
+				f.Field = field + "." + f.Field
+				v.Fields = append(v.Fields, f)
+			}
+		}
+	}
+
+	return v.err()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Scene) UnmarshalJSON(data []byte) error {
+	type scene Scene
+	extra, err := decodeExtra(data, (*scene)(s))
+	if err != nil {
+		return err
+	}
+
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Scene) MarshalJSON() ([]byte, error) {
+	type scene Scene
+	return encodeExtra(scene(s), s.Extra)
+}
@@ -0,0 +1,84 @@
+package hue
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestScene_Validate(t *testing.T) {
+	on := true
+	bri := 0
+
+	tests := []struct {
+		name       string
+		scene      Scene
+		wantFields []string
+	}{
+		{
+			name:  "light scene",
+			scene: Scene{Name: "Reading", Lights: []string{"1"}, LightStates: LightStates{"1": {On: &on}}},
+		},
+		{
+			name:  "group scene",
+			scene: Scene{Name: "Evening", Type: GroupScene, Group: "3"},
+		},
+		{
+			name:       "light scene without lights",
+			scene:      Scene{Name: "Reading", Group: "3"},
+			wantFields: []string{"group", "lights"},
+		},
+		{
+			name:       "unknown type",
+			scene:      Scene{Name: "Reading", Type: "Zone"},
+			wantFields: []string{"type"},
+		},
+		{
+			name: "invalid light states",
+			scene: Scene{Name: "Reading", Lights: []string{"1"}, AppData: AppData{Data: "far too much appdata"}, LightStates: LightStates{
+				"1": {Bri: &bri, Scene: "other"},
+				"2": {On: &on},
+			}},
+			wantFields: []string{"appdata.data", "lightstates.1.scene", "lightstates.1.bri", "lightstates.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := tt.scene.Validate(); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Scene.Validate() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestScene_MarshalJSON(t *testing.T) {
+	data := `{"name":"Reading","type":"LightScene","lights":["1"],"recycle":false,"locked":true,"appdata":{"version":1,"data":"x"},"version":2,"lightstates":{"1":{"on":true}},"image":"abc"}`
+
+	var s Scene
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatalf("Scene.UnmarshalJSON() error = %v", err)
+	}
+
+	if !s.Locked || len(s.LightStates) != 1 || string(s.Extra["image"]) != `"abc"` {
+		t.Errorf("Scene.UnmarshalJSON() = %+v", s)
+	}
+
+	out, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Scene.MarshalJSON() error = %v", err)
+	}
+
+	var got, want map[string]interface{}
+	json.Unmarshal(out, &got)
+	json.Unmarshal([]byte(data), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scene.MarshalJSON() = %s, want %s", out, data)
+	}
+}
@@ -0,0 +1,147 @@
+package hue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ninnemana/huego/timepattern"
+)
+
+// Schedule statuses.
+const (
+	ScheduleEnabled  = "enabled"
+	ScheduleDisabled = "disabled"
+)
+
+// maxDescriptionLength is the longest description, in characters, the
+// bridge accepts for a schedule.
+const maxDescriptionLength = 64
+
+// Schedule triggers a command on the bridge at the times described by its
+// time pattern.
+type Schedule struct {
+	// ID is the identifier of the schedule on the bridge, it isn't part
+	// of the schedule's attributes.
+	ID int `json:"-"`
+
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Command     Command `json:"command"`
+
+	// LocalTime is the time pattern in the bridge's timezone, see the
+	// timepattern package. Time is the deprecated UTC equivalent the
+	// bridge still reports.
+	LocalTime string `json:"localtime"`
+	Time      string `json:"time,omitempty"`
+
+	Created    string `json:"created,omitempty"`
+	Status     string `json:"status,omitempty"`
+	AutoDelete *bool  `json:"autodelete,omitempty"`
+	StartTime  string `json:"starttime,omitempty"`
+	Recycle    bool   `json:"recycle,omitempty"`
+
+	// Extra holds any attribute the bridge reported that isn't modelled
+	// above, it is written back out when the schedule is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Command is a request the bridge sends to itself when a schedule or rule
+// fires.
+type Command struct {
+	// Address is the resource the command is sent to, including the
+	// /api/<username> prefix.
+	Address string `json:"address"`
+
+	// Method is the HTTP method of the command, POST, PUT or DELETE.
+	Method string `json:"method"`
+
+	Body map[string]interface{} `json:"body"`
+}
+
+// Pattern parses the local time pattern of the schedule, absolute times
+// are read in loc, the bridge's timezone.
+func (s *Schedule) Pattern(loc *time.Location) (timepattern.Pattern, error) {
+	return timepattern.Parse(s.LocalTime, loc)
+}
+
+// SetPattern sets the local time pattern of the schedule.
+func (s *Schedule) SetPattern(p timepattern.Pattern) {
+	s.LocalTime = p.String()
+}
+
+// CreatedAt returns when the schedule was created, the bridge reports it
+// in UTC.
+func (s *Schedule) CreatedAt() (time.Time, bool) {
+	t, err := timepattern.ParseLocal(s.Created, time.UTC)
+	return t, err == nil
+}
+
+// Validate checks the attributes of the schedule that can be written to
+// the bridge.
+func (s *Schedule) Validate() error {
+	v := &ValidationError{}
+
+	if utf8.RuneCountInString(s.Name) > MaxNameLength {
+		v.add("name", s.Name, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	}
+
+	if utf8.RuneCountInString(s.Description) > maxDescriptionLength {
+		v.add("description", s.Description, fmt.Sprintf("must be at most %d characters", maxDescriptionLength))
+	}
+
+	if err := s.Command.Validate(); err != nil {
+		v.Fields = append(v.Fields, err.(*ValidationError).Fields...)
+	}
+
+	if s.LocalTime == "" {
+		v.add("localtime", s.LocalTime, "can't be empty")
+	} else if _, err := timepattern.Parse(s.LocalTime, nil); err != nil {
+		v.add("localtime", s.LocalTime, err.Error())
+	}
+
+	switch s.Status {
+	case "", ScheduleEnabled, ScheduleDisabled:
+	default:
+		v.add("status", s.Status, "must be one of enabled or disabled")
+	}
+
+	return v.err()
+}
+
+// Validate checks the command can be sent by the bridge.
+func (c *Command) Validate() error {
+	v := &ValidationError{}
+
+	if !strings.HasPrefix(c.Address, "/api/") {
+		v.add("command.address", c.Address, "must start with /api/<username>")
+	}
+
+	switch c.Method {
+	case "POST", "PUT", "DELETE":
+	default:
+		v.add("command.method", c.Method, "must be one of POST, PUT or DELETE")
+	}
+
+	return v.err()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Schedule) UnmarshalJSON(data []byte) error {
+	type schedule Schedule
+	extra, err := decodeExtra(data, (*schedule)(s))
+	if err != nil {
+		return err
+	}
+
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	type schedule Schedule
+	return encodeExtra(schedule(s), s.Extra)
+}
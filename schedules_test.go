@@ -0,0 +1,79 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ninnemana/huego/timepattern"
+)
+
+func TestSchedule_Validate(t *testing.T) {
+	command := Command{
+		Address: "/api/user/groups/0/action",
+		Method:  "PUT",
+		Body:    map[string]interface{}{"on": true},
+	}
+
+	tests := []struct {
+		name       string
+		schedule   Schedule
+		wantFields []string
+	}{
+		{
+			name:     "recurring",
+			schedule: Schedule{Name: "Wake up", Command: command, LocalTime: "W124/T07:00:00"},
+		},
+		{
+			name:       "missing time",
+			schedule:   Schedule{Command: command},
+			wantFields: []string{"localtime"},
+		},
+		{
+			name:       "invalid command",
+			schedule:   Schedule{Command: Command{Address: "/lights/1/state", Method: "GET"}, LocalTime: "PT00:01:00"},
+			wantFields: []string{"command.address", "command.method"},
+		},
+		{
+			name:       "long description and unknown status",
+			schedule:   Schedule{Description: "a description that goes on for far longer than the bridge allows us", Command: command, LocalTime: "PT00:01:00", Status: "paused"},
+			wantFields: []string{"description", "status"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := tt.schedule.Validate(); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Schedule.Validate() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestSchedule_Pattern(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	s := &Schedule{}
+	s.SetPattern(timepattern.At(time.Date(2020, 6, 1, 6, 30, 0, 0, time.UTC)).Randomize(time.Minute))
+	if s.LocalTime != "2020-06-01T06:30:00A00:01:00" {
+		t.Fatalf("Schedule.SetPattern() = %s", s.LocalTime)
+	}
+
+	p, err := s.Pattern(loc)
+	if err != nil {
+		t.Fatalf("Schedule.Pattern() error = %v", err)
+	}
+
+	if want := time.Date(2020, 6, 1, 4, 30, 0, 0, time.UTC); !p.Time.Equal(want) {
+		t.Errorf("Schedule.Pattern() time = %v, want %v", p.Time, want)
+	}
+}
@@ -0,0 +1,506 @@
+package hue
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ninnemana/huego/timepattern"
+)
+
+// SensorType is the kind of a sensor, which decides the attributes of its
+// state and config.
+type SensorType string
+
+// Sensor types.
+const (
+	// ZLLPresence is the motion detector of a Hue motion sensor.
+	ZLLPresence SensorType = "ZLLPresence"
+
+	// ZLLLightLevel is the ambient light sensor of a Hue motion sensor.
+	ZLLLightLevel SensorType = "ZLLLightLevel"
+
+	// ZLLTemperature is the thermometer of a Hue motion sensor.
+	ZLLTemperature SensorType = "ZLLTemperature"
+
+	// ZLLSwitch is a battery powered switch, such as the dimmer switch or
+	// the smart button.
+	ZLLSwitch SensorType = "ZLLSwitch"
+
+	// ZGPSwitch is a switch powered by its own button presses, such as the
+	// Hue tap and Friends of Hue switches.
+	ZGPSwitch SensorType = "ZGPSwitch"
+
+	// Daylight is the bridge's built-in sensor, it reports whether the sun
+	// is up at the configured location.
+	Daylight SensorType = "Daylight"
+
+	// CLIPGenericFlag, CLIPGenericStatus, CLIPPresence, CLIPLightLevel,
+	// CLIPTemperature, CLIPHumidity, CLIPOpenClose and CLIPSwitch are
+	// virtual sensors whose state is written through the API, mostly
+	// used by rules.
+	CLIPGenericFlag   SensorType = "CLIPGenericFlag"
+	CLIPGenericStatus SensorType = "CLIPGenericStatus"
+	CLIPPresence      SensorType = "CLIPPresence"
+	CLIPLightLevel    SensorType = "CLIPLightLevel"
+	CLIPTemperature   SensorType = "CLIPTemperature"
+	CLIPHumidity      SensorType = "CLIPHumidity"
+	CLIPOpenClose     SensorType = "CLIPOpenClose"
+	CLIPSwitch        SensorType = "CLIPSwitch"
+)
+
+// CLIP reports whether sensors of the type are virtual sensors created
+// through the API.
+func (t SensorType) CLIP() bool {
+	return strings.HasPrefix(string(t), "CLIP")
+}
+
+// Sensor is a physical or virtual sensor registered on the bridge.
+// GET /api/<username>/sensors/<id>
+type Sensor struct {
+	// ID is the identifier of the sensor on the bridge, it isn't part of
+	// the sensor's attributes.
+	ID int `json:"-"`
+
+	Name             string     `json:"name"`
+	Type             SensorType `json:"type"`
+	ModelID          string     `json:"modelid"`
+	ManufacturerName string     `json:"manufacturername"`
+	ProductName      string     `json:"productname,omitempty"`
+	SWVersion        string     `json:"swversion"`
+	UniqueID         string     `json:"uniqueid,omitempty"`
+	Recycle          bool       `json:"recycle,omitempty"`
+
+	// State is one of the state types below depending on the sensor
+	// type, *UnknownState for types that aren't modelled.
+	State SensorState `json:"state"`
+
+	Config SensorConfig `json:"config"`
+
+	// Extra holds any attribute the bridge reported that isn't modelled
+	// above, it is written back out when the sensor is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// SensorState is the state of a sensor.
+type SensorState interface {
+	// Updated returns when the state last changed, it is false when the
+	// sensor never reported a state.
+	Updated() (time.Time, bool)
+}
+
+// StateTime is the time a sensor state last changed, it is part of every
+// sensor state.
+type StateTime struct {
+	LastUpdated string `json:"lastupdated,omitempty"`
+}
+
+// Updated implements SensorState, the bridge reports the time in UTC.
+func (s StateTime) Updated() (time.Time, bool) {
+	t, err := timepattern.ParseLocal(s.LastUpdated, time.UTC)
+	return t, err == nil
+}
+
+// PresenceState is the state of ZLLPresence and CLIPPresence sensors.
+type PresenceState struct {
+	StateTime
+	Presence bool `json:"presence"`
+}
+
+// LightLevelState is the state of ZLLLightLevel and CLIPLightLevel
+// sensors. Dark and Daylight are derived by the bridge from the light
+// level and the tholddark and tholdoffset config.
+type LightLevelState struct {
+	StateTime
+	LightLevel int  `json:"lightlevel"`
+	Dark       bool `json:"dark"`
+	Daylight   bool `json:"daylight"`
+}
+
+// Lux converts the light level, 10000*log10(lux)+1, to lux.
+func (s *LightLevelState) Lux() float64 {
+	return LightLevelToLux(s.LightLevel)
+}
+
+// LightLevelToLux converts a light level as reported by sensors to lux.
+func LightLevelToLux(level int) float64 {
+	if level <= 0 {
+		return 0
+	}
+
+	return math.Pow(10, float64(level-1)/10000)
+}
+
+// LuxToLightLevel converts lux to the light level scale used by sensors
+// and the tholddark config.
+func LuxToLightLevel(lux float64) int {
+	if lux <= 0 {
+		return 0
+	}
+
+	return int(math.Round(10000*math.Log10(lux))) + 1
+}
+
+// TemperatureState is the state of ZLLTemperature and CLIPTemperature
+// sensors.
+type TemperatureState struct {
+	StateTime
+
+	// Temperature is in hundredths of a degree Celsius.
+	Temperature int `json:"temperature"`
+}
+
+// Celsius returns the temperature in degrees Celsius.
+func (s *TemperatureState) Celsius() float64 {
+	return float64(s.Temperature) / 100
+}
+
+// HumidityState is the state of CLIPHumidity sensors.
+type HumidityState struct {
+	StateTime
+
+	// Humidity is the relative humidity in hundredths of a percent.
+	Humidity int `json:"humidity"`
+}
+
+// Percent returns the relative humidity in percent.
+func (s *HumidityState) Percent() float64 {
+	return float64(s.Humidity) / 100
+}
+
+// OpenCloseState is the state of CLIPOpenClose sensors.
+type OpenCloseState struct {
+	StateTime
+	Open bool `json:"open"`
+}
+
+// SwitchState is the state of ZLLSwitch, ZGPSwitch and CLIPSwitch
+// sensors, the last button event they reported.
+type SwitchState struct {
+	StateTime
+	ButtonEvent int `json:"buttonevent"`
+}
+
+// DaylightState is the state of the Daylight sensor, Daylight is nil
+// until the sensor is configured with a location.
+type DaylightState struct {
+	StateTime
+	Daylight *bool `json:"daylight"`
+}
+
+// FlagState is the state of CLIPGenericFlag sensors.
+type FlagState struct {
+	StateTime
+	Flag bool `json:"flag"`
+}
+
+// StatusState is the state of CLIPGenericStatus sensors.
+type StatusState struct {
+	StateTime
+	Status int `json:"status"`
+}
+
+// UnknownState holds the state of sensor types that aren't modelled.
+type UnknownState struct {
+	StateTime
+	Attributes map[string]json.RawMessage
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *UnknownState) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.StateTime); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &s.Attributes)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s UnknownState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Attributes)
+}
+
+// NewSensorState returns an empty state of the type used by sensors of
+// type t.
+func NewSensorState(t SensorType) SensorState {
+	switch t {
+	case ZLLPresence, CLIPPresence:
+		return &PresenceState{}
+	case ZLLLightLevel, CLIPLightLevel:
+		return &LightLevelState{}
+	case ZLLTemperature, CLIPTemperature:
+		return &TemperatureState{}
+	case CLIPHumidity:
+		return &HumidityState{}
+	case CLIPOpenClose:
+		return &OpenCloseState{}
+	case ZLLSwitch, ZGPSwitch, CLIPSwitch:
+		return &SwitchState{}
+	case Daylight:
+		return &DaylightState{}
+	case CLIPGenericFlag:
+		return &FlagState{}
+	case CLIPGenericStatus:
+		return &StatusState{}
+	default:
+		return &UnknownState{}
+	}
+}
+
+// SensorConfig is the configuration of a sensor, each sensor type only
+// reports the attributes that apply to it.
+type SensorConfig struct {
+	On        bool  `json:"on"`
+	Reachable *bool `json:"reachable,omitempty"`
+
+	// Battery is the remaining charge in percent.
+	Battery *int `json:"battery,omitempty"`
+
+	Alert         string   `json:"alert,omitempty"`
+	LEDIndication *bool    `json:"ledindication,omitempty"`
+	UserTest      *bool    `json:"usertest,omitempty"`
+	Pending       []string `json:"pending,omitempty"`
+
+	// Sensitivity of ZLLPresence sensors, from 0 to SensitivityMax.
+	Sensitivity    *int `json:"sensitivity,omitempty"`
+	SensitivityMax *int `json:"sensitivitymax,omitempty"`
+
+	// TholdDark and TholdOffset of ZLLLightLevel sensors, as light
+	// levels. It is dark below TholdDark and daylight above TholdDark
+	// plus TholdOffset.
+	TholdDark   *int `json:"tholddark,omitempty"`
+	TholdOffset *int `json:"tholdoffset,omitempty"`
+
+	// Configured, SunriseOffset and SunsetOffset, in minutes, of the
+	// Daylight sensor.
+	Configured    *bool `json:"configured,omitempty"`
+	SunriseOffset *int  `json:"sunriseoffset,omitempty"`
+	SunsetOffset  *int  `json:"sunsetoffset,omitempty"`
+
+	// URL of CLIP sensors.
+	URL string `json:"url,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// SensorConfigUpdate is the body of a configuration write to a sensor.
+// PUT /api/<username>/sensors/<id>/config
+type SensorConfigUpdate struct {
+	On            *bool  `json:"on,omitempty"`
+	Alert         string `json:"alert,omitempty"`
+	LEDIndication *bool  `json:"ledindication,omitempty"`
+	Sensitivity   *int   `json:"sensitivity,omitempty"`
+	TholdDark     *int   `json:"tholddark,omitempty"`
+	TholdOffset   *int   `json:"tholdoffset,omitempty"`
+
+	// Battery, Reachable and URL can only be written to CLIP sensors,
+	// physical sensors report their own.
+	Battery   *int   `json:"battery,omitempty"`
+	Reachable *bool  `json:"reachable,omitempty"`
+	URL       string `json:"url,omitempty"`
+
+	// Lat, Long, SunriseOffset and SunsetOffset configure the Daylight
+	// sensor. The coordinates are written as degrees with four decimals
+	// followed by the hemisphere, such as 52.3740N and 004.8897E.
+	Lat           string `json:"lat,omitempty"`
+	Long          string `json:"long,omitempty"`
+	SunriseOffset *int   `json:"sunriseoffset,omitempty"`
+	SunsetOffset  *int   `json:"sunsetoffset,omitempty"`
+}
+
+var (
+	macPrefix = regexp.MustCompile(`^(?i)((?:[0-9a-f]{2}:){5,7}[0-9a-f]{2})(?:-|$)`)
+	latitude  = regexp.MustCompile(`^\d{1,3}\.\d{4}[NS]$`)
+	longitude = regexp.MustCompile(`^\d{1,3}\.\d{4}[EW]$`)
+)
+
+// defaultSensitivityMax is the highest sensitivity of presence sensors
+// that don't report sensitivitymax.
+const defaultSensitivityMax = 2
+
+// Validate checks the update against the ranges documented by the Hue
+// API. When s is provided, attributes the sensor doesn't report in its
+// config, or can't have written, are rejected and the sensitivity is
+// checked against the maximum of the sensor.
+func (u *SensorConfigUpdate) Validate(s *Sensor) error {
+	v := &ValidationError{}
+
+	if u == nil {
+		v.add("config", nil, "is required")
+		return v
+	}
+
+	switch u.Alert {
+	case "", "none", "select", "lselect":
+	default:
+		v.add("alert", u.Alert, "must be one of none, select or lselect")
+	}
+
+	v.check(u.TholdDark, "tholddark", 0, 65535)
+	v.check(u.TholdOffset, "tholdoffset", 1, 65535)
+	v.check(u.Battery, "battery", 0, 100)
+	v.check(u.SunriseOffset, "sunriseoffset", -120, 120)
+	v.check(u.SunsetOffset, "sunsetoffset", -120, 120)
+
+	if u.Lat != "" && !latitude.MatchString(u.Lat) {
+		v.add("lat", u.Lat, "must be degrees with four decimals followed by N or S")
+	}
+
+	if u.Long != "" && !longitude.MatchString(u.Long) {
+		v.add("long", u.Long, "must be degrees with four decimals followed by E or W")
+	}
+
+	v.conflict((u.Lat == "") != (u.Long == ""), "lat", "must be set along with long")
+
+	if s == nil {
+		return v.err()
+	}
+
+	c := s.Config
+	sensitivityMax := defaultSensitivityMax
+	if c.SensitivityMax != nil {
+		sensitivityMax = *c.SensitivityMax
+	}
+	v.check(u.Sensitivity, "sensitivity", 0, sensitivityMax)
+
+	v.conflict(u.LEDIndication != nil && c.LEDIndication == nil, "ledindication", "sensor doesn't have an indicator led")
+	v.conflict(u.Sensitivity != nil && c.Sensitivity == nil, "sensitivity", "sensor doesn't support configuring sensitivity")
+	v.conflict(u.TholdDark != nil && c.TholdDark == nil, "tholddark", "sensor doesn't measure light levels")
+	v.conflict(u.TholdOffset != nil && c.TholdOffset == nil, "tholdoffset", "sensor doesn't measure light levels")
+
+	clip := s.Type.CLIP()
+	v.conflict(u.Battery != nil && !clip, "battery", "can only be written to CLIP sensors")
+	v.conflict(u.Reachable != nil && !clip, "reachable", "can only be written to CLIP sensors")
+	v.conflict(u.URL != "" && !clip, "url", "can only be written to CLIP sensors")
+
+	daylight := s.Type == Daylight
+	v.conflict(u.Lat != "" && !daylight, "lat", "can only be written to the daylight sensor")
+	v.conflict(u.SunriseOffset != nil && !daylight, "sunriseoffset", "can only be written to the daylight sensor")
+	v.conflict(u.SunsetOffset != nil && !daylight, "sunsetoffset", "can only be written to the daylight sensor")
+
+	return v.err()
+}
+
+// Validate checks the attributes needed to create a sensor, only CLIP
+// sensors can be created through the API.
+func (s *Sensor) Validate() error {
+	v := &ValidationError{}
+
+	if err := ValidateName(s.Name); err != nil {
+		v.Fields = append(v.Fields, err.(*ValidationError).Fields...)
+	}
+
+	if !s.Type.CLIP() {
+		v.add("type", s.Type, "only CLIP sensors can be created")
+	}
+
+	v.conflict(s.ModelID == "", "modelid", "can't be empty")
+	v.conflict(s.ManufacturerName == "", "manufacturername", "can't be empty")
+	v.conflict(s.SWVersion == "", "swversion", "can't be empty")
+	v.conflict(s.UniqueID == "", "uniqueid", "can't be empty")
+
+	return v.err()
+}
+
+// MAC returns the hardware address the unique ID of the sensor starts
+// with, it is shared by every sensor entry of a physical device. It is
+// false for sensors whose unique ID isn't a hardware address, such as
+// most CLIP sensors.
+func (s *Sensor) MAC() (string, bool) {
+	m := macPrefix.FindStringSubmatch(s.UniqueID)
+	if m == nil {
+		return "", false
+	}
+
+	return strings.ToLower(m[1]), true
+}
+
+// SensorDevice is a physical device along with the sensor entries it
+// registered, such as the presence, light level and temperature entries
+// of a motion sensor.
+type SensorDevice struct {
+	// MAC is the hardware address of the device, it is empty for a
+	// sensor whose unique ID doesn't carry one.
+	MAC              string
+	ModelID          string
+	ManufacturerName string
+
+	// Sensors are the entries of the device, in the order they were
+	// given.
+	Sensors []Sensor
+}
+
+// GroupSensors groups sensor entries by the device that registered them,
+// devices are ordered by their first entry. Sensors without a hardware
+// address form a device of their own.
+func GroupSensors(sensors []Sensor) []SensorDevice {
+	var devices []SensorDevice
+	index := map[string]int{}
+	for _, s := range sensors {
+		mac, ok := s.MAC()
+		if ok {
+			if i, found := index[mac]; found {
+				devices[i].Sensors = append(devices[i].Sensors, s)
+				continue
+			}
+
+			index[mac] = len(devices)
+		}
+
+		devices = append(devices, SensorDevice{
+			MAC:              mac,
+			ModelID:          s.ModelID,
+			ManufacturerName: s.ManufacturerName,
+			Sensors:          []Sensor{s},
+		})
+	}
+
+	return devices
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the state is decoded into
+// the type matching the sensor's type.
+func (s *Sensor) UnmarshalJSON(data []byte) error {
+	var kind struct {
+		Type SensorType `json:"type"`
+	}
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return err
+	}
+
+	type sensor Sensor
+	s.State = NewSensorState(kind.Type)
+	extra, err := decodeExtra(data, (*sensor)(s))
+	if err != nil {
+		return err
+	}
+
+	s.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Sensor) MarshalJSON() ([]byte, error) {
+	type sensor Sensor
+	return encodeExtra(sensor(s), s.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *SensorConfig) UnmarshalJSON(data []byte) error {
+	type config SensorConfig
+	extra, err := decodeExtra(data, (*config)(c))
+	if err != nil {
+		return err
+	}
+
+	c.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c SensorConfig) MarshalJSON() ([]byte, error) {
+	type config SensorConfig
+	return encodeExtra(config(c), c.Extra)
+}
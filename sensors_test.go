@@ -0,0 +1,245 @@
+package hue
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSensor_UnmarshalJSON(t *testing.T) {
+	yes := true
+
+	tests := []struct {
+		name string
+		data string
+		want SensorState
+	}{
+		{
+			name: "presence",
+			data: `{"type": "ZLLPresence", "state": {"presence": true, "lastupdated": "2019-03-01T10:20:30"}}`,
+			want: &PresenceState{StateTime: StateTime{LastUpdated: "2019-03-01T10:20:30"}, Presence: true},
+		},
+		{
+			name: "light level",
+			data: `{"type": "ZLLLightLevel", "state": {"lightlevel": 14002, "dark": true, "daylight": false}}`,
+			want: &LightLevelState{LightLevel: 14002, Dark: true},
+		},
+		{
+			name: "temperature",
+			data: `{"type": "ZLLTemperature", "state": {"temperature": 2153}}`,
+			want: &TemperatureState{Temperature: 2153},
+		},
+		{
+			name: "tap",
+			data: `{"type": "ZGPSwitch", "state": {"buttonevent": 34}}`,
+			want: &SwitchState{ButtonEvent: 34},
+		},
+		{
+			name: "daylight",
+			data: `{"type": "Daylight", "state": {"daylight": true, "lastupdated": "none"}}`,
+			want: &DaylightState{StateTime: StateTime{LastUpdated: "none"}, Daylight: &yes},
+		},
+		{
+			name: "generic flag",
+			data: `{"type": "CLIPGenericFlag", "state": {"flag": true}}`,
+			want: &FlagState{Flag: true},
+		},
+		{
+			name: "generic status",
+			data: `{"type": "CLIPGenericStatus", "state": {"status": 2}}`,
+			want: &StatusState{Status: 2},
+		},
+		{
+			name: "humidity",
+			data: `{"type": "CLIPHumidity", "state": {"humidity": 4250}}`,
+			want: &HumidityState{Humidity: 4250},
+		},
+		{
+			name: "open close",
+			data: `{"type": "CLIPOpenClose", "state": {"open": true}}`,
+			want: &OpenCloseState{Open: true},
+		},
+		{
+			name: "unknown",
+			data: `{"type": "ZLLRelativeRotary", "state": {"rotaryevent": 1}}`,
+			want: &UnknownState{Attributes: map[string]json.RawMessage{"rotaryevent": json.RawMessage(`1`)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Sensor
+			if err := json.Unmarshal([]byte(tt.data), &s); err != nil {
+				t.Fatalf("Sensor.UnmarshalJSON() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(s.State, tt.want) {
+				t.Errorf("Sensor.UnmarshalJSON() state = %#v, want %#v", s.State, tt.want)
+			}
+
+			out, err := json.Marshal(s)
+			if err != nil {
+				t.Fatalf("Sensor.MarshalJSON() error = %v", err)
+			}
+
+			var again Sensor
+			if err := json.Unmarshal(out, &again); err != nil {
+				t.Fatalf("Sensor.UnmarshalJSON() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(again.State, tt.want) {
+				t.Errorf("Sensor.MarshalJSON() state = %s", out)
+			}
+		})
+	}
+}
+
+func TestStateTime_Updated(t *testing.T) {
+	got, ok := StateTime{LastUpdated: "2019-03-01T10:20:30"}.Updated()
+	if want := time.Date(2019, 3, 1, 10, 20, 30, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("StateTime.Updated() = %v, %v, want %v", got, ok, want)
+	}
+
+	if _, ok := (StateTime{LastUpdated: "none"}).Updated(); ok {
+		t.Errorf("StateTime.Updated() of none is ok")
+	}
+}
+
+func TestLightLevel(t *testing.T) {
+	tests := []struct {
+		level int
+		lux   float64
+	}{
+		{level: 0, lux: 0},
+		{level: 1, lux: 1},
+		{level: 10001, lux: 10},
+		{level: 30001, lux: 1000},
+	}
+	for _, tt := range tests {
+		s := LightLevelState{LightLevel: tt.level}
+		if got := s.Lux(); math.Abs(got-tt.lux) > 1e-9 {
+			t.Errorf("LightLevelState.Lux() of %d = %v, want %v", tt.level, got, tt.lux)
+		}
+
+		if got := LuxToLightLevel(tt.lux); got != tt.level {
+			t.Errorf("LuxToLightLevel(%v) = %v, want %v", tt.lux, got, tt.level)
+		}
+	}
+}
+
+func TestTemperatureState_Celsius(t *testing.T) {
+	s := TemperatureState{Temperature: 2153}
+	if got := s.Celsius(); got != 21.53 {
+		t.Errorf("TemperatureState.Celsius() = %v, want 21.53", got)
+	}
+}
+
+func TestSensorConfigUpdate_Validate(t *testing.T) {
+	two := 2
+	three := 3
+	yes := true
+	battery := 120
+
+	presence := &Sensor{
+		Type:   ZLLPresence,
+		Config: SensorConfig{Sensitivity: &two, SensitivityMax: &two, LEDIndication: &yes},
+	}
+
+	tests := []struct {
+		name       string
+		update     SensorConfigUpdate
+		sensor     *Sensor
+		wantFields []string
+	}{
+		{
+			name:   "sensitivity",
+			update: SensorConfigUpdate{Sensitivity: &two, LEDIndication: &yes},
+			sensor: presence,
+		},
+		{
+			name:       "sensitivity above max",
+			update:     SensorConfigUpdate{Sensitivity: &three},
+			sensor:     presence,
+			wantFields: []string{"sensitivity"},
+		},
+		{
+			name:   "sensitivity without the sensor",
+			update: SensorConfigUpdate{Sensitivity: &three},
+		},
+		{
+			name:       "threshold on a presence sensor",
+			update:     SensorConfigUpdate{TholdDark: &two},
+			sensor:     presence,
+			wantFields: []string{"tholddark"},
+		},
+		{
+			name:       "battery of a physical sensor",
+			update:     SensorConfigUpdate{Battery: &two},
+			sensor:     presence,
+			wantFields: []string{"battery"},
+		},
+		{
+			name:       "battery out of range",
+			update:     SensorConfigUpdate{Battery: &battery},
+			sensor:     &Sensor{Type: CLIPGenericFlag},
+			wantFields: []string{"battery"},
+		},
+		{
+			name:   "daylight location",
+			update: SensorConfigUpdate{Lat: "52.3740N", Long: "004.8897E", SunriseOffset: &three},
+			sensor: &Sensor{Type: Daylight},
+		},
+		{
+			name:       "invalid location",
+			update:     SensorConfigUpdate{Lat: "52.37N"},
+			wantFields: []string{"lat", "lat"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := tt.update.Validate(tt.sensor); err != nil {
+				for _, f := range err.(*ValidationError).Fields {
+					got = append(got, f.Field)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("SensorConfigUpdate.Validate() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestGroupSensors(t *testing.T) {
+	sensors := []Sensor{
+		{ID: 5, ModelID: "SML001", UniqueID: "00:17:88:01:02:00:B5:D1-02-0406"},
+		{ID: 8, ModelID: "ZGPSWITCH", UniqueID: "00:00:00:00:00:40:9e:2b-f2"},
+		{ID: 6, ModelID: "SML001", UniqueID: "00:17:88:01:02:00:b5:d1-02-0400"},
+		{ID: 9, ModelID: "away", UniqueID: "away"},
+		{ID: 10, ModelID: "away", UniqueID: "away"},
+	}
+
+	got := GroupSensors(sensors)
+
+	var macs []string
+	var ids [][]int
+	for _, d := range got {
+		macs = append(macs, d.MAC)
+
+		var group []int
+		for _, s := range d.Sensors {
+			group = append(group, s.ID)
+		}
+		ids = append(ids, group)
+	}
+
+	if want := []string{"00:17:88:01:02:00:b5:d1", "00:00:00:00:00:40:9e:2b", "", ""}; !reflect.DeepEqual(macs, want) {
+		t.Errorf("GroupSensors() macs = %v, want %v", macs, want)
+	}
+
+	if want := [][]int{{5, 6}, {8}, {9}, {10}}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("GroupSensors() sensors = %v, want %v", ids, want)
+	}
+}
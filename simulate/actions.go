@@ -0,0 +1,156 @@
+package simulate
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ninnemana/huego"
+)
+
+// apply runs an action of a rule or the command of a schedule, returning
+// the attributes it changed.
+func (s *simulator) apply(method, address string, body map[string]interface{}) []string {
+	if method != http.MethodPut {
+		s.notef("%s %s isn't simulated", method, address)
+		return nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(address, "/"), "/")
+	id := -1
+	if len(parts) >= 2 {
+		if n, err := strconv.Atoi(parts[1]); err == nil {
+			id = n
+		}
+	}
+
+	switch {
+	case id < 0:
+	case len(parts) == 3 && parts[0] == "lights" && parts[2] == "state":
+		if _, ok := s.attrs[address+"/on"]; ok {
+			return s.write(address, body)
+		}
+	case len(parts) == 3 && parts[0] == "groups" && parts[2] == "action":
+		if id == hue.AllLightsGroup || s.groupLights(id) != nil {
+			return s.groupAction(id, body)
+		}
+	case len(parts) == 3 && parts[0] == "sensors" && (parts[2] == "state" || parts[2] == "config"):
+		if s.sensorExists(id) {
+			return s.write(address, body)
+		}
+	case len(parts) == 2 && parts[0] == "schedules":
+		if i := s.scheduleIndex(id); i >= 0 {
+			s.setScheduleStatus(i, body)
+			return nil
+		}
+	case len(parts) == 2 && parts[0] == "rules":
+		if i := s.ruleIndex(id); i >= 0 {
+			if status, ok := body["status"].(string); ok {
+				s.ruleStatus[i] = status
+			}
+			return nil
+		}
+	}
+
+	s.notef("PUT %s isn't simulated", address)
+	return nil
+}
+
+// groupAction applies the body to every light of the group, recalling
+// the scene first when it holds one.
+func (s *simulator) groupAction(id int, body map[string]interface{}) []string {
+	var changed []string
+
+	settings := make(map[string]interface{}, len(body))
+	for key, v := range body {
+		if key == "scene" {
+			changed = append(changed, s.recall(fmt.Sprint(v))...)
+			continue
+		}
+
+		settings[key] = v
+		if key != "transitiontime" {
+			s.set(fmt.Sprintf("/groups/%d/action/%s", id, key), v)
+		}
+	}
+
+	if len(settings) > 0 {
+		for _, l := range s.groupLights(id) {
+			changed = append(changed, s.write(fmt.Sprintf("/lights/%s/state", l), settings)...)
+		}
+	}
+
+	return changed
+}
+
+// recall applies the light states of a scene.
+func (s *simulator) recall(scene string) []string {
+	for _, sc := range s.state.Scenes {
+		if sc.ID != scene {
+			continue
+		}
+
+		if len(sc.LightStates) == 0 {
+			s.notef("scene %s '%s' has no light states in the state, recalling it isn't simulated", sc.ID, sc.Name)
+			return nil
+		}
+
+		var changed []string
+		for _, l := range sc.LightStates.Lights() {
+			changed = append(changed, s.write(fmt.Sprintf("/lights/%s/state", l), object(sc.LightStates[l]))...)
+		}
+		return changed
+	}
+
+	s.notef("scene %s doesn't exist", scene)
+	return nil
+}
+
+// setScheduleStatus enables or disables a schedule, enabling a schedule
+// restarts it.
+func (s *simulator) setScheduleStatus(i int, body map[string]interface{}) {
+	status, ok := body["status"].(string)
+	if !ok || len(body) != 1 {
+		s.notef("changing schedule %d other than its status isn't simulated", s.schedules[i].ID)
+	}
+	if !ok {
+		return
+	}
+
+	s.scheduleStatus[i] = status
+	s.scheduleGen[i]++
+	if status == hue.ScheduleEnabled {
+		s.plan(i, s.now, false)
+	}
+}
+
+func (s *simulator) sensorExists(id int) bool {
+	for _, sensor := range s.state.Sensors {
+		if sensor.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *simulator) scheduleIndex(id int) int {
+	for i, sc := range s.schedules {
+		if sc.ID == id && id > 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *simulator) ruleIndex(id int) int {
+	for i, r := range s.rules {
+		if r.ID == id && id > 0 {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,426 @@
+// Package simulate runs rules and schedules against a snapshot of the
+// state of a bridge, without the bridge, to tell what a sequence of
+// sensor changes would trigger before the rules are deployed.
+//
+// Rules are evaluated the way the bridge evaluates them: when an
+// attribute one of their conditions refers to changes, and when the
+// duration of a ddx or stable condition elapses. A rule triggers when all
+// of its conditions hold at that moment. The actions of a rule that write
+// the state of CLIP sensors change attributes in turn, the rules depending
+// on them are evaluated once every rule the previous change led to has
+// been.
+//
+// Only PUT actions are simulated: light states, group actions including
+// the recall of scenes whose light states are in the snapshot, sensor
+// states and configs, and the status of rules and schedules. Anything
+// else is listed in the notes of the report.
+package simulate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ninnemana/huego"
+	"github.com/pkg/errors"
+)
+
+// MaxDepth is the longest chain of rules triggering each other the
+// simulation follows before reporting them as a loop.
+const MaxDepth = 16
+
+// Event sets an attribute at a point in time.
+type Event struct {
+	At time.Time
+
+	// Address is the attribute set, such as
+	// /sensors/5/state/presence. An event without an address only
+	// advances the clock, running the timers and schedules due until
+	// then.
+	Address string
+
+	Value interface{}
+}
+
+// Firing is a rule or schedule that triggered.
+type Firing struct {
+	At time.Time
+
+	// Rule and Schedule are the index of the rule or schedule that
+	// triggered in the ones simulated, the other one is -1.
+	Rule     int
+	Schedule int
+
+	Name string
+
+	// Cause is the attribute whose change led to the rule being
+	// evaluated, or the condition or time pattern whose time came.
+	Cause string
+
+	// Depth is 0 for rules triggered by an event or by time, and one
+	// more than the rule or schedule whose actions triggered it
+	// otherwise.
+	Depth int
+}
+
+func (f Firing) String() string {
+	kind, index := "rule", f.Rule
+	if f.Rule < 0 {
+		kind, index = "schedule", f.Schedule
+	}
+
+	return fmt.Sprintf("%s %s %d '%s' on %s", f.At.Format(time.RFC3339), kind, index, f.Name, f.Cause)
+}
+
+// Report is the outcome of a simulation.
+type Report struct {
+	// Firings are the rules and schedules that triggered, in order.
+	Firings []Firing
+
+	// Lights are the states of the lights once every event has been
+	// simulated, keyed by light ID.
+	Lights map[int]hue.LightState
+
+	// Notes lists the actions that weren't simulated.
+	Notes []string
+}
+
+// Run simulates the rules and schedules on state, feeding it the events
+// in order. The simulation starts at the local time of the state, or the
+// first event when the state has no config, and ends with the last event.
+// The IDs of rules and schedules are only used to resolve the actions
+// addressing them, rules that haven't been created yet can be simulated.
+func Run(state *hue.FullState, rules []hue.Rule, schedules []hue.Schedule, events []Event) (*Report, error) {
+	start, err := state.LocalTime()
+	if err != nil {
+		if len(events) == 0 {
+			return nil, errors.Wrap(err, "the simulation has no start time")
+		}
+		start = events[0].At
+	}
+
+	s := newSimulator(state, rules, schedules, start)
+
+	end := start
+	for i, e := range events {
+		if e.At.Before(end) {
+			return nil, errors.Errorf("event %d at %s is before the previous event or the state", i, e.At.Format(time.RFC3339))
+		}
+
+		end = e.At
+		s.push(&item{at: e.At.In(s.loc), event: &events[i]})
+	}
+
+	for i := range schedules {
+		s.plan(i, start, true)
+	}
+
+	for {
+		it := s.pop()
+		if it == nil || it.at.After(end) {
+			break
+		}
+
+		s.now = it.at
+		if err := s.run(it); err != nil {
+			return s.report, errors.Wrapf(err, "at %s", it.at.Format(time.RFC3339))
+		}
+	}
+
+	s.report.Lights = s.lights()
+	return s.report, nil
+}
+
+// item is something due at a point in time: an event, the elapsed
+// duration of a condition or a schedule.
+type item struct {
+	at  time.Time
+	seq int
+
+	event *Event
+
+	// rule and condition are the indexes of the condition whose duration
+	// elapsed, the rule is -1 otherwise.
+	rule, condition int
+
+	// schedule is the index of the schedule that is due, -1 otherwise.
+	// It is stale unless gen is the schedule's generation, runs is the
+	// number of times a timer runs again.
+	schedule  int
+	gen, runs int
+}
+
+// simulator holds the state of a simulation.
+type simulator struct {
+	state     *hue.FullState
+	rules     []hue.Rule
+	schedules []hue.Schedule
+	loc       *time.Location
+	now       time.Time
+
+	// attrs are the attributes of lights, groups and sensors keyed by
+	// their address.
+	attrs map[string]*attr
+
+	ruleStatus     []string
+	scheduleStatus []string
+	scheduleGen    []int
+
+	pending []*item
+	seq     int
+
+	report *Report
+}
+
+func newSimulator(state *hue.FullState, rules []hue.Rule, schedules []hue.Schedule, start time.Time) *simulator {
+	s := &simulator{
+		state:          state,
+		rules:          rules,
+		schedules:      schedules,
+		loc:            start.Location(),
+		now:            start,
+		attrs:          map[string]*attr{},
+		ruleStatus:     make([]string, len(rules)),
+		scheduleStatus: make([]string, len(schedules)),
+		scheduleGen:    make([]int, len(schedules)),
+		report:         &Report{},
+	}
+
+	for i, r := range rules {
+		s.ruleStatus[i] = r.Status
+	}
+	for i, sc := range schedules {
+		s.scheduleStatus[i] = sc.Status
+	}
+
+	s.load()
+	return s
+}
+
+func (s *simulator) push(it *item) {
+	if it.event != nil {
+		it.rule, it.schedule = -1, -1
+	}
+
+	it.seq = s.seq
+	s.seq++
+	s.pending = append(s.pending, it)
+}
+
+// pop removes and returns the item due first, items due at the same time
+// are returned in the order they were pushed.
+func (s *simulator) pop() *item {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	sort.Slice(s.pending, func(i, j int) bool {
+		a, b := s.pending[i], s.pending[j]
+		if !a.at.Equal(b.at) {
+			return a.at.Before(b.at)
+		}
+		return a.seq < b.seq
+	})
+
+	it := s.pending[0]
+	s.pending = s.pending[1:]
+	return it
+}
+
+func (s *simulator) run(it *item) error {
+	switch {
+	case it.event != nil:
+		if it.event.Address == "" {
+			return nil
+		}
+
+		changed, err := s.event(it.event)
+		if err != nil {
+			return err
+		}
+		return s.cascade(changed, 0)
+	case it.rule >= 0:
+		return s.elapsed(it.rule, it.condition)
+	default:
+		return s.scheduled(it)
+	}
+}
+
+// cascade evaluates the rules depending on the changed attributes, then
+// the rules depending on the attributes their actions changed.
+func (s *simulator) cascade(changed []string, depth int) error {
+	for len(changed) > 0 {
+		if depth >= MaxDepth {
+			return errors.Errorf("rules triggered each other %d times in a row, they likely loop", MaxDepth)
+		}
+
+		set := make(map[string]bool, len(changed))
+		for _, address := range changed {
+			set[address] = true
+		}
+
+		s.startTimers(set)
+
+		var next []string
+		for i := range s.rules {
+			if s.ruleStatus[i] == hue.RuleDisabled {
+				continue
+			}
+
+			cause := ""
+			for _, c := range s.rules[i].Conditions {
+				if set[c.Address] {
+					cause = c.Address
+					break
+				}
+			}
+
+			if cause == "" || !s.holds(&s.rules[i], set) {
+				continue
+			}
+
+			next = append(next, s.fire(i, cause, depth)...)
+		}
+
+		changed = next
+		depth++
+	}
+
+	return nil
+}
+
+// startTimers queues the evaluation of the ddx and stable conditions on
+// the changed attributes for when their duration elapses.
+func (s *simulator) startTimers(set map[string]bool) {
+	for i, r := range s.rules {
+		for j, c := range r.Conditions {
+			if !set[c.Address] || (c.Operator != hue.OpDdx && c.Operator != hue.OpStable) {
+				continue
+			}
+
+			if d, err := conditionDuration(c.Value); err == nil {
+				s.push(&item{at: s.now.Add(d), rule: i, condition: j, schedule: -1})
+			}
+		}
+	}
+}
+
+// elapsed evaluates a rule once the duration of one of its conditions
+// elapsed, unless the attribute changed again since.
+func (s *simulator) elapsed(rule, condition int) error {
+	r := &s.rules[rule]
+	c := r.Conditions[condition]
+
+	a, ok := s.attrs[c.Address]
+	d, err := conditionDuration(c.Value)
+	if !ok || err != nil || !a.changed.Add(d).Equal(s.now) {
+		return nil
+	}
+
+	if s.ruleStatus[rule] == hue.RuleDisabled || !s.holds(r, nil) {
+		return nil
+	}
+
+	cause := fmt.Sprintf("%s %s %s", c.Address, c.Operator, c.Value)
+	return s.cascade(s.fire(rule, cause, 0), 1)
+}
+
+// fire records the rule triggering and runs its actions, returning the
+// attributes they changed.
+func (s *simulator) fire(rule int, cause string, depth int) []string {
+	r := &s.rules[rule]
+	s.report.Firings = append(s.report.Firings, Firing{
+		At:       s.now,
+		Rule:     rule,
+		Schedule: -1,
+		Name:     r.Name,
+		Cause:    cause,
+		Depth:    depth,
+	})
+
+	var changed []string
+	for _, a := range r.Actions {
+		changed = append(changed, s.apply(a.Method, a.Address, a.Body)...)
+	}
+
+	return changed
+}
+
+// plan queues the next run of a schedule after t, from the start time of
+// timers when initial is set.
+func (s *simulator) plan(i int, t time.Time, initial bool) {
+	sc := &s.schedules[i]
+	if s.scheduleStatus[i] == hue.ScheduleDisabled {
+		return
+	}
+
+	p, err := sc.Pattern(s.loc)
+	if err != nil {
+		s.notef("schedule %d '%s' isn't simulated: %v", i, sc.Name, err)
+		return
+	}
+
+	if p.Random > 0 && initial {
+		s.notef("schedule %d '%s' is simulated without its random delay", i, sc.Name)
+	}
+
+	it := &item{rule: -1, schedule: i, gen: s.scheduleGen[i], runs: p.Repeat}
+	switch {
+	case p.Duration > 0:
+		if started, ok := startTime(sc); ok && initial {
+			t = started
+		}
+		it.at = t.Add(p.Duration)
+	default:
+		next, ok := p.Next(t)
+		if !ok {
+			return
+		}
+		it.at = next
+	}
+
+	if it.at.Before(s.now) {
+		return
+	}
+
+	s.push(it)
+}
+
+// scheduled runs a schedule that is due and plans its next run.
+func (s *simulator) scheduled(it *item) error {
+	i := it.schedule
+	if it.gen != s.scheduleGen[i] || s.scheduleStatus[i] == hue.ScheduleDisabled {
+		return nil
+	}
+
+	sc := &s.schedules[i]
+	s.report.Firings = append(s.report.Firings, Firing{
+		At:       s.now,
+		Rule:     -1,
+		Schedule: i,
+		Name:     sc.Name,
+		Cause:    sc.LocalTime,
+	})
+
+	p, _ := sc.Pattern(s.loc)
+	switch {
+	case p.Duration > 0 && (it.runs > 0 || it.runs == -1):
+		runs := it.runs
+		if runs > 0 {
+			runs--
+		}
+		s.push(&item{at: s.now.Add(p.Duration), rule: -1, schedule: i, gen: it.gen, runs: runs})
+	case p.Duration > 0 || p.Time.Equal(s.now):
+		// timers and absolute times only run once, the bridge then
+		// disables them
+		s.scheduleStatus[i] = hue.ScheduleDisabled
+	default:
+		s.plan(i, s.now, false)
+	}
+
+	return s.cascade(s.apply(sc.Command.Method, commandAddress(sc.Command.Address), sc.Command.Body), 1)
+}
+
+func (s *simulator) notef(format string, args ...interface{}) {
+	s.report.Notes = append(s.report.Notes, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,292 @@
+package simulate
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ninnemana/huego"
+)
+
+const snapshot = `{
+	"lights": {
+		"1": {"name": "Hall ceiling", "type": "Dimmable light", "state": {"on": false, "bri": 254, "reachable": true}},
+		"2": {"name": "Hall lamp", "type": "Dimmable light", "state": {"on": false, "bri": 254, "reachable": true}}
+	},
+	"groups": {
+		"1": {"name": "Hall", "type": "Room", "lights": ["1", "2"], "action": {"on": false}, "state": {"any_on": false, "all_on": false}}
+	},
+	"sensors": {
+		"1": {"name": "Daylight", "type": "Daylight", "state": {"daylight": false, "lastupdated": "2019-01-07T16:00:00"}, "config": {"on": true}},
+		"5": {"name": "Hall motion", "type": "ZLLPresence", "state": {"presence": false, "lastupdated": "2019-01-07T21:00:00"}, "config": {"on": true}},
+		"9": {"name": "Hall occupied", "type": "CLIPGenericFlag", "state": {"flag": false, "lastupdated": "none"}, "config": {"on": true}}
+	},
+	"scenes": {
+		"AB34EF5": {"name": "Night", "type": "GroupScene", "group": "1", "lights": ["1", "2"], "lightstates": {"1": {"on": true, "bri": 50}, "2": {"on": true, "bri": 50}}},
+		"CD56AB7": {"name": "Bright", "type": "GroupScene", "group": "1", "lights": ["1", "2"]}
+	},
+	"schedules": {},
+	"rules": {},
+	"config": {"name": "Philips hue", "localtime": "2019-01-07T22:00:00", "timezone": "none"}
+}`
+
+func loadSnapshot(t *testing.T) *hue.FullState {
+	var state hue.FullState
+	if err := json.Unmarshal([]byte(snapshot), &state); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	return &state
+}
+
+// hallRules turn the hall on through a CLIP flag when motion is detected
+// at night, and off once there was no motion for 5 minutes.
+var hallRules = []hue.Rule{
+	{
+		ID:   1,
+		Name: "Hall motion",
+		Conditions: []hue.Condition{
+			{Address: "/sensors/5/state/presence", Operator: hue.OpEq, Value: "true"},
+			{Address: "/sensors/5/state/presence", Operator: hue.OpDx},
+			{Address: "/sensors/1/state/daylight", Operator: hue.OpEq, Value: "false"},
+		},
+		Actions: []hue.RuleAction{
+			{Address: "/sensors/9/state", Method: http.MethodPut, Body: map[string]interface{}{"flag": true}},
+		},
+	},
+	{
+		ID:   2,
+		Name: "Hall occupied",
+		Conditions: []hue.Condition{
+			{Address: "/sensors/9/state/flag", Operator: hue.OpEq, Value: "true"},
+			{Address: "/sensors/9/state/flag", Operator: hue.OpDx},
+		},
+		Actions: []hue.RuleAction{
+			{Address: "/groups/1/action", Method: http.MethodPut, Body: map[string]interface{}{"scene": "AB34EF5"}},
+		},
+	},
+	{
+		ID:   3,
+		Name: "Hall vacant",
+		Conditions: []hue.Condition{
+			{Address: "/sensors/5/state/presence", Operator: hue.OpEq, Value: "false"},
+			{Address: "/sensors/5/state/presence", Operator: hue.OpStable, Value: "PT00:05:00"},
+		},
+		Actions: []hue.RuleAction{
+			{Address: "/groups/1/action", Method: http.MethodPut, Body: map[string]interface{}{"on": false}},
+			{Address: "/sensors/9/state", Method: http.MethodPut, Body: map[string]interface{}{"flag": false}},
+		},
+	},
+}
+
+func at(clock string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", "2019-01-07T"+clock, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func presence(clock string, v bool) Event {
+	return Event{At: at(clock), Address: "/sensors/5/state/presence", Value: v}
+}
+
+func firings(r *Report) []string {
+	var got []string
+	for _, f := range r.Firings {
+		name := f.Name
+		if f.Depth > 0 {
+			name = strings.Repeat(">", f.Depth) + name
+		}
+		got = append(got, f.At.Format("15:04:05")+" "+name)
+	}
+
+	return got
+}
+
+func TestRun(t *testing.T) {
+	on, off := true, false
+	bri50, bri254 := uint8(50), uint8(254)
+
+	tests := []struct {
+		name       string
+		rules      []hue.Rule
+		schedules  []hue.Schedule
+		events     []Event
+		want       []string
+		wantLights map[int]bool
+		wantBri    map[int]uint8
+		wantNotes  []string
+	}{
+		{
+			name:  "chained through CLIP sensor",
+			rules: hallRules,
+			events: []Event{
+				presence("22:10:00", true),
+				presence("22:11:00", false),
+				{At: at("22:30:00")},
+			},
+			want:       []string{"22:10:00 Hall motion", "22:10:00 >Hall occupied", "22:16:00 Hall vacant"},
+			wantLights: map[int]bool{1: off, 2: off},
+			wantBri:    map[int]uint8{1: bri50, 2: bri50},
+		},
+		{
+			name:  "stable restarted by a change",
+			rules: hallRules,
+			events: []Event{
+				presence("22:10:00", true),
+				presence("22:11:00", false),
+				presence("22:14:00", true),
+				presence("22:15:00", false),
+				{At: at("22:19:59")},
+			},
+			want:       []string{"22:10:00 Hall motion", "22:10:00 >Hall occupied", "22:14:00 Hall motion"},
+			wantLights: map[int]bool{1: on, 2: on},
+			wantBri:    map[int]uint8{1: bri50, 2: bri50},
+		},
+		{
+			name: "ddx and time interval",
+			rules: []hue.Rule{
+				{
+					Name: "Two minutes after",
+					Conditions: []hue.Condition{
+						{Address: "/sensors/5/state/presence", Operator: hue.OpDdx, Value: "PT00:02:00"},
+						{Address: hue.LocalTimeAddress, Operator: hue.OpIn, Value: "W127/T22:00:00/T22:15:00"},
+					},
+					Actions: []hue.RuleAction{
+						{Address: "/lights/1/state", Method: http.MethodPut, Body: map[string]interface{}{"on": true, "bri_inc": -100}},
+					},
+				},
+				{
+					Name:   "Disabled",
+					Status: hue.RuleDisabled,
+					Conditions: []hue.Condition{
+						{Address: "/sensors/5/state/presence", Operator: hue.OpDx},
+					},
+					Actions: []hue.RuleAction{
+						{Address: "/lights/2/state", Method: http.MethodPut, Body: map[string]interface{}{"on": true}},
+					},
+				},
+			},
+			events: []Event{
+				presence("22:10:00", true),
+				presence("22:14:00", false),
+				{At: at("22:30:00")},
+			},
+			want:       []string{"22:12:00 Two minutes after"},
+			wantLights: map[int]bool{1: on, 2: off},
+			wantBri:    map[int]uint8{1: 154, 2: bri254},
+		},
+		{
+			name: "schedules",
+			rules: []hue.Rule{
+				{
+					Name: "Lamp follows ceiling",
+					Conditions: []hue.Condition{
+						{Address: "/lights/1/state/on", Operator: hue.OpEq, Value: "true"},
+						{Address: "/groups/1/state/all_on", Operator: hue.OpEq, Value: "false"},
+					},
+					Actions: []hue.RuleAction{
+						{Address: "/lights/2/state", Method: http.MethodPut, Body: map[string]interface{}{"on": true}},
+						{Address: "/scenes/CD56AB7", Method: http.MethodDelete},
+					},
+				},
+			},
+			schedules: []hue.Schedule{
+				{
+					Name:      "Evening",
+					LocalTime: "W127/T22:30:00",
+					Command:   hue.Command{Address: "/api/user/lights/1/state", Method: http.MethodPut, Body: map[string]interface{}{"on": true}},
+				},
+				{
+					Name:      "Bright",
+					LocalTime: "R02/PT00:20:00",
+					Command:   hue.Command{Address: "/api/user/groups/1/action", Method: http.MethodPut, Body: map[string]interface{}{"scene": "CD56AB7"}},
+				},
+			},
+			events:     []Event{{At: at("23:30:00")}},
+			want:       []string{"22:20:00 Bright", "22:30:00 Evening", "22:30:00 >Lamp follows ceiling", "22:40:00 Bright", "23:00:00 Bright"},
+			wantLights: map[int]bool{1: on, 2: on},
+			wantBri:    map[int]uint8{1: bri254, 2: bri254},
+			wantNotes: []string{
+				"scene CD56AB7 'Bright' has no light states in the state, recalling it isn't simulated",
+				"DELETE /scenes/CD56AB7 isn't simulated",
+				"scene CD56AB7 'Bright' has no light states in the state, recalling it isn't simulated",
+				"scene CD56AB7 'Bright' has no light states in the state, recalling it isn't simulated",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Run(loadSnapshot(t), tt.rules, tt.schedules, tt.events)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if f := firings(got); !reflect.DeepEqual(f, tt.want) {
+				t.Errorf("Run() firings = %q, want %q", f, tt.want)
+			}
+
+			for id, on := range tt.wantLights {
+				if got.Lights[id].On != on || got.Lights[id].Bri != tt.wantBri[id] {
+					t.Errorf("Run() light %d on = %v bri = %d, want %v %d", id, got.Lights[id].On, got.Lights[id].Bri, on, tt.wantBri[id])
+				}
+			}
+
+			if !reflect.DeepEqual(got.Notes, tt.wantNotes) {
+				t.Errorf("Run() notes = %q, want %q", got.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestRun_loop(t *testing.T) {
+	rules := []hue.Rule{
+		{
+			Name:       "Set",
+			Conditions: []hue.Condition{{Address: "/sensors/9/state/flag", Operator: hue.OpEq, Value: "true"}},
+			Actions:    []hue.RuleAction{{Address: "/sensors/9/state", Method: http.MethodPut, Body: map[string]interface{}{"flag": false}}},
+		},
+		{
+			Name:       "Reset",
+			Conditions: []hue.Condition{{Address: "/sensors/9/state/flag", Operator: hue.OpEq, Value: "false"}},
+			Actions:    []hue.RuleAction{{Address: "/sensors/9/state", Method: http.MethodPut, Body: map[string]interface{}{"flag": true}}},
+		},
+	}
+
+	_, err := Run(loadSnapshot(t), rules, nil, []Event{{At: at("22:01:00"), Address: "/sensors/9/state/flag", Value: true}})
+	if err == nil || !strings.Contains(err.Error(), "likely loop") {
+		t.Errorf("Run() error = %v, want a loop", err)
+	}
+}
+
+func TestRun_invalidEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+	}{
+		{
+			name:   "unknown attribute",
+			events: []Event{{At: at("22:01:00"), Address: "/sensors/5/state/buttonevent", Value: 1002}},
+		},
+		{
+			name:   "out of order",
+			events: []Event{presence("22:05:00", true), presence("22:01:00", false)},
+		},
+		{
+			name:   "before the state",
+			events: []Event{presence("21:00:00", true)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Run(loadSnapshot(t), hallRules, nil, tt.events); err == nil {
+				t.Error("Run() error = nil, want an error")
+			}
+		})
+	}
+}
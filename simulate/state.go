@@ -0,0 +1,403 @@
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ninnemana/huego"
+	"github.com/ninnemana/huego/timepattern"
+	"github.com/pkg/errors"
+)
+
+// attr is the value of an attribute and when it last changed.
+type attr struct {
+	value   interface{}
+	changed time.Time
+}
+
+// load reads the attributes of the lights, groups and sensors of the
+// state. Sensor states changed when they were last updated, anything
+// else when the simulation starts.
+func (s *simulator) load() {
+	for _, l := range s.state.Lights {
+		s.setAll(fmt.Sprintf("/lights/%d/state", l.ID), l.State, s.now)
+	}
+
+	for _, g := range s.state.Groups {
+		s.setAll(fmt.Sprintf("/groups/%d/action", g.ID), g.Action, s.now)
+	}
+	s.updateGroups()
+
+	for _, sensor := range s.state.Sensors {
+		s.setAll(fmt.Sprintf("/sensors/%d/config", sensor.ID), sensor.Config, s.now)
+		if sensor.State == nil {
+			continue
+		}
+
+		changed := s.now
+		if updated, ok := sensor.State.Updated(); ok && updated.Before(s.now) {
+			changed = updated
+		}
+		s.setAll(fmt.Sprintf("/sensors/%d/state", sensor.ID), sensor.State, changed)
+	}
+}
+
+// setAll sets the attributes of v under the address prefix.
+func (s *simulator) setAll(prefix string, v interface{}, changed time.Time) {
+	for key, value := range object(v) {
+		s.attrs[prefix+"/"+key] = &attr{value: value, changed: changed}
+	}
+}
+
+// set sets an attribute, reporting whether its value changed.
+func (s *simulator) set(address string, v interface{}) bool {
+	v = normalize(v)
+	if a, ok := s.attrs[address]; ok && reflect.DeepEqual(a.value, v) {
+		return false
+	}
+
+	s.attrs[address] = &attr{value: v, changed: s.now}
+	return true
+}
+
+// event applies an event, returning the attributes it changed.
+func (s *simulator) event(e *Event) ([]string, error) {
+	if _, ok := s.attrs[e.Address]; !ok {
+		return nil, errors.Errorf("unknown attribute '%s'", e.Address)
+	}
+
+	i := strings.LastIndex(e.Address, "/")
+	return s.write(e.Address[:i], map[string]interface{}{e.Address[i+1:]: e.Value}), nil
+}
+
+// write sets the attributes of body under the address prefix, returning
+// the ones that changed. Writing a sensor state always updates it, and
+// writing a light state updates the state of its groups.
+func (s *simulator) write(prefix string, body map[string]interface{}) []string {
+	keys := make([]string, 0, len(body))
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changed []string
+	for _, key := range keys {
+		v := body[key]
+		switch {
+		case key == "transitiontime":
+			continue
+		case strings.HasSuffix(key, "_inc"):
+			base := prefix + "/" + strings.TrimSuffix(key, "_inc")
+			a, ok := s.attrs[base]
+			current, isNum := number(valueOf(a, ok))
+			inc, incNum := number(normalize(v))
+			if !isNum || !incNum {
+				s.notef("%s of %s isn't simulated", key, prefix)
+				continue
+			}
+
+			if s.set(base, increment(key, current, inc)) {
+				changed = append(changed, base)
+			}
+		default:
+			if s.set(prefix+"/"+key, v) {
+				changed = append(changed, prefix+"/"+key)
+			}
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(prefix, "/sensors/") && strings.HasSuffix(prefix, "/state"):
+		address := prefix + "/lastupdated"
+		s.attrs[address] = &attr{value: timepattern.FormatLocal(s.now, time.UTC), changed: s.now}
+		changed = append(changed, address)
+	case strings.HasPrefix(prefix, "/lights/"):
+		changed = append(changed, s.updateGroups()...)
+	}
+
+	return changed
+}
+
+// updateGroups sets whether any and all lights of every group are on,
+// returning the attributes that changed.
+func (s *simulator) updateGroups() []string {
+	var changed []string
+	for _, id := range append([]int{hue.AllLightsGroup}, s.groupIDs()...) {
+		lights := s.groupLights(id)
+
+		anyOn, allOn := false, len(lights) > 0
+		for _, l := range lights {
+			a, ok := s.attrs[fmt.Sprintf("/lights/%s/state/on", l)]
+			on := ok && a.value == true
+			anyOn = anyOn || on
+			allOn = allOn && on
+		}
+
+		for key, v := range map[string]bool{"any_on": anyOn, "all_on": allOn} {
+			address := fmt.Sprintf("/groups/%d/state/%s", id, key)
+			if s.set(address, v) {
+				changed = append(changed, address)
+			}
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+func (s *simulator) groupIDs() []int {
+	ids := make([]int, 0, len(s.state.Groups))
+	for _, g := range s.state.Groups {
+		ids = append(ids, g.ID)
+	}
+
+	return ids
+}
+
+// groupLights returns the IDs of the lights of a group, every light for
+// the group of all lights and none when the group doesn't exist.
+func (s *simulator) groupLights(id int) []string {
+	if id == hue.AllLightsGroup {
+		lights := make([]string, 0, len(s.state.Lights))
+		for _, l := range s.state.Lights {
+			lights = append(lights, strconv.Itoa(l.ID))
+		}
+		return lights
+	}
+
+	for _, g := range s.state.Groups {
+		if g.ID == id {
+			return g.Lights
+		}
+	}
+
+	return nil
+}
+
+// holds reports whether every condition of the rule holds now, set holds
+// the attributes that just changed.
+func (s *simulator) holds(r *hue.Rule, set map[string]bool) bool {
+	for _, c := range r.Conditions {
+		if !s.condition(c, set) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *simulator) condition(c hue.Condition, set map[string]bool) bool {
+	if c.Address == hue.LocalTimeAddress {
+		in, err := inInterval(s.now, c.Value)
+		switch {
+		case err != nil:
+			return false
+		case c.Operator == hue.OpIn:
+			return in
+		case c.Operator == hue.OpNotIn:
+			return !in
+		default:
+			return false
+		}
+	}
+
+	a, ok := s.attrs[c.Address]
+	if !ok {
+		return false
+	}
+
+	switch c.Operator {
+	case hue.OpEq:
+		return format(a.value) == c.Value
+	case hue.OpGt, hue.OpLt:
+		n, ok := number(a.value)
+		v, err := strconv.ParseFloat(c.Value, 64)
+		if !ok || err != nil {
+			return false
+		}
+
+		if c.Operator == hue.OpGt {
+			return n > v
+		}
+		return n < v
+	case hue.OpDx:
+		return set[c.Address]
+	case hue.OpDdx, hue.OpStable, hue.OpNotStable:
+		d, err := conditionDuration(c.Value)
+		if err != nil {
+			return false
+		}
+
+		switch c.Operator {
+		case hue.OpDdx:
+			return s.now.Equal(a.changed.Add(d))
+		case hue.OpStable:
+			return !s.now.Before(a.changed.Add(d))
+		default:
+			return s.now.Before(a.changed.Add(d))
+		}
+	default:
+		return false
+	}
+}
+
+// lights returns the state of every light.
+func (s *simulator) lights() map[int]hue.LightState {
+	lights := make(map[int]hue.LightState, len(s.state.Lights))
+	for _, l := range s.state.Lights {
+		prefix := fmt.Sprintf("/lights/%d/state/", l.ID)
+
+		attrs := object(l.State)
+		for address, a := range s.attrs {
+			if strings.HasPrefix(address, prefix) {
+				attrs[address[len(prefix):]] = a.value
+			}
+		}
+
+		var state hue.LightState
+		data, _ := json.Marshal(attrs)
+		if err := json.Unmarshal(data, &state); err != nil {
+			s.notef("light %d ended in a state that can't be decoded: %v", l.ID, err)
+			state = l.State
+		}
+
+		lights[l.ID] = state
+	}
+
+	return lights
+}
+
+// object returns the JSON object v encodes to.
+func object(v interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	data, err := json.Marshal(v)
+	if err == nil {
+		json.Unmarshal(data, &m)
+	}
+
+	return m
+}
+
+// normalize returns v as it reads once encoded to JSON, so values set by
+// events and actions compare equal to the ones read from the state.
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var n interface{}
+	if err := json.Unmarshal(data, &n); err != nil {
+		return v
+	}
+
+	return n
+}
+
+// increment adds inc to the current value of an attribute, within the
+// range of light state attributes.
+func increment(key string, current, inc float64) float64 {
+	v := current + inc
+	switch key {
+	case "hue_inc":
+		return math.Mod(math.Mod(v, 65536)+65536, 65536)
+	case "bri_inc", "sat_inc":
+		return math.Max(0, math.Min(254, v))
+	case "ct_inc":
+		return math.Max(153, math.Min(500, v))
+	}
+
+	return v
+}
+
+func valueOf(a *attr, ok bool) interface{} {
+	if !ok {
+		return nil
+	}
+
+	return a.value
+}
+
+func number(v interface{}) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+// format writes a value the way the values of conditions are written.
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// conditionDuration parses the PThh:mm:ss duration of a condition.
+func conditionDuration(v string) (time.Duration, error) {
+	p, err := timepattern.Parse(v, time.UTC)
+	if err != nil || p.Kind != timepattern.Timer || p.Repeat != 0 {
+		return 0, errors.Errorf("invalid condition duration '%s'", v)
+	}
+
+	return p.Duration, nil
+}
+
+var interval = regexp.MustCompile(`^(?:W(\d{1,3})/)?T(\d{2}:\d{2}:\d{2})/T(\d{2}:\d{2}:\d{2})$`)
+
+// inInterval reports whether t is in a time interval of a condition,
+// such as W124/T18:00:00/T23:00:00. Intervals that end before they start
+// run past midnight, the weekdays are the days they start on.
+func inInterval(t time.Time, v string) (bool, error) {
+	m := interval.FindStringSubmatch(v)
+	if m == nil {
+		return false, errors.Errorf("invalid time interval '%s'", v)
+	}
+
+	days := timepattern.Everyday
+	if m[1] != "" {
+		n, _ := strconv.Atoi(m[1])
+		days = timepattern.Weekdays(n)
+	}
+
+	from, to := clock(m[2]), clock(m[3])
+	y, mo, d := t.Date()
+	tod := t.Sub(time.Date(y, mo, d, 0, 0, 0, 0, t.Location()))
+
+	if from <= to {
+		return days.Has(t.Weekday()) && tod >= from && tod <= to, nil
+	}
+
+	return days.Has(t.Weekday()) && tod >= from || days.Has(t.AddDate(0, 0, -1).Weekday()) && tod <= to, nil
+}
+
+func clock(s string) time.Duration {
+	var h, m, sec int
+	fmt.Sscanf(s, "%d:%d:%d", &h, &m, &sec)
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second
+}
+
+// startTime returns when a timer schedule was started, the bridge
+// reports it in UTC.
+func startTime(sc *hue.Schedule) (time.Time, bool) {
+	t, err := timepattern.ParseLocal(sc.StartTime, time.UTC)
+	return t, err == nil
+}
+
+var apiPrefix = regexp.MustCompile(`^/api/[^/]+`)
+
+// commandAddress strips the /api/<username> prefix of the address of a
+// schedule command.
+func commandAddress(address string) string {
+	return apiPrefix.ReplaceAllString(address, "")
+}
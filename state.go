@@ -0,0 +1,323 @@
+package hue
+
+import (
+	"math"
+	"time"
+
+	"github.com/ninnemana/huego/color"
+)
+
+const (
+	// MinCT and MaxCT bound the color temperature, in mired, accepted
+	// by lights that don't report their own range.
+	MinCT = 153
+	MaxCT = 500
+)
+
+// StateUpdate is the body of a state write to a light or group.
+// PUT /api/<username>/lights/<id>/state
+// PUT /api/<username>/groups/<id>/action
+//
+// Only the attributes that are set are sent to the bridge, use a
+// StateBuilder to construct one that has been validated.
+type StateUpdate struct {
+	On             *bool     `json:"on,omitempty"`
+	Bri            *int      `json:"bri,omitempty"`
+	Hue            *int      `json:"hue,omitempty"`
+	Sat            *int      `json:"sat,omitempty"`
+	XY             []float64 `json:"xy,omitempty"`
+	CT             *int      `json:"ct,omitempty"`
+	Alert          string    `json:"alert,omitempty"`
+	Effect         string    `json:"effect,omitempty"`
+	TransitionTime *int      `json:"transitiontime,omitempty"`
+	BriInc         *int      `json:"bri_inc,omitempty"`
+	SatInc         *int      `json:"sat_inc,omitempty"`
+	HueInc         *int      `json:"hue_inc,omitempty"`
+	CTInc          *int      `json:"ct_inc,omitempty"`
+	XYInc          []float64 `json:"xy_inc,omitempty"`
+
+	// Scene recalls a scene on the group, it is only accepted by group
+	// actions.
+	Scene string `json:"scene,omitempty"`
+}
+
+// gamutTolerance is how far, in xy, a color may lie outside the gamut of
+// a light, so colors clamped to the gamut and rounded still validate.
+const gamutTolerance = 0.001
+
+// Validate checks every attribute of the update against the ranges
+// documented by the Hue API. When caps is provided and the light is
+// certified, color and color temperature attributes are also checked
+// against what the light reports it can render. Color temperatures are
+// checked against the range and xy colors against the gamut the light
+// reports, whether it is certified or not.
+func (s *StateUpdate) Validate(caps *LightCapabilities) error {
+	v := &ValidationError{}
+
+	if s == nil {
+		v.add("state", nil, "is required")
+		return v
+	}
+
+	v.check(s.Bri, "bri", 1, 254)
+	v.check(s.Hue, "hue", 0, 65535)
+	v.check(s.Sat, "sat", 0, 254)
+	v.check(s.TransitionTime, "transitiontime", 0, 65535)
+	v.check(s.BriInc, "bri_inc", -254, 254)
+	v.check(s.SatInc, "sat_inc", -254, 254)
+	v.check(s.HueInc, "hue_inc", -65534, 65534)
+	v.check(s.CTInc, "ct_inc", -65534, 65534)
+
+	minCT, maxCT := MinCT, MaxCT
+	if caps != nil && caps.Control.CT != nil {
+		minCT, maxCT = int(caps.Control.CT.Min), int(caps.Control.CT.Max)
+	}
+	v.check(s.CT, "ct", minCT, maxCT)
+
+	if s.XY != nil {
+		if len(s.XY) != 2 || s.XY[0] < 0 || s.XY[0] > 1 || s.XY[1] < 0 || s.XY[1] > 1 {
+			v.add("xy", s.XY, "must be two coordinates between 0 and 1")
+		}
+	}
+
+	if len(s.XY) == 2 && caps != nil {
+		if g, ok := caps.Gamut(); ok {
+			p := color.XY{X: s.XY[0], Y: s.XY[1]}
+			if c := g.Clamp(p); math.Hypot(c.X-p.X, c.Y-p.Y) > gamutTolerance {
+				v.add("xy", s.XY, "is outside the color gamut of the light")
+			}
+		}
+	}
+
+	if s.XYInc != nil {
+		if len(s.XYInc) != 2 || s.XYInc[0] < -0.5 || s.XYInc[0] > 0.5 || s.XYInc[1] < -0.5 || s.XYInc[1] > 0.5 {
+			v.add("xy_inc", s.XYInc, "must be two increments between -0.5 and 0.5")
+		}
+	}
+
+	switch s.Alert {
+	case "", "none", "select", "lselect":
+	default:
+		v.add("alert", s.Alert, "must be one of none, select or lselect")
+	}
+
+	switch s.Effect {
+	case "", "none", "colorloop":
+	default:
+		v.add("effect", s.Effect, "must be one of none or colorloop")
+	}
+
+	modes := []string{}
+	if s.XY != nil || s.XYInc != nil {
+		modes = append(modes, "xy")
+	}
+	if s.CT != nil || s.CTInc != nil {
+		modes = append(modes, "ct")
+	}
+	if s.Hue != nil || s.Sat != nil || s.HueInc != nil || s.SatInc != nil {
+		modes = append(modes, "hs")
+	}
+	if len(modes) > 1 {
+		v.add("colormode", modes, "only one of xy, ct or hue/sat can be set at once")
+	}
+
+	v.conflict(s.Bri != nil && s.BriInc != nil, "bri_inc", "can't be combined with bri")
+	v.conflict(s.Sat != nil && s.SatInc != nil, "sat_inc", "can't be combined with sat")
+	v.conflict(s.Hue != nil && s.HueInc != nil, "hue_inc", "can't be combined with hue")
+	v.conflict(s.CT != nil && s.CTInc != nil, "ct_inc", "can't be combined with ct")
+	v.conflict(s.XY != nil && s.XYInc != nil, "xy_inc", "can't be combined with xy")
+
+	if caps != nil && caps.Certified {
+		colored := caps.Control.ColorGamutType != "" || len(caps.Control.ColorGamut) > 0
+		if !colored {
+			for _, m := range modes {
+				if m == "xy" || m == "hs" {
+					v.add(m, nil, "light doesn't support color")
+				}
+			}
+
+			if s.Effect == "colorloop" {
+				v.add("effect", s.Effect, "light doesn't support color")
+			}
+		}
+
+		if caps.Control.CT == nil && (s.CT != nil || s.CTInc != nil) {
+			v.add("ct", nil, "light doesn't support color temperature")
+		}
+	}
+
+	return v.err()
+}
+
+// StateBuilder constructs a StateUpdate, validating it against the
+// capabilities of the light it was created for when it's built.
+type StateBuilder struct {
+	caps  *LightCapabilities
+	gamut *color.Gamut
+	state StateUpdate
+}
+
+// NewStateBuilder returns a StateBuilder for a light with the given
+// capabilities, caps may be nil to only validate the documented ranges.
+func NewStateBuilder(caps *LightCapabilities) *StateBuilder {
+	b := &StateBuilder{
+		caps: caps,
+	}
+
+	if caps != nil {
+		if g, ok := caps.Gamut(); ok {
+			b.gamut = &g
+		}
+	}
+
+	return b
+}
+
+// On turns the light on or off.
+func (b *StateBuilder) On(on bool) *StateBuilder {
+	b.state.On = &on
+	return b
+}
+
+// Bri sets the brightness, from 1 to 254.
+func (b *StateBuilder) Bri(bri int) *StateBuilder {
+	b.state.Bri = &bri
+	return b
+}
+
+// Hue sets the hue, from 0 to 65535.
+func (b *StateBuilder) Hue(hue int) *StateBuilder {
+	b.state.Hue = &hue
+	return b
+}
+
+// Sat sets the saturation, from 0 to 254.
+func (b *StateBuilder) Sat(sat int) *StateBuilder {
+	b.state.Sat = &sat
+	return b
+}
+
+// XY sets the CIE color space coordinates.
+func (b *StateBuilder) XY(x, y float64) *StateBuilder {
+	b.state.XY = []float64{x, y}
+	return b
+}
+
+// CT sets the color temperature in mired.
+func (b *StateBuilder) CT(ct int) *StateBuilder {
+	b.state.CT = &ct
+	return b
+}
+
+// Color sets the color of the light from any of the color models in the
+// color package. Color temperatures are sent as ct, clamped to the range
+// of the light, when the light supports it. Every other color is sent as
+// xy, clamped to the gamut of the light, along with its brightness when
+// the color model carries one.
+func (b *StateBuilder) Color(c color.Color) *StateBuilder {
+	if m, ok := c.(color.Mired); ok && (b.caps == nil || b.caps.Control.CT != nil) {
+		ct := int(m)
+		if b.caps != nil {
+			ct = clampInt(ct, int(b.caps.Control.CT.Min), int(b.caps.Control.CT.Max))
+		}
+
+		return b.CT(ct)
+	}
+
+	xy := c.XY()
+	if b.gamut != nil {
+		xy = b.gamut.Clamp(xy)
+	}
+	b.XY(round(xy.X, 4), round(xy.Y, 4))
+
+	if v, ok := c.(color.Brightness); ok {
+		b.Bri(clampInt(int(math.Round(v.Brightness()*254)), 1, 254))
+	}
+
+	return b
+}
+
+// Alert sets the alert effect, one of none, select or lselect.
+func (b *StateBuilder) Alert(alert string) *StateBuilder {
+	b.state.Alert = alert
+	return b
+}
+
+// Effect sets the dynamic effect, one of none or colorloop.
+func (b *StateBuilder) Effect(effect string) *StateBuilder {
+	b.state.Effect = effect
+	return b
+}
+
+// TransitionTime sets the duration of the transition to the new state,
+// it is rounded to the 100ms steps the bridge works in.
+func (b *StateBuilder) TransitionTime(d time.Duration) *StateBuilder {
+	steps := int((d + 50*time.Millisecond) / (100 * time.Millisecond))
+	b.state.TransitionTime = &steps
+	return b
+}
+
+// Scene recalls the scene with the given identifier, it can only be sent
+// to a group.
+func (b *StateBuilder) Scene(id string) *StateBuilder {
+	b.state.Scene = id
+	return b
+}
+
+// BriInc increments or decrements the brightness.
+func (b *StateBuilder) BriInc(inc int) *StateBuilder {
+	b.state.BriInc = &inc
+	return b
+}
+
+// SatInc increments or decrements the saturation.
+func (b *StateBuilder) SatInc(inc int) *StateBuilder {
+	b.state.SatInc = &inc
+	return b
+}
+
+// HueInc increments or decrements the hue.
+func (b *StateBuilder) HueInc(inc int) *StateBuilder {
+	b.state.HueInc = &inc
+	return b
+}
+
+// CTInc increments or decrements the color temperature.
+func (b *StateBuilder) CTInc(inc int) *StateBuilder {
+	b.state.CTInc = &inc
+	return b
+}
+
+// XYInc increments or decrements the CIE color space coordinates.
+func (b *StateBuilder) XYInc(dx, dy float64) *StateBuilder {
+	b.state.XYInc = []float64{dx, dy}
+	return b
+}
+
+// Build validates the accumulated attributes and returns the resulting
+// StateUpdate, any invalid attribute is reported in a *ValidationError.
+func (b *StateBuilder) Build() (*StateUpdate, error) {
+	state := b.state
+	if err := state.Validate(b.caps); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+
+	if v > hi {
+		return hi
+	}
+
+	return v
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
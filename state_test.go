@@ -0,0 +1,163 @@
+package hue
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ninnemana/huego/color"
+)
+
+func TestStateBuilder_Build(t *testing.T) {
+	colored := &LightCapabilities{
+		Certified: true,
+		Control: LightControl{
+			ColorGamutType: "C",
+			CT:             &CTRange{Min: 153, Max: 454},
+		},
+	}
+	white := &LightCapabilities{
+		Certified: true,
+		Control: LightControl{
+			MinDimLevel: 2000,
+			MaxLumen:    800,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		builder    *StateBuilder
+		want       string
+		wantFields []string
+	}{
+		{
+			name:    "on with brightness",
+			builder: NewStateBuilder(nil).On(true).Bri(200).TransitionTime(time.Second),
+			want:    `{"on":true,"bri":200,"transitiontime":10}`,
+		},
+		{
+			name:    "off",
+			builder: NewStateBuilder(nil).On(false),
+			want:    `{"on":false}`,
+		},
+		{
+			name:    "color temperature within light range",
+			builder: NewStateBuilder(colored).CT(454),
+			want:    `{"ct":454}`,
+		},
+		{
+			name:    "increments",
+			builder: NewStateBuilder(colored).BriInc(-20).XYInc(0.1, -0.1),
+			want:    `{"bri_inc":-20,"xy_inc":[0.1,-0.1]}`,
+		},
+		{
+			name:    "rgb color clamped into gamut",
+			builder: NewStateBuilder(&LightCapabilities{Control: LightControl{ColorGamutType: "B"}}).Color(color.RGB{R: 255}),
+			want:    `{"bri":254,"xy":[0.675,0.322]}`,
+		},
+		{
+			name:    "kelvin on color temperature light",
+			builder: NewStateBuilder(colored).Color(color.Kelvin(6500)),
+			want:    `{"ct":154}`,
+		},
+		{
+			name:    "kelvin clamped to light range",
+			builder: NewStateBuilder(colored).Color(color.Kelvin(2000)),
+			want:    `{"ct":454}`,
+		},
+		{
+			name:    "kelvin on color only light",
+			builder: (&Light{ModelID: "LLC011"}).StateBuilder().Color(color.Kelvin(2700)),
+			want:    `{"xy":[0.4591,0.4106]}`,
+		},
+		{
+			name:       "out of range",
+			builder:    NewStateBuilder(nil).Bri(0).Sat(255).Hue(70000).TransitionTime(-time.Second),
+			wantFields: []string{"bri", "hue", "sat", "transitiontime"},
+		},
+		{
+			name:       "color temperature outside light range",
+			builder:    NewStateBuilder(colored).CT(500),
+			wantFields: []string{"ct"},
+		},
+		{
+			name:       "conflicting color modes",
+			builder:    NewStateBuilder(colored).XY(0.3, 0.3).CT(300),
+			wantFields: []string{"colormode"},
+		},
+		{
+			name:       "absolute and increment",
+			builder:    NewStateBuilder(nil).Bri(100).BriInc(10),
+			wantFields: []string{"bri_inc"},
+		},
+		{
+			name:       "invalid alert and effect",
+			builder:    NewStateBuilder(nil).Alert("blink").Effect("rainbow"),
+			wantFields: []string{"alert", "effect"},
+		},
+		{
+			name:       "color on white light",
+			builder:    NewStateBuilder(white).XY(0.3, 0.3).Effect("colorloop"),
+			wantFields: []string{"xy", "effect"},
+		},
+		{
+			name:       "color temperature on white light",
+			builder:    NewStateBuilder(white).CT(300),
+			wantFields: []string{"ct"},
+		},
+		{
+			name:       "invalid xy",
+			builder:    NewStateBuilder(nil).XY(1.2, 0.3),
+			wantFields: []string{"xy"},
+		},
+		{
+			name:       "xy outside light gamut",
+			builder:    NewStateBuilder(colored).XY(0.1, 0.9),
+			wantFields: []string{"xy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if tt.wantFields != nil {
+				verr, ok := err.(*ValidationError)
+				if !ok {
+					t.Fatalf("StateBuilder.Build() error = %v, want *ValidationError", err)
+				}
+
+				fields := []string{}
+				for _, f := range verr.Fields {
+					fields = append(fields, f.Field)
+				}
+				if len(fields) != len(tt.wantFields) {
+					t.Fatalf("StateBuilder.Build() invalid fields = %v, want %v", fields, tt.wantFields)
+				}
+				for i := range fields {
+					if fields[i] != tt.wantFields[i] {
+						t.Errorf("StateBuilder.Build() invalid fields = %v, want %v", fields, tt.wantFields)
+						break
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StateBuilder.Build() error = %v", err)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("StateBuilder.Build() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateUpdate_Validate_nil(t *testing.T) {
+	var s *StateUpdate
+	if _, ok := s.Validate(nil).(*ValidationError); !ok {
+		t.Errorf("StateUpdate.Validate() error = %v, want *ValidationError", s.Validate(nil))
+	}
+}
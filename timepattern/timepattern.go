@@ -0,0 +1,359 @@
+// Package timepattern parses and formats the time patterns the Hue
+// bridge uses to trigger schedules, and converts the bridge's local
+// times to and from time.Time.
+package timepattern
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LocalLayout is the layout of the local times reported by the bridge,
+// which carry no zone of their own.
+const LocalLayout = "2006-01-02T15:04:05"
+
+// Forever is the repetition count of a recurring timer that never stops.
+const Forever = -1
+
+// maxRepeat is the most repetitions a recurring timer can be given.
+const maxRepeat = 99
+
+// Kind is the form of a time pattern.
+type Kind int
+
+const (
+	// Absolute fires once at a date and time, [YYYY]-[MM]-[DD]T[hh]:[mm]:[ss].
+	Absolute Kind = iota + 1
+
+	// Recurring fires on a set of weekdays at a time of day,
+	// W[bbb]/T[hh]:[mm]:[ss].
+	Recurring
+
+	// Timer fires once the duration has elapsed from when it was started,
+	// PT[hh]:[mm]:[ss], and repeats when prefixed with R[nn]/.
+	Timer
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Absolute:
+		return "absolute"
+	case Recurring:
+		return "recurring"
+	case Timer:
+		return "timer"
+	}
+
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Pattern is a parsed time pattern. Only the fields of its Kind are used,
+// along with Random which applies to all of them.
+type Pattern struct {
+	Kind Kind
+
+	// Time is when an Absolute pattern fires, in the location it was
+	// parsed with.
+	Time time.Time
+
+	// Weekdays and TimeOfDay are when a Recurring pattern fires,
+	// TimeOfDay is the offset from midnight.
+	Weekdays  Weekdays
+	TimeOfDay time.Duration
+
+	// Duration is how long a Timer runs, Repeat is how many times it
+	// runs again, 0 for a one-shot timer and Forever when it never stops.
+	Duration time.Duration
+	Repeat   int
+
+	// Random is the upper bound of a random delay the bridge adds before
+	// firing, the A[hh]:[mm]:[ss] suffix.
+	Random time.Duration
+}
+
+var (
+	clockExpr     = `(\d{2}):(\d{2}):(\d{2})`
+	randomExpr    = `(?:A` + clockExpr + `)?`
+	absoluteRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2})` + randomExpr + `$`)
+	weeklyRegex   = regexp.MustCompile(`^W(\d{1,3})/T` + clockExpr + randomExpr + `$`)
+	timerRegex    = regexp.MustCompile(`^(?:R(\d{2})?/)?PT` + clockExpr + randomExpr + `$`)
+)
+
+// At returns an Absolute pattern firing at t.
+func At(t time.Time) Pattern {
+	return Pattern{
+		Kind: Absolute,
+		Time: t.Truncate(time.Second),
+	}
+}
+
+// Weekly returns a Recurring pattern firing on days at timeOfDay, the
+// offset from midnight.
+func Weekly(days Weekdays, timeOfDay time.Duration) Pattern {
+	return Pattern{
+		Kind:      Recurring,
+		Weekdays:  days,
+		TimeOfDay: timeOfDay,
+	}
+}
+
+// After returns a one-shot Timer firing once d has elapsed.
+func After(d time.Duration) Pattern {
+	return Pattern{
+		Kind:     Timer,
+		Duration: d,
+	}
+}
+
+// Every returns a Timer firing each time d elapses, repeat times or
+// Forever.
+func Every(d time.Duration, repeat int) Pattern {
+	return Pattern{
+		Kind:     Timer,
+		Duration: d,
+		Repeat:   repeat,
+	}
+}
+
+// Randomize returns a copy of p that fires after an extra random delay
+// of up to d.
+func (p Pattern) Randomize(d time.Duration) Pattern {
+	p.Random = d
+	return p
+}
+
+// Parse reads a time pattern, absolute times are read in loc, which
+// defaults to UTC when nil.
+func Parse(s string, loc *time.Location) (Pattern, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var (
+		p   Pattern
+		err error
+	)
+
+	switch {
+	case absoluteRegex.MatchString(s):
+		m := absoluteRegex.FindStringSubmatch(s)
+		p.Kind = Absolute
+		if p.Time, err = time.ParseInLocation(LocalLayout, m[1], loc); err != nil {
+			return Pattern{}, errors.Errorf("invalid time pattern '%s': %v", s, err)
+		}
+		p.Random, err = clock(m[2:5])
+
+	case weeklyRegex.MatchString(s):
+		m := weeklyRegex.FindStringSubmatch(s)
+		days, _ := strconv.Atoi(m[1])
+		p.Kind = Recurring
+		p.Weekdays = Weekdays(days)
+		if days > int(Everyday) {
+			p.Weekdays = 0
+		}
+		if p.TimeOfDay, err = clock(m[2:5]); err == nil {
+			p.Random, err = clock(m[5:8])
+		}
+
+	case timerRegex.MatchString(s):
+		m := timerRegex.FindStringSubmatch(s)
+		p.Kind = Timer
+		switch {
+		case m[1] != "":
+			p.Repeat, _ = strconv.Atoi(m[1])
+			if p.Repeat == 0 {
+				return Pattern{}, errors.Errorf("invalid time pattern '%s': a recurring timer runs at least once", s)
+			}
+		case strings.HasPrefix(s, "R/"):
+			p.Repeat = Forever
+		}
+		if p.Duration, err = clock(m[2:5]); err == nil {
+			p.Random, err = clock(m[5:8])
+		}
+
+	default:
+		return Pattern{}, errors.Errorf("invalid time pattern '%s'", s)
+	}
+
+	if err != nil {
+		return Pattern{}, errors.Errorf("invalid time pattern '%s': %v", s, err)
+	}
+
+	if err := p.Validate(); err != nil {
+		return Pattern{}, errors.Errorf("invalid time pattern '%s': %v", s, err)
+	}
+
+	return p, nil
+}
+
+// MustParse is like Parse but panics when s isn't a valid pattern.
+func MustParse(s string, loc *time.Location) Pattern {
+	p, err := Parse(s, loc)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Validate checks the pattern can be expressed in the bridge's format.
+func (p Pattern) Validate() error {
+	switch p.Kind {
+	case Absolute:
+		if p.Time.IsZero() {
+			return errors.New("absolute time can't be zero")
+		}
+	case Recurring:
+		if !p.Weekdays.Valid() {
+			return errors.Errorf("weekdays must be between 1 and 127, received %d", p.Weekdays)
+		}
+		if p.TimeOfDay < 0 || p.TimeOfDay >= 24*time.Hour {
+			return errors.Errorf("time of day must be within a day, received '%s'", p.TimeOfDay)
+		}
+	case Timer:
+		if p.Duration <= 0 || p.Duration >= 100*time.Hour {
+			return errors.Errorf("timer duration must be positive and below 100 hours, received '%s'", p.Duration)
+		}
+		if p.Repeat < Forever || p.Repeat > maxRepeat {
+			return errors.Errorf("timer repetitions must be between 0 and %d or Forever, received %d", maxRepeat, p.Repeat)
+		}
+	default:
+		return errors.Errorf("unknown pattern kind %d", p.Kind)
+	}
+
+	if p.Random < 0 || p.Random >= 100*time.Hour {
+		return errors.Errorf("random delay must be positive and below 100 hours, received '%s'", p.Random)
+	}
+
+	return nil
+}
+
+// String formats the pattern as the bridge expects it.
+func (p Pattern) String() string {
+	var s string
+	switch p.Kind {
+	case Absolute:
+		s = p.Time.Format(LocalLayout)
+	case Recurring:
+		s = fmt.Sprintf("W%03d/T%s", p.Weekdays, formatClock(p.TimeOfDay))
+	case Timer:
+		s = "PT" + formatClock(p.Duration)
+		switch {
+		case p.Repeat == Forever:
+			s = "R/" + s
+		case p.Repeat > 0:
+			s = fmt.Sprintf("R%02d/%s", p.Repeat, s)
+		}
+	default:
+		return ""
+	}
+
+	if p.Random > 0 {
+		s += "A" + formatClock(p.Random)
+	}
+
+	return s
+}
+
+// Next returns the first time an Absolute or Recurring pattern fires
+// strictly after t, ignoring the random delay. Recurring patterns are
+// evaluated in the location of t. Timers have no fixed time since they
+// depend on when they were started, so Next reports false for them.
+func (p Pattern) Next(t time.Time) (time.Time, bool) {
+	switch p.Kind {
+	case Absolute:
+		if p.Time.After(t) {
+			return p.Time, true
+		}
+	case Recurring:
+		if !p.Weekdays.Valid() {
+			return time.Time{}, false
+		}
+
+		// build each candidate from its wall clock so days that change
+		// to or from daylight saving time still fire at the same time
+		y, m, d := t.Date()
+		h, min, sec := int(p.TimeOfDay/time.Hour), int(p.TimeOfDay%time.Hour/time.Minute), int(p.TimeOfDay%time.Minute/time.Second)
+		for i := 0; i <= 7; i++ {
+			at := time.Date(y, m, d+i, h, min, sec, 0, t.Location())
+			if !p.Weekdays.Has(at.Weekday()) {
+				continue
+			}
+
+			if at.After(t) {
+				return at, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// LoadLocation returns the location of a bridge timezone, bridges that
+// haven't been given one report "none" and keep their time in UTC.
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" || timezone == "none" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.Errorf("unknown bridge timezone '%s': %v", timezone, err)
+	}
+
+	return loc, nil
+}
+
+// ParseLocal reads a local time reported by the bridge in loc.
+func ParseLocal(s string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t, err := time.ParseInLocation(LocalLayout, s, loc)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid local time '%s': %v", s, err)
+	}
+
+	return t, nil
+}
+
+// FormatLocal formats t as a local time of a bridge in loc.
+func FormatLocal(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return t.In(loc).Format(LocalLayout)
+}
+
+// clock reads the hours, minutes and seconds matched by clockExpr, an
+// unmatched group is a zero duration.
+func clock(m []string) (time.Duration, error) {
+	if m[0] == "" {
+		return 0, nil
+	}
+
+	h, _ := strconv.Atoi(m[0])
+	min, _ := strconv.Atoi(m[1])
+	sec, _ := strconv.Atoi(m[2])
+	if min > 59 || sec > 59 {
+		return 0, errors.Errorf("invalid time %s:%s:%s", m[0], m[1], m[2])
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second, nil
+}
+
+func formatClock(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
@@ -0,0 +1,277 @@
+package timepattern
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		s       string
+		loc     *time.Location
+		want    Pattern
+		wantErr bool
+	}{
+		{
+			name: "absolute",
+			s:    "2014-09-20T19:35:26",
+			loc:  amsterdam,
+			want: Pattern{Kind: Absolute, Time: time.Date(2014, 9, 20, 19, 35, 26, 0, amsterdam)},
+		},
+		{
+			name: "absolute in utc",
+			s:    "2014-09-20T19:35:26",
+			want: Pattern{Kind: Absolute, Time: time.Date(2014, 9, 20, 19, 35, 26, 0, time.UTC)},
+		},
+		{
+			name: "randomized",
+			s:    "2014-09-20T19:35:26A00:30:00",
+			want: Pattern{Kind: Absolute, Time: time.Date(2014, 9, 20, 19, 35, 26, 0, time.UTC), Random: 30 * time.Minute},
+		},
+		{
+			name: "recurring",
+			s:    "W124/T07:30:00",
+			want: Pattern{Kind: Recurring, Weekdays: Workdays, TimeOfDay: 7*time.Hour + 30*time.Minute},
+		},
+		{
+			name: "recurring randomized",
+			s:    "W003/T22:00:00A00:15:00",
+			want: Pattern{Kind: Recurring, Weekdays: Weekend, TimeOfDay: 22 * time.Hour, Random: 15 * time.Minute},
+		},
+		{
+			name: "timer",
+			s:    "PT00:10:00",
+			want: Pattern{Kind: Timer, Duration: 10 * time.Minute},
+		},
+		{
+			name: "randomized timer",
+			s:    "PT01:00:00A00:05:00",
+			want: Pattern{Kind: Timer, Duration: time.Hour, Random: 5 * time.Minute},
+		},
+		{
+			name: "recurring timer",
+			s:    "R05/PT00:00:30",
+			want: Pattern{Kind: Timer, Duration: 30 * time.Second, Repeat: 5},
+		},
+		{
+			name: "endless timer",
+			s:    "R/PT00:01:00A00:00:10",
+			want: Pattern{Kind: Timer, Duration: time.Minute, Repeat: Forever, Random: 10 * time.Second},
+		},
+		{name: "empty", s: "", wantErr: true},
+		{name: "no weekdays", s: "W000/T07:00:00", wantErr: true},
+		{name: "too many weekdays", s: "W128/T07:00:00", wantErr: true},
+		{name: "past midnight", s: "W127/T24:00:00", wantErr: true},
+		{name: "invalid minutes", s: "PT00:60:00", wantErr: true},
+		{name: "zero timer", s: "PT00:00:00", wantErr: true},
+		{name: "zero repetitions", s: "R00/PT00:01:00", wantErr: true},
+		{name: "invalid date", s: "2014-13-20T19:35:26", wantErr: true},
+		{name: "interval", s: "T08:00:00/T12:00:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.s, tt.loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+			if s := got.String(); s != tt.s {
+				t.Errorf("Pattern.String() = %s, want %s", s, tt.s)
+			}
+		})
+	}
+}
+
+func TestPattern_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Pattern
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "at",
+			p:    At(time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)),
+			want: "2020-01-02T03:04:05",
+		},
+		{
+			name: "weekly",
+			p:    Weekly(Monday|Wednesday|Friday, 18*time.Hour+15*time.Minute),
+			want: "W084/T18:15:00",
+		},
+		{
+			name: "single day",
+			p:    Weekly(Sunday, 9*time.Hour),
+			want: "W001/T09:00:00",
+		},
+		{
+			name: "randomized weekly",
+			p:    Weekly(Everyday, 20*time.Hour).Randomize(30 * time.Minute),
+			want: "W127/T20:00:00A00:30:00",
+		},
+		{
+			name: "after",
+			p:    After(90 * time.Minute),
+			want: "PT01:30:00",
+		},
+		{
+			name: "every",
+			p:    Every(15*time.Second, 12),
+			want: "R12/PT00:00:15",
+		},
+		{
+			name: "forever",
+			p:    Every(time.Hour, Forever),
+			want: "R/PT01:00:00",
+		},
+		{
+			name:    "too many repetitions",
+			p:       Every(time.Hour, 100),
+			want:    "R100/PT01:00:00",
+			wantErr: true,
+		},
+		{
+			name:    "negative random",
+			p:       After(time.Minute).Randomize(-time.Second),
+			want:    "PT00:01:00",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.String(); got != tt.want {
+				t.Errorf("Pattern.String() = %v, want %v", got, tt.want)
+			}
+			if err := tt.p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Pattern.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPattern_Next(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	// Friday
+	now := time.Date(2020, 3, 27, 12, 0, 0, 0, amsterdam)
+
+	tests := []struct {
+		name   string
+		p      Pattern
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "absolute ahead",
+			p:      At(now.Add(time.Hour)),
+			want:   now.Add(time.Hour),
+			wantOK: true,
+		},
+		{
+			name: "absolute passed",
+			p:    At(now.Add(-time.Hour)),
+		},
+		{
+			name:   "later today",
+			p:      Weekly(Friday, 18*time.Hour),
+			want:   time.Date(2020, 3, 27, 18, 0, 0, 0, amsterdam),
+			wantOK: true,
+		},
+		{
+			name:   "across daylight saving change",
+			p:      Weekly(Workdays, 7*time.Hour),
+			want:   time.Date(2020, 3, 30, 7, 0, 0, 0, amsterdam),
+			wantOK: true,
+		},
+		{
+			name:   "same time next week",
+			p:      Weekly(Friday, 12*time.Hour),
+			want:   time.Date(2020, 4, 3, 12, 0, 0, 0, amsterdam),
+			wantOK: true,
+		},
+		{
+			name: "timer",
+			p:    After(time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.p.Next(now)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Pattern.Next() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLocal(t *testing.T) {
+	loc, err := LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	utc, err := LoadLocation("none")
+	if err != nil || utc != time.UTC {
+		t.Fatalf("LoadLocation(none) = %v, %v, want UTC", utc, err)
+	}
+
+	if _, err := LoadLocation("Mars/Olympus_Mons"); err == nil {
+		t.Errorf("LoadLocation() expected an error for an unknown timezone")
+	}
+
+	got, err := ParseLocal("2020-07-01T08:00:00", loc)
+	if err != nil {
+		t.Fatalf("ParseLocal() error = %v", err)
+	}
+
+	want := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("ParseLocal() = %v, want %v", got, want)
+	}
+
+	if s := FormatLocal(want, loc); s != "2020-07-01T08:00:00" {
+		t.Errorf("FormatLocal() = %v, want 2020-07-01T08:00:00", s)
+	}
+}
+
+func TestWeekdays(t *testing.T) {
+	tests := []struct {
+		days Weekdays
+		want []time.Weekday
+		str  string
+	}{
+		{days: Everyday, want: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}, str: "every day"},
+		{days: Weekend, want: []time.Weekday{time.Saturday, time.Sunday}, str: "weekend"},
+		{days: Monday | Thursday, want: []time.Weekday{time.Monday, time.Thursday}, str: "Mon,Thu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := tt.days.Days(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Weekdays.Days() = %v, want %v", got, tt.want)
+			}
+			if got := tt.days.String(); got != tt.str {
+				t.Errorf("Weekdays.String() = %v, want %v", got, tt.str)
+			}
+			for _, d := range tt.want {
+				if Weekday(d)&tt.days == 0 {
+					t.Errorf("Weekday(%v) not in %v", d, tt.days)
+				}
+			}
+		})
+	}
+}
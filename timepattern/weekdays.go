@@ -0,0 +1,75 @@
+package timepattern
+
+import (
+	"strings"
+	"time"
+)
+
+// Weekdays is the bitmask of days a recurring pattern fires on, as the
+// bridge encodes it with Monday as the most significant of seven bits.
+type Weekdays uint8
+
+// Days of the week, combine them with | to build a mask.
+const (
+	Monday    Weekdays = 64
+	Tuesday   Weekdays = 32
+	Wednesday Weekdays = 16
+	Thursday  Weekdays = 8
+	Friday    Weekdays = 4
+	Saturday  Weekdays = 2
+	Sunday    Weekdays = 1
+
+	Workdays = Monday | Tuesday | Wednesday | Thursday | Friday
+	Weekend  = Saturday | Sunday
+	Everyday = Workdays | Weekend
+)
+
+// Weekday returns the bit of d.
+func Weekday(d time.Weekday) Weekdays {
+	if d == time.Sunday {
+		return Sunday
+	}
+
+	return 1 << uint(7-d)
+}
+
+// Has reports whether d is part of the mask.
+func (w Weekdays) Has(d time.Weekday) bool {
+	return w&Weekday(d) != 0
+}
+
+// Days returns the days in the mask, starting from Monday.
+func (w Weekdays) Days() []time.Weekday {
+	days := []time.Weekday{}
+	for _, d := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		if w.Has(d) {
+			days = append(days, d)
+		}
+	}
+
+	return days
+}
+
+// Valid reports whether the mask holds at least one day and no bits
+// outside the seven days of the week.
+func (w Weekdays) Valid() bool {
+	return w > 0 && w <= Everyday
+}
+
+func (w Weekdays) String() string {
+	switch w {
+	case Everyday:
+		return "every day"
+	case Workdays:
+		return "workdays"
+	case Weekend:
+		return "weekend"
+	}
+
+	names := []string{}
+	for _, d := range w.Days() {
+		names = append(names, d.String()[:3])
+	}
+
+	return strings.Join(names, ",")
+}